@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/suyw-0123/graphweaver/internal/eval"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/internal/service"
+	"github.com/suyw-0123/graphweaver/pkg/embedding"
+	"github.com/suyw-0123/graphweaver/pkg/llm"
+	"github.com/suyw-0123/graphweaver/pkg/rerank"
+)
+
+// eval runs a dataset of questions against a notebook and reports retrieval metrics
+// (recall@k, MRR, nDCG@k) and answer quality (similarity to the expected answer and
+// LLM-judged correctness) as JSON and Markdown.
+//
+// Usage: go run ./cmd/eval -notebook 1 -dataset questions.jsonl [-k 5] [-retrieval-mode hybrid]
+// [-chat-mode local] [-expansion none] [-skip-chat] [-no-judge] [-json report.json] [-markdown report.md]
+func main() {
+	notebookID := flag.Int64("notebook", 0, "ID of the notebook to evaluate")
+	datasetPath := flag.String("dataset", "", "dataset file: a JSON dataset or JSON Lines questions")
+	k := flag.Int("k", 5, "retrieved chunks scored by the retrieval metrics")
+	retrievalMode := flag.String("retrieval-mode", service.RetrievalModeHybrid, "hybrid, vector, lexical or graph")
+	chatMode := flag.String("chat-mode", service.ChatModeLocal, "local, global or agent")
+	expansion := flag.String("expansion", service.ExpansionNone, "query expansion of retrieval and local chat: none, hyde or multi_query")
+	skipChat := flag.Bool("skip-chat", false, "evaluate retrieval only")
+	noJudge := flag.Bool("no-judge", false, "do not score answers with the LLM judge")
+	jsonPath := flag.String("json", "eval-report.json", "path of the JSON report")
+	markdownPath := flag.String("markdown", "eval-report.md", "path of the Markdown report")
+	flag.Parse()
+
+	if *notebookID <= 0 || *datasetPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load .env file if it exists
+	_ = godotenv.Load()
+
+	ds, err := eval.LoadDataset(*datasetPath)
+	if err != nil {
+		log.Fatalf("Failed to load dataset: %v", err)
+	}
+
+	dbHost := os.Getenv("DB_HOST")
+	if dbHost == "" {
+		dbHost = "localhost"
+	}
+	dbPort := os.Getenv("DB_PORT")
+	if dbPort == "" {
+		dbPort = "5432"
+	}
+	dbUser := os.Getenv("DB_USER")
+	if dbUser == "" {
+		dbUser = "graphweaver"
+	}
+	dbPass := os.Getenv("DB_PASSWORD")
+	if dbPass == "" {
+		dbPass = "graphweaver123"
+	}
+	dbName := os.Getenv("DB_NAME")
+	if dbName == "" {
+		dbName = "graphweaver"
+	}
+
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", dbUser, dbPass, dbHost, dbPort, dbName)
+
+	db, err := repository.NewPostgresDB(dsn)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		log.Fatal("GEMINI_API_KEY is required to embed questions and generate answers")
+	}
+	llmClient, err := llm.NewGeminiClient(ctx, apiKey, os.Getenv("GEMINI_MODEL_NAME"))
+	if err != nil {
+		log.Fatalf("Failed to initialize Gemini client: %v", err)
+	}
+	defer llmClient.Close()
+	embeddingClient, err := embedding.NewGeminiClient(ctx, apiKey)
+	if err != nil {
+		log.Fatalf("Failed to initialize Embedding client: %v", err)
+	}
+	defer embeddingClient.Close()
+
+	qdrantHost := os.Getenv("QDRANT_HOST")
+	if qdrantHost == "" {
+		qdrantHost = "127.0.0.1"
+	}
+	qdrantPort := 6334
+	if portStr := os.Getenv("QDRANT_PORT"); portStr != "" {
+		if p, err := strconv.Atoi(portStr); err == nil {
+			qdrantPort = p
+		}
+	}
+	vectorRepo, err := repository.NewQdrantVectorRepository(qdrantHost, qdrantPort)
+	if err != nil {
+		log.Fatalf("Failed to connect to Qdrant: %v", err)
+	}
+	defer vectorRepo.Close()
+
+	docRepo := repository.NewPostgresDocumentRepository(db)
+	notebookRepo := repository.NewPostgresNotebookRepository(db)
+	chunkRepo := repository.NewPostgresChunkRepository(db)
+	communityRepo := repository.NewPostgresCommunityRepository(db)
+	mentionRepo := repository.NewPostgresMentionRepository(db)
+
+	var graphRepo repository.GraphRepository
+	switch backend := os.Getenv("GRAPH_BACKEND"); backend {
+	case "", "postgres":
+		graphRepo = repository.NewPostgresGraphRepository(db)
+	case "neo4j":
+		neo4jURI := os.Getenv("NEO4J_URI")
+		if neo4jURI == "" {
+			neo4jURI = "neo4j://localhost:7687"
+		}
+		neo4jUser := os.Getenv("NEO4J_USER")
+		if neo4jUser == "" {
+			neo4jUser = "neo4j"
+		}
+		neo4jRepo, err := repository.NewNeo4jGraphRepository(ctx, repository.Neo4jConfig{
+			URI:      neo4jURI,
+			Username: neo4jUser,
+			Password: os.Getenv("NEO4J_PASSWORD"),
+			Database: os.Getenv("NEO4J_DATABASE"),
+		}, docRepo)
+		if err != nil {
+			log.Fatalf("Failed to connect to Neo4j: %v", err)
+		}
+		defer neo4jRepo.Close(ctx)
+		graphRepo = neo4jRepo
+	default:
+		log.Fatalf("Unknown GRAPH_BACKEND: %s", backend)
+	}
+
+	// Same retrieval stack as the server, so that RERANKER and ENTITY_LINKING runs compare
+	var reranker rerank.Reranker
+	switch backend := os.Getenv("RERANKER"); backend {
+	case "", "none":
+	case "llm":
+		reranker = rerank.NewLLMReranker(llmClient)
+	case "http":
+		if os.Getenv("RERANKER_URL") == "" {
+			log.Fatal("RERANKER=http requires RERANKER_URL")
+		}
+		reranker = rerank.NewHTTPReranker(os.Getenv("RERANKER_URL"))
+	default:
+		log.Fatalf("Unknown RERANKER: %s", backend)
+	}
+	var linkingLLM llm.Client = llmClient
+	if os.Getenv("ENTITY_LINKING") == "fuzzy" {
+		linkingLLM = nil
+	}
+
+	entityLinkingService := service.NewEntityLinkingService(graphRepo, notebookRepo, vectorRepo, embeddingClient, linkingLLM)
+	retrievalService := service.NewRetrievalService(docRepo, notebookRepo, chunkRepo, graphRepo, mentionRepo, vectorRepo, embeddingClient, entityLinkingService, reranker)
+	chatService := service.NewChatService(docRepo, communityRepo, graphRepo, retrievalService, entityLinkingService, llmClient)
+
+	var judge llm.Client
+	if !*noJudge {
+		judge = llmClient
+	}
+	runner := eval.NewRunner(retrievalService, chatService, docRepo, embeddingClient, judge)
+
+	report, err := runner.Run(ctx, *notebookID, ds, eval.Config{
+		K:             *k,
+		RetrievalMode: *retrievalMode,
+		ChatMode:      *chatMode,
+		Expansion:     *expansion,
+		SkipChat:      *skipChat,
+	})
+	if err != nil {
+		log.Fatalf("Evaluation failed: %v", err)
+	}
+
+	jsonFile, err := os.Create(*jsonPath)
+	if err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+	defer jsonFile.Close()
+	enc := json.NewEncoder(jsonFile)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	mdFile, err := os.Create(*markdownPath)
+	if err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+	defer mdFile.Close()
+	if err := report.WriteMarkdown(mdFile); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	log.Printf("Evaluated %d questions (%d failed), reports written to %s and %s",
+		report.Summary.Questions, report.Summary.Failed, *jsonPath, *markdownPath)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+)
+
+// graphsync copies the knowledge graph from Postgres to Neo4j, keeping node and edge IDs.
+// Nodes of every document are copied before any edge, since edges may point at nodes of
+// other documents.
+//
+// Usage: go run ./cmd/graphsync [-reset]
+func main() {
+	reset := flag.Bool("reset", false, "delete all entities in Neo4j before copying")
+	flag.Parse()
+
+	// Load .env file if it exists
+	_ = godotenv.Load()
+
+	dbHost := os.Getenv("DB_HOST")
+	if dbHost == "" {
+		dbHost = "localhost"
+	}
+	dbPort := os.Getenv("DB_PORT")
+	if dbPort == "" {
+		dbPort = "5432"
+	}
+	dbUser := os.Getenv("DB_USER")
+	if dbUser == "" {
+		dbUser = "graphweaver"
+	}
+	dbPass := os.Getenv("DB_PASSWORD")
+	if dbPass == "" {
+		dbPass = "graphweaver123"
+	}
+	dbName := os.Getenv("DB_NAME")
+	if dbName == "" {
+		dbName = "graphweaver"
+	}
+
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", dbUser, dbPass, dbHost, dbPort, dbName)
+
+	db, err := repository.NewPostgresDB(dsn)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	neo4jURI := os.Getenv("NEO4J_URI")
+	if neo4jURI == "" {
+		neo4jURI = "neo4j://localhost:7687"
+	}
+	neo4jUser := os.Getenv("NEO4J_USER")
+	if neo4jUser == "" {
+		neo4jUser = "neo4j"
+	}
+
+	ctx := context.Background()
+	docRepo := repository.NewPostgresDocumentRepository(db)
+	source := repository.NewPostgresGraphRepository(db)
+	target, err := repository.NewNeo4jGraphRepository(ctx, repository.Neo4jConfig{
+		URI:      neo4jURI,
+		Username: neo4jUser,
+		Password: os.Getenv("NEO4J_PASSWORD"),
+		Database: os.Getenv("NEO4J_DATABASE"),
+	}, docRepo)
+	if err != nil {
+		log.Fatalf("Failed to connect to Neo4j: %v", err)
+	}
+	defer target.Close(ctx)
+
+	if *reset {
+		if err := target.Reset(ctx); err != nil {
+			log.Fatalf("Failed to reset Neo4j: %v", err)
+		}
+	}
+
+	var docIDs []int64
+	const pageSize = 100
+	for offset := 0; ; offset += pageSize {
+		docs, err := docRepo.List(ctx, pageSize, offset, nil)
+		if err != nil {
+			log.Fatalf("Failed to list documents: %v", err)
+		}
+		for _, d := range docs {
+			docIDs = append(docIDs, d.ID)
+		}
+		if len(docs) < pageSize {
+			break
+		}
+	}
+
+	var nodeCount, edgeCount int
+	for _, id := range docIDs {
+		nodes, err := source.GetNodesByDocumentID(ctx, id)
+		if err != nil {
+			log.Fatalf("Failed to read nodes of document %d: %v", id, err)
+		}
+		if err := target.ImportGraph(ctx, nodes, nil); err != nil {
+			log.Fatalf("Failed to copy nodes of document %d: %v", id, err)
+		}
+		nodeCount += len(nodes)
+	}
+	for _, id := range docIDs {
+		edges, err := source.GetEdgesByDocumentID(ctx, id)
+		if err != nil {
+			log.Fatalf("Failed to read edges of document %d: %v", id, err)
+		}
+		if err := target.ImportGraph(ctx, nil, edges); err != nil {
+			log.Fatalf("Failed to copy edges of document %d: %v", id, err)
+		}
+		edgeCount += len(edges)
+	}
+
+	log.Printf("Copied %d nodes and %d edges of %d documents to Neo4j", nodeCount, edgeCount, len(docIDs))
+}
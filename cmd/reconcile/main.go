@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/internal/service"
+	"github.com/suyw-0123/graphweaver/pkg/embedding"
+)
+
+// reconcile compares chunk rows in Postgres with points in Qdrant and repairs the drift.
+//
+// Usage: go run ./cmd/reconcile [-dry-run]
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report drift without re-embedding or deleting points")
+	flag.Parse()
+
+	// Load .env file if it exists
+	_ = godotenv.Load()
+
+	dbHost := os.Getenv("DB_HOST")
+	if dbHost == "" {
+		dbHost = "localhost"
+	}
+	dbPort := os.Getenv("DB_PORT")
+	if dbPort == "" {
+		dbPort = "5432"
+	}
+	dbUser := os.Getenv("DB_USER")
+	if dbUser == "" {
+		dbUser = "graphweaver"
+	}
+	dbPass := os.Getenv("DB_PASSWORD")
+	if dbPass == "" {
+		dbPass = "graphweaver123"
+	}
+	dbName := os.Getenv("DB_NAME")
+	if dbName == "" {
+		dbName = "graphweaver"
+	}
+
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", dbUser, dbPass, dbHost, dbPort, dbName)
+
+	db, err := repository.NewPostgresDB(dsn)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	qdrantHost := os.Getenv("QDRANT_HOST")
+	if qdrantHost == "" {
+		qdrantHost = "127.0.0.1"
+	}
+	qdrantPort := 6334
+	if portStr := os.Getenv("QDRANT_PORT"); portStr != "" {
+		if p, err := strconv.Atoi(portStr); err == nil {
+			qdrantPort = p
+		}
+	}
+	vectorRepo, err := repository.NewQdrantVectorRepository(qdrantHost, qdrantPort)
+	if err != nil {
+		log.Fatalf("Failed to connect to Qdrant: %v", err)
+	}
+	defer vectorRepo.Close()
+
+	// Only needed to re-embed missing points
+	var embeddingClient embedding.Client
+	if apiKey := os.Getenv("GEMINI_API_KEY"); apiKey != "" {
+		client, err := embedding.NewGeminiClient(context.Background(), apiKey)
+		if err != nil {
+			log.Fatalf("Failed to initialize Embedding client: %v", err)
+		}
+		defer client.Close()
+		embeddingClient = client
+	} else if !*dryRun {
+		log.Println("Warning: GEMINI_API_KEY is not set. Missing points cannot be re-embedded.")
+	}
+
+	reconciler := service.NewReconcileService(repository.NewPostgresChunkRepository(db), vectorRepo, embeddingClient)
+
+	report, err := reconciler.Reconcile(context.Background(), *dryRun)
+	if err != nil {
+		log.Fatalf("Reconciliation failed: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+}
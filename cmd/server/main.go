@@ -7,14 +7,18 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	"github.com/suyw-0123/graphweaver/internal/api"
 	"github.com/suyw-0123/graphweaver/internal/repository"
 	"github.com/suyw-0123/graphweaver/internal/service"
 	"github.com/suyw-0123/graphweaver/pkg/embedding"
 	"github.com/suyw-0123/graphweaver/pkg/llm"
+	"github.com/suyw-0123/graphweaver/pkg/rerank"
+	"github.com/suyw-0123/graphweaver/pkg/storage"
 )
 
 func main() {
@@ -99,20 +103,112 @@ func main() {
 		log.Println("Connected to Qdrant")
 	}
 
+	// Blob Storage
+	var blobStore storage.BlobStore
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "local":
+		uploadDir := os.Getenv("UPLOAD_DIR")
+		if uploadDir == "" {
+			uploadDir = "uploads"
+		}
+		localStore, err := storage.NewLocalStore(uploadDir)
+		if err != nil {
+			log.Fatalf("Failed to initialize local storage: %v", err)
+		}
+		blobStore = localStore
+		log.Printf("Using local storage at %s", uploadDir)
+	case "s3":
+		s3Store, err := storage.NewS3Store(context.Background(), storage.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			Region:          os.Getenv("S3_REGION"),
+			UseSSL:          os.Getenv("S3_USE_SSL") == "true",
+			PublicEndpoint:  os.Getenv("S3_PUBLIC_ENDPOINT"),
+			PublicUseSSL:    os.Getenv("S3_PUBLIC_USE_SSL") == "true",
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize S3 storage: %v", err)
+		}
+		blobStore = s3Store
+		log.Printf("Using S3 storage bucket %s at %s", os.Getenv("S3_BUCKET"), os.Getenv("S3_ENDPOINT"))
+	default:
+		log.Fatalf("Unknown STORAGE_BACKEND: %s", backend)
+	}
+
 	// Dependency Injection
 	docRepo := repository.NewPostgresDocumentRepository(db)
 	notebookRepo := repository.NewPostgresNotebookRepository(db)
-	graphRepo := repository.NewPostgresGraphRepository(db)
 	chunkRepo := repository.NewPostgresChunkRepository(db)
+	communityRepo := repository.NewPostgresCommunityRepository(db)
+	editRepo := repository.NewPostgresGraphEditRepository(db)
+	mentionRepo := repository.NewPostgresMentionRepository(db)
+
+	// Graph Storage
+	var graphRepo repository.GraphRepository
+	switch backend := os.Getenv("GRAPH_BACKEND"); backend {
+	case "", "postgres":
+		graphRepo = repository.NewPostgresGraphRepository(db)
+	case "neo4j":
+		neo4jRepo, err := repository.NewNeo4jGraphRepository(context.Background(), neo4jConfigFromEnv(), docRepo)
+		if err != nil {
+			log.Fatalf("Failed to connect to Neo4j: %v", err)
+		}
+		defer neo4jRepo.Close(context.Background())
+		graphRepo = neo4jRepo
+		log.Println("Using Neo4j graph storage")
+	default:
+		log.Fatalf("Unknown GRAPH_BACKEND: %s", backend)
+	}
+
+	cleanupService := service.NewCleanupService(docRepo, graphRepo, vectorRepo, blobStore)
+	docService := service.NewDocumentService(docRepo, graphRepo, blobStore)
+	notebookService := service.NewNotebookService(notebookRepo, docRepo, cleanupService)
+	ingestionService := service.NewIngestionService(docRepo, notebookRepo, graphRepo, editRepo, mentionRepo, chunkRepo, vectorRepo, llmClient, embeddingClient, blobStore)
+	graphService := service.NewGraphService(graphRepo, docRepo, notebookRepo)
+	communityService := service.NewCommunityService(communityRepo, graphRepo, notebookRepo, llmClient)
+	importService := service.NewImportService(docRepo, graphRepo, notebookRepo)
+	curationService := service.NewCurationService(graphRepo, editRepo, mentionRepo, docRepo, vectorRepo, embeddingClient)
+	evidenceService := service.NewEvidenceService(mentionRepo, graphRepo)
+	// Entities are extracted from questions by the LLM unless ENTITY_LINKING=fuzzy, which
+	// matches node names against the whole question
+	var linkingLLM llm.Client
+	if llmClient != nil && os.Getenv("ENTITY_LINKING") != "fuzzy" {
+		linkingLLM = llmClient
+	}
+	entityLinkingService := service.NewEntityLinkingService(graphRepo, notebookRepo, vectorRepo, embeddingClient, linkingLLM)
+	retrievalService := service.NewRetrievalService(docRepo, notebookRepo, chunkRepo, graphRepo, mentionRepo, vectorRepo, embeddingClient, entityLinkingService, rerankerFromEnv(llmClient))
+	chatService := service.NewChatService(docRepo, communityRepo, graphRepo, retrievalService, entityLinkingService, llmClient)
+	if cache := chatCacheFromEnv(db, embeddingClient); cache != nil {
+		chatService = service.NewCachedChatService(chatService, cache, notebookRepo, embeddingClient, service.ChatCacheConfig{
+			TTL:           durationEnv("CHAT_CACHE_TTL", 24*time.Hour),
+			MinSimilarity: floatEnv("CHAT_CACHE_SIMILARITY", 0.95),
+		})
+	}
 
-	docService := service.NewDocumentService(docRepo, graphRepo)
-	notebookService := service.NewNotebookService(notebookRepo)
-	ingestionService := service.NewIngestionService(docRepo, graphRepo, chunkRepo, vectorRepo, llmClient, embeddingClient, "uploads")
-	chatService := service.NewChatService(docRepo, graphRepo, vectorRepo, llmClient, embeddingClient)
+	// Background purge of deleted documents and orphaned data
+	cleanupInterval := durationEnv("CLEANUP_INTERVAL", time.Hour)
+	purgeRetention := durationEnv("PURGE_RETENTION", 24*time.Hour)
+	go cleanupService.Run(context.Background(), cleanupInterval, purgeRetention)
+
+	// Background reconciliation of chunks and vectors
+	if vectorRepo != nil {
+		reconcileService := service.NewReconcileService(chunkRepo, vectorRepo, embeddingClient)
+		reconcileInterval := durationEnv("RECONCILE_INTERVAL", 6*time.Hour)
+		reconcileDryRun := os.Getenv("RECONCILE_DRY_RUN") == "true"
+		go reconcileService.Run(context.Background(), reconcileInterval, reconcileDryRun)
+	}
 
 	docHandler := api.NewDocumentHandler(docService, ingestionService)
 	notebookHandler := api.NewNotebookHandler(notebookService)
 	chatHandler := api.NewChatHandler(chatService)
+	graphHandler := api.NewGraphHandler(graphService)
+	communityHandler := api.NewCommunityHandler(communityService)
+	importHandler := api.NewImportHandler(importService)
+	curationHandler := api.NewCurationHandler(curationService)
+	evidenceHandler := api.NewEvidenceHandler(evidenceService)
+	searchHandler := api.NewSearchHandler(retrievalService, entityLinkingService)
 
 	// Router Setup
 	r := gin.Default()
@@ -136,6 +232,12 @@ func main() {
 	docHandler.RegisterRoutes(r)
 	notebookHandler.RegisterRoutes(r)
 	chatHandler.RegisterRoutes(r)
+	graphHandler.RegisterRoutes(r)
+	communityHandler.RegisterRoutes(r)
+	importHandler.RegisterRoutes(r)
+	curationHandler.RegisterRoutes(r)
+	evidenceHandler.RegisterRoutes(r)
+	searchHandler.RegisterRoutes(r)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -147,3 +249,92 @@ func main() {
 		log.Fatalf("Server failed to start: %v", err)
 	}
 }
+
+// durationEnv parses a duration such as "30m" from an environment variable, falling back to def.
+func durationEnv(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+		log.Printf("Warning: invalid %s %q, using %s", key, v, def)
+	}
+	return def
+}
+
+// floatEnv parses a number from an environment variable, falling back to def.
+func floatEnv(key string, def float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+		log.Printf("Warning: invalid %s %q, using %g", key, v, def)
+	}
+	return def
+}
+
+// chatCacheFromEnv selects where answers to repeated chat questions are cached: none (the
+// default), memory, or postgres to share the cache between instances.
+func chatCacheFromEnv(db *sqlx.DB, embeddingClient *embedding.GeminiClient) repository.ChatCacheRepository {
+	backend := os.Getenv("CHAT_CACHE")
+	if backend == "" || backend == "none" {
+		return nil
+	}
+	if embeddingClient == nil {
+		log.Println("Warning: CHAT_CACHE needs an embedding client, chat caching is disabled")
+		return nil
+	}
+	switch backend {
+	case "memory":
+		log.Println("Using in-memory chat cache")
+		return repository.NewMemoryChatCacheRepository(0)
+	case "postgres":
+		log.Println("Using Postgres chat cache")
+		return repository.NewPostgresChatCacheRepository(db)
+	default:
+		log.Fatalf("Unknown CHAT_CACHE: %s", backend)
+		return nil
+	}
+}
+
+// rerankerFromEnv selects the reranking stage of retrieval: none (the default), llm, or http
+// for a TEI-compatible rerank server at RERANKER_URL.
+func rerankerFromEnv(llmClient *llm.GeminiClient) rerank.Reranker {
+	switch backend := os.Getenv("RERANKER"); backend {
+	case "", "none":
+		return nil
+	case "llm":
+		if llmClient == nil {
+			log.Println("Warning: RERANKER=llm needs an LLM client, reranking is disabled")
+			return nil
+		}
+		log.Println("Using LLM reranking")
+		return rerank.NewLLMReranker(llmClient)
+	case "http":
+		url := os.Getenv("RERANKER_URL")
+		if url == "" {
+			log.Fatal("RERANKER=http requires RERANKER_URL")
+		}
+		log.Printf("Using rerank server at %s", url)
+		return rerank.NewHTTPReranker(url)
+	default:
+		log.Fatalf("Unknown RERANKER: %s", backend)
+		return nil
+	}
+}
+
+// neo4jConfigFromEnv reads the Neo4j connection settings, defaulting to a local server.
+func neo4jConfigFromEnv() repository.Neo4jConfig {
+	cfg := repository.Neo4jConfig{
+		URI:      os.Getenv("NEO4J_URI"),
+		Username: os.Getenv("NEO4J_USER"),
+		Password: os.Getenv("NEO4J_PASSWORD"),
+		Database: os.Getenv("NEO4J_DATABASE"),
+	}
+	if cfg.URI == "" {
+		cfg.URI = "neo4j://localhost:7687"
+	}
+	if cfg.Username == "" {
+		cfg.Username = "neo4j"
+	}
+	return cfg
+}
@@ -1,10 +1,12 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/suyw-0123/graphweaver/internal/entity"
 	"github.com/suyw-0123/graphweaver/internal/service"
 )
 
@@ -32,18 +34,76 @@ func (h *ChatHandler) Chat(c *gin.Context) {
 	}
 
 	var req struct {
-		Query string `json:"query" binding:"required"`
+		Query          string  `json:"query" binding:"required"`
+		Mode           string  `json:"mode"`
+		CommunityLevel int     `json:"community_level"`
+		AsOf           string  `json:"as_of"`
+		VectorWeight   float64 `json:"vector_weight"`
+		LexicalWeight  float64 `json:"lexical_weight"`
+		Expansion      string  `json:"expansion"`
+		SubQueries     int     `json:"sub_queries"`
+		MaxSteps       int     `json:"max_steps"`
+		Verify         bool    `json:"verify"`
+		Regenerate     bool    `json:"regenerate"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Query is required"})
 		return
 	}
+	switch req.Mode {
+	case "", service.ChatModeLocal, service.ChatModeGlobal, service.ChatModeAgent:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be local, global or agent"})
+		return
+	}
+	if req.MaxSteps < 0 || req.MaxSteps > service.MaxAgentSteps {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("max_steps must be between 0 and %d", service.MaxAgentSteps)})
+		return
+	}
+
+	if req.VectorWeight < 0 || req.LexicalWeight < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "vector_weight and lexical_weight cannot be negative"})
+		return
+	}
+
+	switch req.Expansion {
+	case "", service.ExpansionNone, service.ExpansionHyDE, service.ExpansionMultiQuery:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expansion must be none, hyde or multi_query"})
+		return
+	}
+	if req.SubQueries < 0 || req.SubQueries > service.MaxSubQueries {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("sub_queries must be between 0 and %d", service.MaxSubQueries)})
+		return
+	}
+
+	var asOf *entity.Date
+	if req.AsOf != "" {
+		d, err := entity.ParseDate(req.AsOf)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "as_of: " + err.Error()})
+			return
+		}
+		asOf = &d
+	}
 
-	answer, err := h.chatService.Chat(c.Request.Context(), notebookID, req.Query)
+	resp, err := h.chatService.Chat(c.Request.Context(), notebookID, service.ChatRequest{
+		Query:          req.Query,
+		Mode:           req.Mode,
+		CommunityLevel: req.CommunityLevel,
+		AsOf:           asOf,
+		VectorWeight:   req.VectorWeight,
+		LexicalWeight:  req.LexicalWeight,
+		Expansion:      req.Expansion,
+		SubQueries:     req.SubQueries,
+		MaxSteps:       req.MaxSteps,
+		Verify:         req.Verify,
+		Regenerate:     req.Regenerate,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"answer": answer})
+	c.JSON(http.StatusOK, resp)
 }
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suyw-0123/graphweaver/internal/service"
+)
+
+// CommunityHandler handles HTTP requests for notebook graph communities.
+type CommunityHandler struct {
+	communityService service.CommunityService
+}
+
+// NewCommunityHandler creates a new CommunityHandler.
+func NewCommunityHandler(communityService service.CommunityService) *CommunityHandler {
+	return &CommunityHandler{communityService: communityService}
+}
+
+// RegisterRoutes registers the community routes.
+func (h *CommunityHandler) RegisterRoutes(r *gin.Engine) {
+	v1 := r.Group("/api/v1")
+	{
+		v1.POST("/notebooks/:id/communities", h.BuildCommunities)
+		v1.GET("/notebooks/:id/communities", h.ListCommunities)
+		v1.GET("/notebooks/:id/communities/build", h.GetBuild)
+	}
+}
+
+// BuildCommunities starts detecting and summarizing the communities of a notebook graph in
+// the background, replacing any previously built ones once done. Poll GetBuild for the outcome.
+func (h *CommunityHandler) BuildCommunities(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	build, err := h.communityService.StartBuild(c.Request.Context(), id)
+	if errors.Is(err, service.ErrCommunityBuildRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, build)
+}
+
+// GetBuild reports the status of the latest community build of a notebook.
+func (h *CommunityHandler) GetBuild(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	build, err := h.communityService.GetBuild(c.Request.Context(), id)
+	if errors.Is(err, service.ErrCommunityBuildNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, build)
+}
+
+// ListCommunities lists the communities of a notebook. Query: level.
+func (h *CommunityHandler) ListCommunities(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var level *int
+	if v := c.Query("level"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid level"})
+			return
+		}
+		level = &l
+	}
+
+	communities, err := h.communityService.ListCommunities(c.Request.Context(), id, level)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"communities": communities})
+}
@@ -0,0 +1,258 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suyw-0123/graphweaver/internal/service"
+)
+
+// CurationHandler handles HTTP requests for manual graph edits.
+type CurationHandler struct {
+	curationService service.CurationService
+}
+
+// NewCurationHandler creates a new CurationHandler.
+func NewCurationHandler(curationService service.CurationService) *CurationHandler {
+	return &CurationHandler{curationService: curationService}
+}
+
+// RegisterRoutes registers the curation routes.
+func (h *CurationHandler) RegisterRoutes(r *gin.Engine) {
+	v1 := r.Group("/api/v1")
+	{
+		v1.POST("/nodes", h.CreateNode)
+		v1.GET("/nodes/:id", h.GetNode)
+		v1.PATCH("/nodes/:id", h.UpdateNode)
+		v1.DELETE("/nodes/:id", h.DeleteNode)
+		v1.POST("/nodes/:id/merge", h.MergeNodes)
+		v1.POST("/nodes/:id/split", h.SplitNode)
+		v1.GET("/nodes/:id/edits", h.ListNodeEdits)
+
+		v1.POST("/edges", h.CreateEdge)
+		v1.GET("/edges/:id", h.GetEdge)
+		v1.PATCH("/edges/:id", h.UpdateEdge)
+		v1.DELETE("/edges/:id", h.DeleteEdge)
+		v1.GET("/edges/:id/edits", h.ListEdgeEdits)
+
+		v1.GET("/notebooks/:id/graph/edits", h.ListNotebookEdits)
+	}
+}
+
+func (h *CurationHandler) GetNode(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	node, err := h.curationService.GetNode(c.Request.Context(), id)
+	if err != nil {
+		writeCurationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, node)
+}
+
+// CreateNode adds a node to a document's graph. Body: document_id, label, name, properties.
+func (h *CurationHandler) CreateNode(c *gin.Context) {
+	var req service.NodeInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	node, err := h.curationService.CreateNode(c.Request.Context(), req)
+	if err != nil {
+		writeCurationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, node)
+}
+
+// UpdateNode changes a node's label, name or properties. Omitted fields are kept.
+func (h *CurationHandler) UpdateNode(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req service.NodeInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	node, err := h.curationService.UpdateNode(c.Request.Context(), id, req)
+	if err != nil {
+		writeCurationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, node)
+}
+
+// DeleteNode removes a node together with its edges.
+func (h *CurationHandler) DeleteNode(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if err := h.curationService.DeleteNode(c.Request.Context(), id); err != nil {
+		writeCurationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MergeNodes folds other nodes into this one. Body: {"node_ids": [...]}.
+func (h *CurationHandler) MergeNodes(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		NodeIDs []int64 `json:"node_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	node, err := h.curationService.MergeNodes(c.Request.Context(), id, req.NodeIDs)
+	if err != nil {
+		writeCurationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, node)
+}
+
+// SplitNode creates new nodes from this one. Body: {"parts": [{"label", "name", "properties", "edge_ids"}]}.
+func (h *CurationHandler) SplitNode(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Parts []service.SplitPart `json:"parts" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	nodes, err := h.curationService.SplitNode(c.Request.Context(), id, req.Parts)
+	if err != nil {
+		writeCurationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, nodes)
+}
+
+func (h *CurationHandler) ListNodeEdits(c *gin.Context) {
+	h.listEdits(c, service.EditEntityNode)
+}
+
+func (h *CurationHandler) GetEdge(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	edge, err := h.curationService.GetEdge(c.Request.Context(), id)
+	if err != nil {
+		writeCurationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, edge)
+}
+
+// CreateEdge adds an edge between two nodes.
+// Body: source_node_id, target_node_id, relation_type, properties, document_id.
+func (h *CurationHandler) CreateEdge(c *gin.Context) {
+	var req service.EdgeInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	edge, err := h.curationService.CreateEdge(c.Request.Context(), req)
+	if err != nil {
+		writeCurationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, edge)
+}
+
+// UpdateEdge changes an edge's relation type or properties. Omitted fields are kept.
+func (h *CurationHandler) UpdateEdge(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req service.EdgeInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	edge, err := h.curationService.UpdateEdge(c.Request.Context(), id, req)
+	if err != nil {
+		writeCurationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, edge)
+}
+
+func (h *CurationHandler) DeleteEdge(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if err := h.curationService.DeleteEdge(c.Request.Context(), id); err != nil {
+		writeCurationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CurationHandler) ListEdgeEdits(c *gin.Context) {
+	h.listEdits(c, service.EditEntityEdge)
+}
+
+// ListNotebookEdits returns the most recent graph edits in a notebook. Query: limit.
+func (h *CurationHandler) ListNotebookEdits(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	edits, err := h.curationService.ListNotebookEdits(c.Request.Context(), id, limit)
+	if err != nil {
+		writeCurationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, edits)
+}
+
+func (h *CurationHandler) listEdits(c *gin.Context, entityType string) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	edits, err := h.curationService.ListEdits(c.Request.Context(), entityType, id)
+	if err != nil {
+		writeCurationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, edits)
+}
+
+// parseID reads the id path parameter, writing a 400 response if it is invalid.
+func parseID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func writeCurationError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidGraphEdit) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	writeGraphError(c, err)
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 	"strconv"
 
@@ -31,6 +33,9 @@ func (h *DocumentHandler) RegisterRoutes(router *gin.Engine) {
 		v1.GET("/documents", h.ListDocuments)
 		v1.GET("/documents/:id", h.GetDocument)
 		v1.GET("/documents/:id/graph", h.GetDocumentGraph)
+		v1.GET("/documents/:id/file", h.DownloadDocumentFile)
+		v1.POST("/documents/:id/reprocess", h.ReprocessDocument)
+		v1.DELETE("/documents/:id", h.DeleteDocument)
 	}
 }
 
@@ -56,12 +61,21 @@ func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 		notebookID = &id
 	}
 
-	doc, err := h.ingestionService.ProcessUpload(c.Request.Context(), file, header, notebookID)
+	doc, created, err := h.ingestionService.ProcessUpload(c.Request.Context(), file, header, notebookID)
+	if errors.Is(err, service.ErrNotebookNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if !created {
+		// Identical content already exists in this notebook
+		c.JSON(http.StatusOK, doc)
+		return
+	}
 	c.JSON(http.StatusCreated, doc)
 }
 
@@ -83,7 +97,7 @@ func (h *DocumentHandler) GetDocument(c *gin.Context) {
 	c.JSON(http.StatusOK, doc)
 }
 
-// GetDocumentGraph handles retrieving the graph data for a document.
+// GetDocumentGraph handles retrieving the graph data for a document. Query: as_of.
 func (h *DocumentHandler) GetDocumentGraph(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -92,7 +106,13 @@ func (h *DocumentHandler) GetDocumentGraph(c *gin.Context) {
 		return
 	}
 
-	graph, err := h.docService.GetGraph(c.Request.Context(), id)
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	graph, err := h.docService.GetGraph(c.Request.Context(), id, asOf)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -101,6 +121,86 @@ func (h *DocumentHandler) GetDocumentGraph(c *gin.Context) {
 	c.JSON(http.StatusOK, graph)
 }
 
+// DownloadDocumentFile redirects to a presigned URL for the document's file, or streams it directly.
+func (h *DocumentHandler) DownloadDocumentFile(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	file, err := h.docService.GetFile(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) || errors.Is(err, service.ErrFileNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if file.URL != "" {
+		c.Redirect(http.StatusFound, file.URL)
+		return
+	}
+	defer file.Content.Close()
+
+	contentType := file.Document.MimeType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.DataFromReader(http.StatusOK, file.Size, contentType, file.Content, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": file.Document.Filename}),
+	})
+}
+
+// ReprocessDocument starts extracting a document again from its stored file.
+func (h *DocumentHandler) ReprocessDocument(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	doc, err := h.ingestionService.Reprocess(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDocumentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrNotReprocessable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, doc)
+}
+
+// DeleteDocument handles deleting a document.
+func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.docService.DeleteDocument(c.Request.Context(), id); err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Document deleted"})
+}
+
 // ListDocuments handles listing documents.
 func (h *DocumentHandler) ListDocuments(c *gin.Context) {
 	pageStr := c.DefaultQuery("page", "1")
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suyw-0123/graphweaver/internal/service"
+)
+
+// EvidenceHandler handles HTTP requests for the source text behind graph entities.
+type EvidenceHandler struct {
+	evidenceService service.EvidenceService
+}
+
+// NewEvidenceHandler creates a new EvidenceHandler.
+func NewEvidenceHandler(evidenceService service.EvidenceService) *EvidenceHandler {
+	return &EvidenceHandler{evidenceService: evidenceService}
+}
+
+// RegisterRoutes registers the evidence routes.
+func (h *EvidenceHandler) RegisterRoutes(r *gin.Engine) {
+	v1 := r.Group("/api/v1")
+	{
+		v1.GET("/nodes/:id/evidence", h.GetNodeEvidence)
+		v1.GET("/edges/:id/evidence", h.GetEdgeEvidence)
+	}
+}
+
+// GetNodeEvidence returns the chunk passages a node was extracted from.
+func (h *EvidenceHandler) GetNodeEvidence(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	mentions, err := h.evidenceService.GetNodeEvidence(c.Request.Context(), id)
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"evidence": mentions})
+}
+
+// GetEdgeEvidence returns the chunk passages supporting an edge.
+func (h *EvidenceHandler) GetEdgeEvidence(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	mentions, err := h.evidenceService.GetEdgeEvidence(c.Request.Context(), id)
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"evidence": mentions})
+}
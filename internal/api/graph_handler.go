@@ -0,0 +1,366 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/export"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/internal/service"
+)
+
+// GraphHandler handles HTTP requests for graph exploration.
+type GraphHandler struct {
+	graphService service.GraphService
+}
+
+// NewGraphHandler creates a new GraphHandler.
+func NewGraphHandler(graphService service.GraphService) *GraphHandler {
+	return &GraphHandler{graphService: graphService}
+}
+
+// RegisterRoutes registers the graph routes.
+func (h *GraphHandler) RegisterRoutes(r *gin.Engine) {
+	v1 := r.Group("/api/v1")
+	{
+		v1.GET("/nodes/:id/neighbors", h.GetNeighbors)
+		v1.GET("/nodes/:id/subgraph", h.GetSubgraph)
+		v1.GET("/graph/path", h.FindPath)
+
+		v1.POST("/documents/:id/graph/analytics", h.ComputeDocumentAnalytics)
+		v1.POST("/notebooks/:id/graph/analytics", h.ComputeNotebookAnalytics)
+		v1.GET("/documents/:id/graph/key-entities", h.GetDocumentKeyEntities)
+		v1.GET("/notebooks/:id/graph/key-entities", h.GetNotebookKeyEntities)
+		v1.GET("/notebooks/:id/graph/nodes", h.FindNodes)
+		v1.GET("/notebooks/:id/graph/edges", h.FindEdges)
+
+		v1.GET("/documents/:id/graph/export", h.ExportDocumentGraph)
+		v1.GET("/notebooks/:id/graph/export", h.ExportNotebookGraph)
+	}
+}
+
+// GetNeighbors handles neighbor expansion.
+// Query: direction=out|in|both, relation=TYPE (repeatable or comma-separated), depth, limit, as_of.
+func (h *GraphHandler) GetNeighbors(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	direction := c.DefaultQuery("direction", repository.DirectionBoth)
+	if !validDirection(direction) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "direction must be out, in or both"})
+		return
+	}
+
+	var relations []string
+	for _, r := range c.QueryArray("relation") {
+		for _, part := range strings.Split(r, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				relations = append(relations, part)
+			}
+		}
+	}
+
+	depth, _ := strconv.Atoi(c.DefaultQuery("depth", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	graph, err := h.graphService.GetNeighbors(c.Request.Context(), id, repository.NeighborQuery{
+		Direction:     direction,
+		RelationTypes: relations,
+		Depth:         depth,
+		Limit:         limit,
+		AsOf:          asOf,
+	})
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, graph)
+}
+
+// GetSubgraph handles ego-subgraph extraction. Query: depth, limit, as_of.
+func (h *GraphHandler) GetSubgraph(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	depth, _ := strconv.Atoi(c.DefaultQuery("depth", "2"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	graph, err := h.graphService.GetSubgraph(c.Request.Context(), id, depth, limit, asOf)
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, graph)
+}
+
+// FindPath handles shortest path queries. Query: from, to, direction, max_depth, as_of.
+func (h *GraphHandler) FindPath(c *gin.Context) {
+	fromID, err1 := strconv.ParseInt(c.Query("from"), 10, 64)
+	toID, err2 := strconv.ParseInt(c.Query("to"), 10, 64)
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be node ids"})
+		return
+	}
+
+	direction := c.DefaultQuery("direction", repository.DirectionBoth)
+	if !validDirection(direction) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "direction must be out, in or both"})
+		return
+	}
+	maxDepth, _ := strconv.Atoi(c.DefaultQuery("max_depth", "4"))
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	graph, err := h.graphService.FindPath(c.Request.Context(), fromID, toID, direction, maxDepth, asOf)
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	if len(graph.Nodes) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no path found"})
+		return
+	}
+	c.JSON(http.StatusOK, graph)
+}
+
+// ComputeDocumentAnalytics recomputes centrality and community scores for a document graph.
+func (h *GraphHandler) ComputeDocumentAnalytics(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	result, err := h.graphService.ComputeDocumentAnalytics(c.Request.Context(), id)
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ComputeNotebookAnalytics recomputes centrality and community scores across a notebook graph.
+func (h *GraphHandler) ComputeNotebookAnalytics(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	result, err := h.graphService.ComputeNotebookAnalytics(c.Request.Context(), id)
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetDocumentKeyEntities lists a document's most central entities. Query: limit.
+func (h *GraphHandler) GetDocumentKeyEntities(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	nodes, err := h.graphService.GetDocumentKeyEntities(c.Request.Context(), id, limit)
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entities": nodes})
+}
+
+// GetNotebookKeyEntities lists a notebook's most central entities. Query: limit.
+func (h *GraphHandler) GetNotebookKeyEntities(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	nodes, err := h.graphService.GetNotebookKeyEntities(c.Request.Context(), id, limit)
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entities": nodes})
+}
+
+// FindNodes lists the notebook's nodes matching property values.
+// Query: label, prop.<key>=<value> (repeatable), properties=<JSON object>, limit.
+func (h *GraphHandler) FindNodes(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	f, err := parsePropertyFilter(c, c.Query("label"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	nodes, err := h.graphService.FindNodes(c.Request.Context(), id, f)
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"nodes": nodes})
+}
+
+// FindEdges lists the notebook's edges matching property values.
+// Query: type, prop.<key>=<value> (repeatable), properties=<JSON object>, limit, as_of.
+func (h *GraphHandler) FindEdges(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	f, err := parsePropertyFilter(c, c.Query("type"))
+	if err == nil {
+		f.AsOf, err = parseAsOf(c)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	edges, err := h.graphService.FindEdges(c.Request.Context(), id, f)
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"edges": edges})
+}
+
+// parsePropertyFilter reads the property conditions of a query. A prop.<key> value is
+// matched as JSON when it parses as a number, boolean or quoted string, and as plain text
+// otherwise, so prop.year=1998 matches the number and prop.year="1998" the string.
+func parsePropertyFilter(c *gin.Context, typ string) (repository.PropertyFilter, error) {
+	f := repository.PropertyFilter{Type: typ, Properties: entity.Properties{}}
+	f.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+	if raw := c.Query("properties"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &f.Properties); err != nil {
+			return f, fmt.Errorf("properties must be a JSON object")
+		}
+	}
+	for key, values := range c.Request.URL.Query() {
+		name, ok := strings.CutPrefix(key, "prop.")
+		if !ok || name == "" || len(values) == 0 {
+			continue
+		}
+		var v any = values[0]
+		var parsed any
+		if json.Unmarshal([]byte(values[0]), &parsed) == nil {
+			switch parsed.(type) {
+			case float64, bool, string:
+				v = parsed
+			}
+		}
+		f.Properties[name] = v
+	}
+	return f, nil
+}
+
+// ExportDocumentGraph downloads a document graph.
+// Query: format=graphml|gexf|cypher|jsonld|turtle|csv (default graphml), as_of.
+func (h *GraphHandler) ExportDocumentGraph(c *gin.Context) {
+	h.exportGraph(c, "document", h.graphService.GetDocumentGraph)
+}
+
+// ExportNotebookGraph downloads the combined graph of a notebook. Query: format, as for documents.
+func (h *GraphHandler) ExportNotebookGraph(c *gin.Context) {
+	h.exportGraph(c, "notebook", h.graphService.GetNotebookGraph)
+}
+
+func (h *GraphHandler) exportGraph(c *gin.Context, scope string, load func(ctx context.Context, id int64, asOf *entity.Date) (*service.GraphData, error)) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	format := c.DefaultQuery("format", export.FormatGraphML)
+	if !export.Supported(format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be graphml, gexf, cypher, jsonld, turtle or csv"})
+		return
+	}
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	graph, err := load(c.Request.Context(), id, asOf)
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+
+	name := fmt.Sprintf("%s-%d", scope, id)
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, export.Extension(format)))
+	c.Status(http.StatusOK)
+	if err := export.Write(c.Writer, format, &export.Graph{Name: name, Nodes: graph.Nodes, Edges: graph.Edges}); err != nil {
+		// Headers are already sent; abort so the client sees a truncated response
+		c.Error(err)
+		c.Abort()
+	}
+}
+
+// parseAsOf reads the optional as_of=YYYY-MM-DD query parameter that restricts a graph to
+// the edges valid on that day.
+func parseAsOf(c *gin.Context) (*entity.Date, error) {
+	raw := c.Query("as_of")
+	if raw == "" {
+		return nil, nil
+	}
+	d, err := entity.ParseDate(raw)
+	if err != nil {
+		return nil, fmt.Errorf("as_of: %w", err)
+	}
+	return &d, nil
+}
+
+func validDirection(d string) bool {
+	return d == repository.DirectionOut || d == repository.DirectionIn || d == repository.DirectionBoth
+}
+
+// writeGraphError maps service errors to HTTP status codes.
+func writeGraphError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrNodeNotFound) || errors.Is(err, service.ErrDocumentNotFound) ||
+		errors.Is(err, service.ErrNotebookNotFound) || errors.Is(err, service.ErrEdgeNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suyw-0123/graphweaver/internal/importer"
+	"github.com/suyw-0123/graphweaver/internal/service"
+)
+
+// ImportHandler handles HTTP requests for importing external graphs.
+type ImportHandler struct {
+	importService service.ImportService
+}
+
+// NewImportHandler creates a new ImportHandler.
+func NewImportHandler(importService service.ImportService) *ImportHandler {
+	return &ImportHandler{importService: importService}
+}
+
+// RegisterRoutes registers the import routes.
+func (h *ImportHandler) RegisterRoutes(r *gin.Engine) {
+	v1 := r.Group("/api/v1")
+	{
+		v1.POST("/notebooks/:id/graph/import", h.ImportGraph)
+	}
+}
+
+// ImportGraph imports a graph into a notebook as a synthetic document.
+// Multipart form: file (CSV nodes file or zip, GraphData JSON or Turtle), or nodes and edges
+// CSV files; format=csv|json|turtle (detected from the file name by default);
+// match=name_label|name|none (default name_label).
+func (h *ImportHandler) ImportGraph(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	match := c.DefaultPostForm("match", service.MatchNameLabel)
+	if match != service.MatchNameLabel && match != service.MatchName && match != service.MatchNone {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "match must be name_label, name or none"})
+		return
+	}
+
+	filename, graph, err := parseImportForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.importService.ImportGraph(c.Request.Context(), id, filename, graph, match)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyImport) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// parseImportForm parses the uploaded graph and returns it with the name of the source file.
+func parseImportForm(c *gin.Context) (string, *importer.Graph, error) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		file, header, err = c.Request.FormFile("nodes")
+		if err != nil {
+			return "", nil, fmt.Errorf("file or nodes is required")
+		}
+	}
+	defer file.Close()
+
+	format := c.PostForm("format")
+	if format == "" {
+		if format = importer.DetectFormat(header.Filename); format == "" {
+			return "", nil, fmt.Errorf("cannot detect the format of %s; set format to csv, json or turtle", header.Filename)
+		}
+	}
+
+	var graph *importer.Graph
+	switch format {
+	case importer.FormatCSV:
+		if strings.EqualFold(filepath.Ext(header.Filename), ".zip") {
+			graph, err = importer.ParseCSVZip(file, header.Size)
+			break
+		}
+		var edges io.Reader // optional
+		if f, _, ferr := c.Request.FormFile("edges"); ferr == nil {
+			defer f.Close()
+			edges = f
+		}
+		graph, err = importer.ParseCSV(file, edges)
+	case importer.FormatJSON:
+		graph, err = importer.ParseJSON(file)
+	case importer.FormatTurtle:
+		graph, err = importer.ParseTurtle(file)
+	default:
+		return "", nil, fmt.Errorf("format must be csv, json or turtle")
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return header.Filename, graph, nil
+}
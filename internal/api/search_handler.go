@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suyw-0123/graphweaver/internal/service"
+)
+
+// SearchHandler handles HTTP requests for chunk and entity search without answer generation.
+type SearchHandler struct {
+	retrievalService service.RetrievalService
+	entityService    service.EntityLinkingService
+}
+
+// NewSearchHandler creates a new SearchHandler.
+func NewSearchHandler(retrievalService service.RetrievalService, entityService service.EntityLinkingService) *SearchHandler {
+	return &SearchHandler{retrievalService: retrievalService, entityService: entityService}
+}
+
+// RegisterRoutes registers the search routes.
+func (h *SearchHandler) RegisterRoutes(r *gin.Engine) {
+	v1 := r.Group("/api/v1")
+	{
+		v1.POST("/notebooks/:id/search", h.Search)
+		v1.GET("/notebooks/:id/entities", h.SearchEntities)
+	}
+}
+
+// searchRequest is the body of a search. Mode is hybrid, vector, lexical or graph;
+// score_threshold is the minimum cosine similarity of vector matches.
+type searchRequest struct {
+	Query          string  `json:"query" binding:"required"`
+	TopK           int     `json:"top_k"`
+	Mode           string  `json:"mode"`
+	ScoreThreshold float64 `json:"score_threshold"`
+	DocumentIDs    []int64 `json:"document_ids"`
+	VectorWeight   float64 `json:"vector_weight"`
+	LexicalWeight  float64 `json:"lexical_weight"`
+}
+
+// Search returns the notebook's chunks ranked by relevance to a query, with their documents
+// and mentioned entities.
+func (h *SearchHandler) Search(c *gin.Context) {
+	notebookID, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req searchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query is required"})
+		return
+	}
+
+	results, err := h.retrievalService.Search(c.Request.Context(), notebookID, service.RetrievalQuery{
+		Query:          req.Query,
+		TopK:           req.TopK,
+		Mode:           req.Mode,
+		ScoreThreshold: req.ScoreThreshold,
+		DocumentIDs:    req.DocumentIDs,
+		VectorWeight:   req.VectorWeight,
+		LexicalWeight:  req.LexicalWeight,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRetrieval) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// SearchEntities returns the notebook's nodes semantically similar to a query. Query: q, limit.
+func (h *SearchHandler) SearchEntities(c *gin.Context) {
+	notebookID, ok := parseID(c)
+	if !ok {
+		return
+	}
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	entities, err := h.entityService.SearchEntities(c.Request.Context(), notebookID, query, limit)
+	if err != nil {
+		writeGraphError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entities": entities})
+}
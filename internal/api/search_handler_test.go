@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/internal/service"
+)
+
+type searchNotebooks struct {
+	repository.NotebookRepository
+}
+
+func (searchNotebooks) GetByID(ctx context.Context, id int64) (*entity.Notebook, error) {
+	return &entity.Notebook{ID: id}, nil
+}
+
+type searchDocs struct {
+	repository.DocumentRepository
+}
+
+func (searchDocs) ListAllByNotebook(ctx context.Context, notebookID int64) ([]*entity.Document, error) {
+	return []*entity.Document{{ID: 1, Filename: "a.txt"}}, nil
+}
+
+// textChunks finds 60 chunks for every full-text search and records the searched documents.
+type textChunks struct {
+	repository.ChunkRepository
+	docIDs []int64
+}
+
+func (r *textChunks) SearchChunks(ctx context.Context, notebookID int64, query string, docIDs []int64, limit int) ([]*repository.LexicalMatch, error) {
+	r.docIDs = docIDs
+	matches := []*repository.LexicalMatch{}
+	for i := range min(60, limit) {
+		matches = append(matches, &repository.LexicalMatch{Chunk: entity.Chunk{ID: fmt.Sprintf("c%02d", i), DocumentID: 1}, Score: float64(60 - i)})
+	}
+	return matches, nil
+}
+
+type noMentions struct {
+	repository.MentionRepository
+}
+
+func (noMentions) ListByChunks(ctx context.Context, chunkIDs []string) ([]*entity.Mention, error) {
+	return nil, nil
+}
+
+func TestSearch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	chunks := &textChunks{}
+	// Without an embedding client, only full-text search can answer
+	retrieval := service.NewRetrievalService(searchDocs{}, searchNotebooks{}, chunks, nil, noMentions{}, nil, nil, nil, nil)
+	r := gin.New()
+	NewSearchHandler(retrieval, nil).RegisterRoutes(r)
+
+	search := func(path, body string) (int, []*service.SearchResult) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		var resp struct {
+			Results []*service.SearchResult `json:"results"`
+		}
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response %s: %v", w.Body.String(), err)
+			}
+		}
+		return w.Code, resp.Results
+	}
+
+	for _, tc := range []struct {
+		name string
+		path string
+		body string
+	}{
+		{"missing query", "/api/v1/notebooks/1/search", `{"top_k": 5}`},
+		{"invalid notebook", "/api/v1/notebooks/x/search", `{"query": "q"}`},
+		{"unknown mode", "/api/v1/notebooks/1/search", `{"query": "q", "mode": "fuzzy"}`},
+		{"negative weight", "/api/v1/notebooks/1/search", `{"query": "q", "lexical_weight": -1}`},
+		{"threshold above 1", "/api/v1/notebooks/1/search", `{"query": "q", "score_threshold": 1.5}`},
+		{"negative threshold", "/api/v1/notebooks/1/search", `{"query": "q", "score_threshold": -0.5}`},
+	} {
+		if code, _ := search(tc.path, tc.body); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", tc.name, code)
+		}
+	}
+
+	code, results := search("/api/v1/notebooks/1/search", `{"query": "q", "mode": "lexical", "top_k": 80, "document_ids": [1]}`)
+	if code != http.StatusOK {
+		t.Fatalf("lexical search: status %d", code)
+	}
+	if len(results) != 50 {
+		t.Errorf("top_k 80 returned %d results, want 50", len(results))
+	}
+	if results[0].ID != "c00" || results[0].LexicalRank != 1 || results[0].Document == nil || results[0].Document.Filename != "a.txt" {
+		t.Errorf("first result = %+v, want c00 at lexical rank 1 from a.txt", results[0].RetrievedChunk)
+	}
+	if !slices.Equal(chunks.docIDs, []int64{1}) {
+		t.Errorf("searched documents %v, want [1]", chunks.docIDs)
+	}
+
+	// Hybrid search weighing only full text does not need the missing dense search
+	code, results = search("/api/v1/notebooks/1/search", `{"query": "q", "vector_weight": 0, "lexical_weight": 2, "top_k": 3}`)
+	if code != http.StatusOK || len(results) != 3 {
+		t.Errorf("hybrid search: status %d with %d results, want 200 with 3", code, len(results))
+	}
+	if code, _ := search("/api/v1/notebooks/1/search", `{"query": "q", "mode": "vector"}`); code != http.StatusInternalServerError {
+		t.Errorf("vector search without embeddings: status %d, want 500", code)
+	}
+}
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// Community is a cluster of related entities in a notebook graph with an LLM-written summary.
+// Communities form a hierarchy: level 0 is the coarsest, and each finer community points to
+// the community that contains it.
+type Community struct {
+	ID         int64     `db:"id" json:"id"`
+	NotebookID int64     `db:"notebook_id" json:"notebook_id"`
+	ParentID   *int64    `db:"parent_id" json:"parent_id,omitempty"`
+	Level      int       `db:"level" json:"level"`
+	Title      string    `db:"title" json:"title"`
+	Summary    string    `db:"summary" json:"summary"`
+	Rank       float64   `db:"rank" json:"rank"`
+	NodeCount  int       `db:"node_count" json:"node_count"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+
+	NodeIDs []int64 `db:"-" json:"node_ids,omitempty"`
+}
+
+// Community build statuses.
+const (
+	CommunityBuildRunning   = "running"
+	CommunityBuildCompleted = "completed"
+	CommunityBuildFailed    = "failed"
+)
+
+// CommunityBuild tracks the latest background build of a notebook's communities.
+type CommunityBuild struct {
+	NotebookID   int64      `db:"notebook_id" json:"notebook_id"`
+	Status       string     `db:"status" json:"status"`
+	ErrorMessage *string    `db:"error_message" json:"error_message,omitempty"`
+	Communities  int        `db:"communities" json:"communities"`
+	StartedAt    time.Time  `db:"started_at" json:"started_at"`
+	FinishedAt   *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+}
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DateLayout is the format of dates in the API.
+const DateLayout = "2006-01-02"
+
+// Date is a calendar day in UTC, encoded in JSON as YYYY-MM-DD.
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the day of t.
+func NewDate(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate parses a YYYY-MM-DD date.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, strings.TrimSpace(s))
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return Date{t}, nil
+}
+
+// ParseDatePeriod parses a YYYY, YYYY-MM or YYYY-MM-DD date and returns the first and last
+// day of the period it names.
+func ParseDatePeriod(s string) (start, end Date, err error) {
+	s = strings.TrimSpace(s)
+	for _, p := range []struct {
+		layout string
+		years  int
+		months int
+	}{
+		{"2006", 1, 0},
+		{"2006-01", 0, 1},
+		{DateLayout, 0, 0},
+	} {
+		t, err := time.Parse(p.layout, s)
+		if err != nil {
+			continue
+		}
+		if p.years == 0 && p.months == 0 {
+			return Date{t}, Date{t}, nil
+		}
+		return Date{t}, Date{t.AddDate(p.years, p.months, -1)}, nil
+	}
+	return Date{}, Date{}, fmt.Errorf("invalid date %q, expected YYYY, YYYY-MM or YYYY-MM-DD", s)
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	parsed, err := ParseDate(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (d Date) Value() (driver.Value, error) {
+	return d.Time, nil
+}
+
+// Scan implements sql.Scanner.
+func (d *Date) Scan(src any) error {
+	t, ok := src.(time.Time)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into date", src)
+	}
+	*d = NewDate(t)
+	return nil
+}
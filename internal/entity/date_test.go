@@ -0,0 +1,50 @@
+package entity
+
+import "testing"
+
+func TestParseDatePeriod(t *testing.T) {
+	tests := []struct {
+		in         string
+		start, end string
+		wantErr    bool
+	}{
+		{in: "2019", start: "2019-01-01", end: "2019-12-31"},
+		{in: "2020-02", start: "2020-02-01", end: "2020-02-29"},
+		{in: " 2021-07-15 ", start: "2021-07-15", end: "2021-07-15"},
+		{in: "", wantErr: true},
+		{in: "March 2019", wantErr: true},
+	}
+	for _, tt := range tests {
+		start, end, err := ParseDatePeriod(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDatePeriod(%q): expected an error", tt.in)
+			}
+			continue
+		}
+		if err != nil || start.String() != tt.start || end.String() != tt.end {
+			t.Errorf("ParseDatePeriod(%q) = %s, %s, %v; want %s, %s", tt.in, start, end, err, tt.start, tt.end)
+		}
+	}
+}
+
+func TestEdge_ValidAt(t *testing.T) {
+	from, _ := ParseDate("2019-03-01")
+	to, _ := ParseDate("2020-06-30")
+	e := &Edge{ValidFrom: &from, ValidTo: &to}
+
+	for day, want := range map[string]bool{
+		"2019-02-28": false,
+		"2019-03-01": true,
+		"2020-06-30": true,
+		"2020-07-01": false,
+	} {
+		d, _ := ParseDate(day)
+		if got := e.ValidAt(d); got != want {
+			t.Errorf("ValidAt(%s) = %v, want %v", day, got, want)
+		}
+	}
+	if !(&Edge{}).ValidAt(from) {
+		t.Error("expected an edge without an interval to always be valid")
+	}
+}
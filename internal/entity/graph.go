@@ -2,23 +2,56 @@ package entity
 
 import "time"
 
+// Provenance of nodes and edges.
+const (
+	SourceLLM    = "llm"    // extracted from a document
+	SourceHuman  = "human"  // created or corrected by a user
+	SourceImport = "import" // imported from an external graph
+)
+
 // Node represents a node in the knowledge graph.
 type Node struct {
-	ID         int64     `db:"id" json:"id"`
-	DocumentID int64     `db:"document_id" json:"document_id"`
-	Label      string    `db:"label" json:"label"`           // e.g., "Person", "Location"
-	Name       string    `db:"name" json:"name"`             // e.g., "Alice", "New York"
-	Properties string    `db:"properties" json:"properties"` // JSON string
-	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	ID         int64      `db:"id" json:"id"`
+	DocumentID int64      `db:"document_id" json:"document_id"`
+	Label      string     `db:"label" json:"label"` // e.g., "Person", "Location"
+	Name       string     `db:"name" json:"name"`   // e.g., "Alice", "New York"
+	Properties Properties `db:"properties" json:"properties"`
+	Source     string     `db:"source" json:"source"` // llm, human or import
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+
+	// Graph analytics scores, from the most recent document or notebook computation
+	PageRank         float64 `db:"pagerank" json:"pagerank"`
+	DegreeCentrality float64 `db:"degree_centrality" json:"degree_centrality"`
+	Betweenness      float64 `db:"betweenness" json:"betweenness"`
+	CommunityID      *int64  `db:"community_id" json:"community_id,omitempty"`
+}
+
+// NodeScore holds the analytics scores computed for a node.
+type NodeScore struct {
+	NodeID           int64   `db:"node_id" json:"node_id"`
+	PageRank         float64 `db:"pagerank" json:"pagerank"`
+	DegreeCentrality float64 `db:"degree_centrality" json:"degree_centrality"`
+	Betweenness      float64 `db:"betweenness" json:"betweenness"`
+	CommunityID      int64   `db:"community_id" json:"community_id"`
 }
 
 // Edge represents a relationship between two nodes.
 type Edge struct {
-	ID           int64     `db:"id" json:"id"`
-	DocumentID   int64     `db:"document_id" json:"document_id"`
-	SourceNodeID int64     `db:"source_node_id" json:"source_node_id"`
-	TargetNodeID int64     `db:"target_node_id" json:"target_node_id"`
-	RelationType string    `db:"relation_type" json:"relation_type"` // e.g., "LIVES_IN"
-	Properties   string    `db:"properties" json:"properties"`       // JSON string
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	ID           int64      `db:"id" json:"id"`
+	DocumentID   int64      `db:"document_id" json:"document_id"`
+	SourceNodeID int64      `db:"source_node_id" json:"source_node_id"`
+	TargetNodeID int64      `db:"target_node_id" json:"target_node_id"`
+	RelationType string     `db:"relation_type" json:"relation_type"` // e.g., "LIVES_IN"
+	Properties   Properties `db:"properties" json:"properties"`
+	Source       string     `db:"source" json:"source"` // llm, human or import
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+
+	// Validity interval of the relation, inclusive; nil bounds are open
+	ValidFrom *Date `db:"valid_from" json:"valid_from,omitempty"`
+	ValidTo   *Date `db:"valid_to" json:"valid_to,omitempty"`
+}
+
+// ValidAt reports whether the relation holds on the given day.
+func (e *Edge) ValidAt(day Date) bool {
+	return (e.ValidFrom == nil || !e.ValidFrom.After(day.Time)) && (e.ValidTo == nil || !e.ValidTo.Before(day.Time))
 }
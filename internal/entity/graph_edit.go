@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// GraphEdit records a manual change to a node or edge. Before and After hold JSON snapshots
+// of the entity; Before is empty for creations and After for deletions.
+type GraphEdit struct {
+	ID         int64     `db:"id" json:"id"`
+	DocumentID int64     `db:"document_id" json:"document_id"`
+	EntityType string    `db:"entity_type" json:"entity_type"` // node, edge
+	EntityID   int64     `db:"entity_id" json:"entity_id"`
+	Action     string    `db:"action" json:"action"` // create, update, delete, merge, split
+	Before     *string   `db:"before" json:"before,omitempty"`
+	After      *string   `db:"after" json:"after,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// Mention links a node or edge to the chunk text it was extracted from. Exactly one of
+// NodeID and EdgeID is set. Offsets are character positions of Quote within the chunk.
+type Mention struct {
+	ID          int64     `db:"id" json:"id"`
+	DocumentID  int64     `db:"document_id" json:"document_id"`
+	NodeID      *int64    `db:"node_id" json:"node_id,omitempty"`
+	EdgeID      *int64    `db:"edge_id" json:"edge_id,omitempty"`
+	ChunkID     string    `db:"chunk_id" json:"chunk_id"`
+	ChunkIndex  int       `db:"chunk_index" json:"chunk_index"` // read only, joined from chunks
+	StartOffset int       `db:"start_offset" json:"start_offset"`
+	EndOffset   int       `db:"end_offset" json:"end_offset"`
+	Quote       string    `db:"quote" json:"quote"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
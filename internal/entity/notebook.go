@@ -8,4 +8,6 @@ type Notebook struct {
 	Description string    `db:"description" json:"description"`
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	// ContentVersion changes whenever the notebook's documents or graph change
+	ContentVersion int64 `db:"content_version" json:"content_version"`
 }
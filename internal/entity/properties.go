@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Properties holds the free-form attributes of a node or edge, such as a description,
+// dates, roles or quantities. It is stored as a JSONB object.
+type Properties map[string]any
+
+// Value implements driver.Valuer. A nil map is stored as an empty object.
+func (p Properties) Value() (driver.Value, error) {
+	if p == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(map[string]any(p))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode properties: %w", err)
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner. NULL scans as an empty map.
+func (p *Properties) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*p = Properties{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into properties", src)
+	}
+	return p.UnmarshalJSON(raw)
+}
+
+// UnmarshalJSON decodes a JSON object. A JSON null decodes as an empty map, and a string
+// holding a JSON object, as written before properties were typed, is decoded as that object.
+func (p *Properties) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if json.Unmarshal(data, &encoded) == nil {
+		data = []byte(encoded)
+	}
+	var m map[string]any
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("properties must be a JSON object: %w", err)
+		}
+	}
+	if m == nil {
+		m = map[string]any{}
+	}
+	*p = m
+	return nil
+}
+
+// String returns the properties as a JSON object.
+func (p Properties) String() string {
+	v, err := p.Value()
+	if err != nil {
+		return "{}"
+	}
+	return v.(string)
+}
+
+// Contains reports whether p contains filter, following the semantics of the JSONB
+// containment operator @>: nested objects match by containment and arrays match if they
+// contain every element of the filter's array.
+func (p Properties) Contains(filter Properties) bool {
+	for k, want := range filter {
+		got, ok := p[k]
+		if !ok || !jsonContains(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonContains(got, want any) bool {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		return ok && Properties(g).Contains(w)
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			return false
+		}
+		for _, wv := range w {
+			found := false
+			for _, gv := range g {
+				if jsonContains(gv, wv) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return got == want
+	}
+}
@@ -0,0 +1,54 @@
+package entity
+
+import "testing"
+
+func TestProperties_ScanValue(t *testing.T) {
+	props := Properties{"description": "say \"hi\"\nC:\\path", "year": 1998.0}
+	v, err := props.Value()
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+
+	var got Properties
+	if err := got.Scan([]byte(v.(string))); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if got["description"] != props["description"] || got["year"] != 1998.0 {
+		t.Errorf("expected properties to round-trip, got %v", got)
+	}
+
+	if err := got.Scan(nil); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("expected NULL to scan as an empty map, got %v (%v)", got, err)
+	}
+	if v, _ := Properties(nil).Value(); v != "{}" {
+		t.Errorf("expected nil properties to be stored as {}, got %v", v)
+	}
+}
+
+func TestProperties_Contains(t *testing.T) {
+	props := Properties{
+		"role":    "CEO",
+		"year":    1998.0,
+		"tags":    []any{"founder", "board"},
+		"address": map[string]any{"city": "Taipei", "zip": "100"},
+	}
+	tests := []struct {
+		filter Properties
+		want   bool
+	}{
+		{Properties{}, true},
+		{Properties{"role": "CEO"}, true},
+		{Properties{"role": "CTO"}, false},
+		{Properties{"year": 1998.0}, true},
+		{Properties{"year": "1998"}, false},
+		{Properties{"tags": []any{"board"}}, true},
+		{Properties{"tags": "board"}, false},
+		{Properties{"address": map[string]any{"city": "Taipei"}}, true},
+		{Properties{"missing": "x"}, false},
+	}
+	for _, tt := range tests {
+		if got := props.Contains(tt.filter); got != tt.want {
+			t.Errorf("Contains(%v) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
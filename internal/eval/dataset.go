@@ -0,0 +1,73 @@
+// Package eval measures retrieval and answer quality of a notebook against a dataset of
+// questions with known answers and relevant documents.
+package eval
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Question is one dataset entry. Relevant documents are given by filename, which stays the
+// same when a corpus is uploaded again, or by ID.
+type Question struct {
+	ID                  string   `json:"id"`
+	Question            string   `json:"question"`
+	ExpectedAnswer      string   `json:"expected_answer"`
+	RelevantDocuments   []string `json:"relevant_documents,omitempty"`
+	RelevantDocumentIDs []int64  `json:"relevant_document_ids,omitempty"`
+}
+
+// Dataset is a named list of questions.
+type Dataset struct {
+	Name      string      `json:"name"`
+	Questions []*Question `json:"questions"`
+}
+
+// LoadDataset reads a dataset from a JSON file holding a Dataset, or a JSON Lines file
+// (.jsonl) with one Question per line. Questions without an ID are numbered.
+func LoadDataset(path string) (*Dataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer f.Close()
+
+	ds := &Dataset{}
+	if strings.EqualFold(filepath.Ext(path), ".jsonl") {
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			var q Question
+			if err := json.Unmarshal([]byte(text), &q); err != nil {
+				return nil, fmt.Errorf("failed to parse dataset line %d: %w", line, err)
+			}
+			ds.Questions = append(ds.Questions, &q)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read dataset: %w", err)
+		}
+	} else if err := json.NewDecoder(f).Decode(ds); err != nil {
+		return nil, fmt.Errorf("failed to parse dataset: %w", err)
+	}
+
+	if ds.Name == "" {
+		ds.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	for i, q := range ds.Questions {
+		if strings.TrimSpace(q.Question) == "" {
+			return nil, fmt.Errorf("dataset question %d has no question", i+1)
+		}
+		if q.ID == "" {
+			q.ID = fmt.Sprintf("q%d", i+1)
+		}
+	}
+	return ds, nil
+}
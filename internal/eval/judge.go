@@ -0,0 +1,60 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/suyw-0123/graphweaver/pkg/llm"
+)
+
+// Judge verdicts and their correctness scores.
+var verdictScores = map[string]float64{
+	"correct":           1,
+	"partially_correct": 0.5,
+	"incorrect":         0,
+}
+
+// JudgeAnswer asks the LLM whether an answer is correct given the expected answer, and
+// returns a score of 1, 0.5 or 0 with the judge's reason.
+func JudgeAnswer(ctx context.Context, client llm.Client, question, expected, answer string) (float64, string, error) {
+	prompt := fmt.Sprintf(`You are grading answers to questions about a collection of documents.
+Compare the Answer to the Expected Answer. Judge only whether the facts are right and complete, not the wording or style.
+An answer that says it does not know is incorrect unless the Expected Answer says the same.
+Return ONLY a valid JSON object with the following structure:
+{"verdict": "correct", "reason": "One sentence explaining the verdict"}
+The verdict is one of: correct, partially_correct, incorrect.
+
+Question: %s
+
+Expected Answer: %s
+
+Answer: %s`, question, expected, answer)
+
+	response, err := client.GenerateContent(ctx, prompt)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to judge answer: %w", err)
+	}
+	var result struct {
+		Verdict string `json:"verdict"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(cleanJSON(response)), &result); err != nil {
+		return 0, "", fmt.Errorf("failed to parse judgement: %w", err)
+	}
+	score, ok := verdictScores[strings.ToLower(strings.TrimSpace(result.Verdict))]
+	if !ok {
+		return 0, "", fmt.Errorf("unknown verdict %q", result.Verdict)
+	}
+	return score, result.Reason, nil
+}
+
+// cleanJSON strips the Markdown code fence LLMs often wrap JSON in.
+func cleanJSON(response string) string {
+	s := strings.TrimSpace(response)
+	s = strings.TrimPrefix(s, "```json")
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimSuffix(s, "```")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,120 @@
+package eval
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Retrieval metrics are computed over documents: ranked holds the document of each retrieved
+// chunk in rank order, and a document counts at the rank of its first chunk.
+
+// RecallAtK is the share of relevant documents among the documents of the top k chunks.
+func RecallAtK(ranked []int64, relevant map[int64]bool, k int) float64 {
+	if len(relevant) == 0 {
+		return 0
+	}
+	found := 0
+	for _, id := range distinct(ranked[:min(len(ranked), k)]) {
+		if relevant[id] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(relevant))
+}
+
+// ReciprocalRank is 1 over the rank of the first relevant document, 0 if none was retrieved.
+// Its mean over questions is the MRR.
+func ReciprocalRank(ranked []int64, relevant map[int64]bool) float64 {
+	for i, id := range distinct(ranked) {
+		if relevant[id] {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// NDCGAtK is the discounted cumulative gain of the top k documents with binary relevance,
+// normalized by that of an ideal ranking.
+func NDCGAtK(ranked []int64, relevant map[int64]bool, k int) float64 {
+	if len(relevant) == 0 {
+		return 0
+	}
+	dcg := 0.0
+	for i, id := range distinct(ranked) {
+		if i == k {
+			break
+		}
+		if relevant[id] {
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+	}
+	ideal := 0.0
+	for i := 0; i < min(len(relevant), k); i++ {
+		ideal += 1 / math.Log2(float64(i+2))
+	}
+	return dcg / ideal
+}
+
+// distinct returns the IDs in order of first occurrence.
+func distinct(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	var out []int64
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// CosineSimilarity is the cosine of the angle between two vectors, 0 if either is zero or
+// their lengths differ.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// TokenF1 is the harmonic mean of precision and recall of the answer's lowercased word
+// tokens against the expected answer's, as in SQuAD.
+func TokenF1(answer, expected string) float64 {
+	got, want := tokens(answer), tokens(expected)
+	if len(got) == 0 || len(want) == 0 {
+		return 0
+	}
+	counts := make(map[string]int, len(want))
+	for _, t := range want {
+		counts[t]++
+	}
+	common := 0
+	for _, t := range got {
+		if counts[t] > 0 {
+			counts[t]--
+			common++
+		}
+	}
+	if common == 0 {
+		return 0
+	}
+	precision := float64(common) / float64(len(got))
+	recall := float64(common) / float64(len(want))
+	return 2 * precision * recall / (precision + recall)
+}
+
+func tokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
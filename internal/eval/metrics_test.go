@@ -0,0 +1,51 @@
+package eval
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRetrievalMetrics(t *testing.T) {
+	// Chunks of documents 3, 1, 3, 2, 4 in rank order: documents rank 3, 1, 2, 4
+	ranked := []int64{3, 1, 3, 2, 4}
+	relevant := map[int64]bool{1: true, 4: true}
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"recall@2", RecallAtK(ranked, relevant, 2), 0.5},
+		{"recall@5", RecallAtK(ranked, relevant, 5), 1},
+		{"reciprocal rank", ReciprocalRank(ranked, relevant), 0.5},
+		{"reciprocal rank none", ReciprocalRank(ranked, map[int64]bool{9: true}), 0},
+		{"ndcg@4", NDCGAtK(ranked, relevant, 4), (1/math.Log2(3) + 1/math.Log2(5)) / (1 + 1/math.Log2(3))},
+		{"ndcg perfect", NDCGAtK([]int64{1, 4}, relevant, 2), 1},
+		{"ndcg no relevant", NDCGAtK(ranked, nil, 3), 0},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestAnswerSimilarity(t *testing.T) {
+	if got := CosineSimilarity([]float32{1, 0}, []float32{2, 0}); math.Abs(got-1) > 1e-9 {
+		t.Errorf("parallel cosine = %v, want 1", got)
+	}
+	if got := CosineSimilarity([]float32{1, 0}, []float32{0, 1}); got != 0 {
+		t.Errorf("orthogonal cosine = %v, want 0", got)
+	}
+	if got := CosineSimilarity([]float32{1}, []float32{1, 0}); got != 0 {
+		t.Errorf("mismatched cosine = %v, want 0", got)
+	}
+
+	// 2 common tokens: precision 2/3, recall 2/4
+	if got, want := TokenF1("Paris, in France!", "the capital Paris France"), 4.0/7; math.Abs(got-want) > 1e-9 {
+		t.Errorf("token F1 = %v, want %v", got, want)
+	}
+	if got := TokenF1("", "Paris"); got != 0 {
+		t.Errorf("empty token F1 = %v, want 0", got)
+	}
+}
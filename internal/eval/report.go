@@ -0,0 +1,81 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes the report as a Markdown document: the run settings, the summary and a
+// table of per-question metrics, followed by the errors of failed questions.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Evaluation: %s\n\n", r.Dataset)
+	fmt.Fprintf(&sb, "- Notebook: %d\n", r.NotebookID)
+	fmt.Fprintf(&sb, "- Started: %s\n", r.StartedAt.Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(&sb, "- Retrieval: %s, k = %d\n", orDefault(r.Config.RetrievalMode, "hybrid"), r.Config.K)
+	if r.Config.SkipChat {
+		sb.WriteString("- Chat: skipped\n")
+	} else {
+		fmt.Fprintf(&sb, "- Chat: %s, expansion %s\n", orDefault(r.Config.ChatMode, "local"), orDefault(r.Config.Expansion, "none"))
+	}
+	fmt.Fprintf(&sb, "- Answer similarity: %s\n\n", r.SimilarityMethod)
+
+	k := r.Config.K
+	sb.WriteString("## Summary\n\n| Metric | Value |\n| --- | --- |\n")
+	fmt.Fprintf(&sb, "| Questions | %d |\n", r.Summary.Questions)
+	fmt.Fprintf(&sb, "| Recall@%d | %s |\n", k, formatMetric(r.Summary.RecallAtK))
+	fmt.Fprintf(&sb, "| MRR | %s |\n", formatMetric(r.Summary.MRR))
+	fmt.Fprintf(&sb, "| nDCG@%d | %s |\n", k, formatMetric(r.Summary.NDCGAtK))
+	fmt.Fprintf(&sb, "| Answer similarity | %s |\n", formatMetric(r.Summary.AnswerSimilarity))
+	fmt.Fprintf(&sb, "| Correctness | %s |\n", formatMetric(r.Summary.Correctness))
+	fmt.Fprintf(&sb, "| Failed | %d |\n\n", r.Summary.Failed)
+
+	sb.WriteString("## Questions\n\n")
+	fmt.Fprintf(&sb, "| ID | Question | Recall@%d | RR | nDCG@%d | Similarity | Correctness |\n", k, k)
+	sb.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+	for _, res := range r.Results {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s | %s |\n",
+			markdownCell(res.ID), markdownCell(res.Question),
+			formatMetric(res.RecallAtK), formatMetric(res.ReciprocalRank), formatMetric(res.NDCGAtK),
+			formatMetric(res.AnswerSimilarity), formatMetric(res.Correctness))
+	}
+
+	var failed []*QuestionResult
+	for _, res := range r.Results {
+		if len(res.Errors) > 0 {
+			failed = append(failed, res)
+		}
+	}
+	if len(failed) > 0 {
+		sb.WriteString("\n## Errors\n\n")
+		for _, res := range failed {
+			for _, e := range res.Errors {
+				fmt.Fprintf(&sb, "- %s: %s\n", res.ID, e)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func formatMetric(v *float64) string {
+	if v == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.3f", *v)
+}
+
+// markdownCell keeps text from breaking a table row.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
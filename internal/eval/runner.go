@@ -0,0 +1,272 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/internal/service"
+	"github.com/suyw-0123/graphweaver/pkg/embedding"
+	"github.com/suyw-0123/graphweaver/pkg/llm"
+)
+
+// Answer similarity methods.
+const (
+	SimilarityEmbedding = "embedding_cosine"
+	SimilarityTokenF1   = "token_f1"
+)
+
+// Config selects what an evaluation run exercises, so that runs with different settings
+// can be compared.
+type Config struct {
+	// K is the number of retrieved chunks the retrieval metrics look at.
+	K             int    `json:"k"`
+	RetrievalMode string `json:"retrieval_mode"`
+	ChatMode      string `json:"chat_mode"`
+	// Expansion rewrites the question before retrieval, for the retrieval metrics and for
+	// local chat alike.
+	Expansion string `json:"expansion,omitempty"`
+	// SkipChat evaluates retrieval only.
+	SkipChat bool `json:"skip_chat,omitempty"`
+}
+
+// QuestionResult holds the metrics of one question. Metrics that do not apply, such as
+// retrieval metrics for a question without relevant documents, are left out.
+type QuestionResult struct {
+	ID                   string   `json:"id"`
+	Question             string   `json:"question"`
+	RetrievedDocumentIDs []int64  `json:"retrieved_document_ids"`
+	ExpandedQueries      []string `json:"expanded_queries,omitempty"`
+	RecallAtK            *float64 `json:"recall_at_k,omitempty"`
+	ReciprocalRank       *float64 `json:"reciprocal_rank,omitempty"`
+	NDCGAtK              *float64 `json:"ndcg_at_k,omitempty"`
+	Answer               string   `json:"answer,omitempty"`
+	AnswerSimilarity     *float64 `json:"answer_similarity,omitempty"`
+	Correctness          *float64 `json:"correctness,omitempty"`
+	JudgeReason          string   `json:"judge_reason,omitempty"`
+	RetrievalMillis      int64    `json:"retrieval_ms"`
+	ChatMillis           int64    `json:"chat_ms,omitempty"`
+	Errors               []string `json:"errors,omitempty"`
+}
+
+// Summary averages each metric over the questions it applies to.
+type Summary struct {
+	Questions        int      `json:"questions"`
+	RecallAtK        *float64 `json:"recall_at_k,omitempty"`
+	MRR              *float64 `json:"mrr,omitempty"`
+	NDCGAtK          *float64 `json:"ndcg_at_k,omitempty"`
+	AnswerSimilarity *float64 `json:"answer_similarity,omitempty"`
+	Correctness      *float64 `json:"correctness,omitempty"`
+	Failed           int      `json:"failed"`
+}
+
+// Report is the outcome of an evaluation run.
+type Report struct {
+	Dataset    string    `json:"dataset"`
+	NotebookID int64     `json:"notebook_id"`
+	Config     Config    `json:"config"`
+	StartedAt  time.Time `json:"started_at"`
+	// SimilarityMethod tells how answer similarity was computed: embedding_cosine, or
+	// token_f1 without an embedding client.
+	SimilarityMethod string            `json:"similarity_method"`
+	Summary          Summary           `json:"summary"`
+	Results          []*QuestionResult `json:"results"`
+}
+
+// Runner evaluates a notebook against a dataset.
+type Runner struct {
+	retrieval       service.RetrievalService
+	chat            service.ChatService
+	docRepo         repository.DocumentRepository
+	embeddingClient embedding.Client
+	judge           llm.Client
+}
+
+// NewRunner creates a Runner. Without an embedding client answers are compared by token F1;
+// without a judge, correctness is not scored.
+func NewRunner(
+	retrieval service.RetrievalService,
+	chat service.ChatService,
+	docRepo repository.DocumentRepository,
+	embeddingClient embedding.Client,
+	judge llm.Client,
+) *Runner {
+	return &Runner{
+		retrieval:       retrieval,
+		chat:            chat,
+		docRepo:         docRepo,
+		embeddingClient: embeddingClient,
+		judge:           judge,
+	}
+}
+
+// Run asks every question of the dataset. A failing question is recorded in its result and
+// does not stop the run.
+func (r *Runner) Run(ctx context.Context, notebookID int64, ds *Dataset, cfg Config) (*Report, error) {
+	if cfg.K <= 0 {
+		cfg.K = 5
+	}
+	docs, err := r.docRepo.ListAllByNotebook(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	docIDsByName := make(map[string][]int64)
+	for _, d := range docs {
+		if !d.IsDeleted {
+			docIDsByName[d.Filename] = append(docIDsByName[d.Filename], d.ID)
+		}
+	}
+
+	report := &Report{
+		Dataset:          ds.Name,
+		NotebookID:       notebookID,
+		Config:           cfg,
+		StartedAt:        time.Now().UTC(),
+		SimilarityMethod: SimilarityTokenF1,
+		Results:          make([]*QuestionResult, 0, len(ds.Questions)),
+	}
+	if r.embeddingClient != nil {
+		report.SimilarityMethod = SimilarityEmbedding
+	}
+
+	for _, q := range ds.Questions {
+		res := &QuestionResult{ID: q.ID, Question: q.Question, RetrievedDocumentIDs: []int64{}}
+		relevant := make(map[int64]bool)
+		for _, id := range q.RelevantDocumentIDs {
+			relevant[id] = true
+		}
+		for _, name := range q.RelevantDocuments {
+			ids, ok := docIDsByName[name]
+			if !ok {
+				res.Errors = append(res.Errors, fmt.Sprintf("relevant document %q is not in the notebook", name))
+			}
+			for _, id := range ids {
+				relevant[id] = true
+			}
+		}
+
+		r.evaluateRetrieval(ctx, notebookID, q, cfg, relevant, res)
+		if !cfg.SkipChat && r.chat != nil {
+			r.evaluateAnswer(ctx, notebookID, q, cfg, res)
+		}
+		report.Results = append(report.Results, res)
+	}
+	report.Summary = summarize(report.Results)
+	return report, nil
+}
+
+func (r *Runner) evaluateRetrieval(ctx context.Context, notebookID int64, q *Question, cfg Config, relevant map[int64]bool, res *QuestionResult) {
+	start := time.Now()
+	query := service.RetrievalQuery{
+		Query: q.Question,
+		TopK:  cfg.K,
+		Mode:  cfg.RetrievalMode,
+	}
+	var chunks []*service.RetrievedChunk
+	var err error
+	switch {
+	case cfg.Expansion == "" || cfg.Expansion == service.ExpansionNone:
+		chunks, err = r.retrieval.Retrieve(ctx, notebookID, query)
+	case r.chat == nil:
+		err = fmt.Errorf("expansion %s needs a chat service", cfg.Expansion)
+	default:
+		// Expand the question the way chat does, so that expansions can be compared
+		chunks, res.ExpandedQueries, err = r.chat.Retrieve(ctx, notebookID, service.ChatRequest{
+			Query:     q.Question,
+			Expansion: cfg.Expansion,
+		}, query)
+	}
+	res.RetrievalMillis = time.Since(start).Milliseconds()
+	if err != nil {
+		res.Errors = append(res.Errors, fmt.Sprintf("retrieval: %v", err))
+		return
+	}
+	for _, c := range chunks {
+		res.RetrievedDocumentIDs = append(res.RetrievedDocumentIDs, c.DocumentID)
+	}
+	if len(relevant) == 0 {
+		return
+	}
+	res.RecallAtK = ptr(RecallAtK(res.RetrievedDocumentIDs, relevant, cfg.K))
+	res.ReciprocalRank = ptr(ReciprocalRank(res.RetrievedDocumentIDs, relevant))
+	res.NDCGAtK = ptr(NDCGAtK(res.RetrievedDocumentIDs, relevant, cfg.K))
+}
+
+func (r *Runner) evaluateAnswer(ctx context.Context, notebookID int64, q *Question, cfg Config, res *QuestionResult) {
+	start := time.Now()
+	resp, err := r.chat.Chat(ctx, notebookID, service.ChatRequest{
+		Query:     q.Question,
+		Mode:      cfg.ChatMode,
+		Expansion: cfg.Expansion,
+	})
+	res.ChatMillis = time.Since(start).Milliseconds()
+	if err != nil {
+		res.Errors = append(res.Errors, fmt.Sprintf("chat: %v", err))
+		return
+	}
+	res.Answer = resp.Answer
+	if q.ExpectedAnswer == "" {
+		return
+	}
+
+	if r.embeddingClient != nil {
+		vectors, err := r.embeddingClient.EmbedBatch(ctx, []string{resp.Answer, q.ExpectedAnswer})
+		if err != nil || len(vectors) != 2 {
+			res.Errors = append(res.Errors, fmt.Sprintf("answer similarity: %v", err))
+		} else {
+			res.AnswerSimilarity = ptr(CosineSimilarity(vectors[0], vectors[1]))
+		}
+	} else {
+		res.AnswerSimilarity = ptr(TokenF1(resp.Answer, q.ExpectedAnswer))
+	}
+
+	if r.judge != nil {
+		score, reason, err := JudgeAnswer(ctx, r.judge, q.Question, q.ExpectedAnswer, resp.Answer)
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("judge: %v", err))
+		} else {
+			res.Correctness, res.JudgeReason = ptr(score), reason
+		}
+	}
+}
+
+// summarize averages each metric over the results that have it.
+func summarize(results []*QuestionResult) Summary {
+	s := Summary{Questions: len(results)}
+	var recall, rr, ndcg, similarity, correctness []float64
+	for _, res := range results {
+		if len(res.Errors) > 0 {
+			s.Failed++
+		}
+		recall = appendMetric(recall, res.RecallAtK)
+		rr = appendMetric(rr, res.ReciprocalRank)
+		ndcg = appendMetric(ndcg, res.NDCGAtK)
+		similarity = appendMetric(similarity, res.AnswerSimilarity)
+		correctness = appendMetric(correctness, res.Correctness)
+	}
+	s.RecallAtK, s.MRR, s.NDCGAtK = mean(recall), mean(rr), mean(ndcg)
+	s.AnswerSimilarity, s.Correctness = mean(similarity), mean(correctness)
+	return s
+}
+
+func appendMetric(values []float64, v *float64) []float64 {
+	if v == nil {
+		return values
+	}
+	return append(values, *v)
+}
+
+// mean returns the mean of the values, or nil if there are none.
+func mean(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return ptr(sum / float64(len(values)))
+}
+
+func ptr(v float64) *float64 { return &v }
@@ -0,0 +1,67 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/internal/service"
+)
+
+type notebookDocs struct {
+	repository.DocumentRepository
+}
+
+func (notebookDocs) ListAllByNotebook(ctx context.Context, notebookID int64) ([]*entity.Document, error) {
+	return []*entity.Document{{ID: 1, Filename: "a.txt"}, {ID: 2, Filename: "b.txt"}}, nil
+}
+
+// plainRetrieval finds document 1 for every question.
+type plainRetrieval struct {
+	service.RetrievalService
+}
+
+func (plainRetrieval) Retrieve(ctx context.Context, notebookID int64, q service.RetrievalQuery) ([]*service.RetrievedChunk, error) {
+	return []*service.RetrievedChunk{{Chunk: &entity.Chunk{DocumentID: 1}}}, nil
+}
+
+// expandingChat finds document 2 when the question is expanded.
+type expandingChat struct {
+	service.ChatService
+	expansions []string
+}
+
+func (c *expandingChat) Retrieve(ctx context.Context, notebookID int64, req service.ChatRequest, q service.RetrievalQuery) ([]*service.RetrievedChunk, []string, error) {
+	c.expansions = append(c.expansions, req.Expansion)
+	return []*service.RetrievedChunk{{Chunk: &entity.Chunk{DocumentID: 2}}}, []string{"passage"}, nil
+}
+
+func TestRunRetrievalWithExpansion(t *testing.T) {
+	chat := &expandingChat{}
+	runner := NewRunner(plainRetrieval{}, chat, notebookDocs{}, nil, nil)
+	ds := &Dataset{Questions: []*Question{{ID: "q1", Question: "q", RelevantDocuments: []string{"b.txt"}}}}
+
+	report, err := runner.Run(context.Background(), 1, ds, Config{K: 1, SkipChat: true})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := *report.Results[0].RecallAtK; got != 0 {
+		t.Errorf("recall without expansion = %v, want 0", got)
+	}
+	if len(chat.expansions) != 0 {
+		t.Errorf("retrieval without expansion went through chat: %v", chat.expansions)
+	}
+
+	report, err = runner.Run(context.Background(), 1, ds, Config{K: 1, Expansion: service.ExpansionHyDE, SkipChat: true})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	res := report.Results[0]
+	if *res.RecallAtK != 1 || len(res.ExpandedQueries) != 1 {
+		t.Errorf("recall with expansion = %v, expanded queries %v; want 1 and the passage", *res.RecallAtK, res.ExpandedQueries)
+	}
+	if len(chat.expansions) != 1 || chat.expansions[0] != service.ExpansionHyDE {
+		t.Errorf("chat retrieval expansions = %v, want [hyde]", chat.expansions)
+	}
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// CSV column headers. The import side reads the same layout.
+var (
+	NodeCSVHeader = []string{"id", "label", "name", "document_id", "pagerank", "degree_centrality", "betweenness", "community_id", "properties"}
+	EdgeCSVHeader = []string{"id", "source", "target", "relation_type", "document_id", "properties"}
+)
+
+// writeCSV writes a zip archive holding nodes.csv and edges.csv.
+func writeCSV(w io.Writer, g *Graph) error {
+	zw := zip.NewWriter(w)
+
+	f, err := zw.Create("nodes.csv")
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+	cw.Write(NodeCSVHeader)
+	for _, n := range g.Nodes {
+		community := ""
+		if n.CommunityID != nil {
+			community = strconv.FormatInt(*n.CommunityID, 10)
+		}
+		cw.Write([]string{
+			strconv.FormatInt(n.ID, 10), n.Label, n.Name, strconv.FormatInt(n.DocumentID, 10),
+			formatFloat(n.PageRank), formatFloat(n.DegreeCentrality), formatFloat(n.Betweenness),
+			community, n.Properties.String(),
+		})
+	}
+	if cw.Flush(); cw.Error() != nil {
+		return cw.Error()
+	}
+
+	f, err = zw.Create("edges.csv")
+	if err != nil {
+		return err
+	}
+	cw = csv.NewWriter(f)
+	cw.Write(EdgeCSVHeader)
+	for _, e := range g.Edges {
+		cw.Write([]string{
+			strconv.FormatInt(e.ID, 10), strconv.FormatInt(e.SourceNodeID, 10), strconv.FormatInt(e.TargetNodeID, 10),
+			e.RelationType, strconv.FormatInt(e.DocumentID, 10), e.Properties.String(),
+		})
+	}
+	if cw.Flush(); cw.Error() != nil {
+		return cw.Error()
+	}
+
+	return zw.Close()
+}
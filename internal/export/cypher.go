@@ -0,0 +1,113 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+var cypherIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// writeCypher writes a script of CREATE statements. Nodes get the :Entity label plus their
+// own label, edges use their relation type; properties are flattened onto both.
+func writeCypher(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "// GraphWeaver export: %s\n", g.Name)
+	bw.WriteString("CREATE CONSTRAINT entity_id IF NOT EXISTS FOR (n:Entity) REQUIRE n.id IS UNIQUE;\n\n")
+
+	for _, n := range g.Nodes {
+		fields := []cypherField{
+			{"id", strconv.FormatInt(n.ID, 10)},
+			{"document_id", strconv.FormatInt(n.DocumentID, 10)},
+			{"name", cypherString(n.Name)},
+			{"label", cypherString(n.Label)},
+			{"pagerank", formatFloat(n.PageRank)},
+			{"degree_centrality", formatFloat(n.DegreeCentrality)},
+			{"betweenness", formatFloat(n.Betweenness)},
+		}
+		if n.CommunityID != nil {
+			fields = append(fields, cypherField{"community_id", strconv.FormatInt(*n.CommunityID, 10)})
+		}
+		fields = appendCypherProperties(fields, n.Properties)
+
+		label := ""
+		if n.Label != "" {
+			label = ":" + cypherName(n.Label)
+		}
+		fmt.Fprintf(bw, "CREATE (:Entity%s %s);\n", label, cypherMap(fields))
+	}
+	bw.WriteString("\n")
+
+	for _, e := range g.Edges {
+		fields := []cypherField{
+			{"id", strconv.FormatInt(e.ID, 10)},
+			{"document_id", strconv.FormatInt(e.DocumentID, 10)},
+		}
+		fields = appendCypherProperties(fields, e.Properties)
+
+		relType := e.RelationType
+		if relType == "" {
+			relType = "RELATED_TO"
+		}
+		fmt.Fprintf(bw, "MATCH (a:Entity {id: %d}), (b:Entity {id: %d}) CREATE (a)-[:%s %s]->(b);\n",
+			e.SourceNodeID, e.TargetNodeID, cypherName(relType), cypherMap(fields))
+	}
+
+	return bw.Flush()
+}
+
+type cypherField struct {
+	key   string
+	value string // a Cypher literal
+}
+
+// appendCypherProperties adds the entries of a properties object that do not clash with
+// the built-in fields.
+func appendCypherProperties(fields []cypherField, props entity.Properties) []cypherField {
+	taken := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		taken[f.key] = true
+	}
+	for _, p := range properties(props) {
+		if taken[p.Key] {
+			continue
+		}
+		var value string
+		switch v := p.Value.(type) {
+		case float64, bool:
+			value = propertyString(v)
+		case nil:
+			continue // Neo4j does not store null properties
+		default:
+			value = cypherString(propertyString(v))
+		}
+		fields = append(fields, cypherField{p.Key, value})
+	}
+	return fields
+}
+
+func cypherMap(fields []cypherField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = cypherName(f.key) + ": " + f.value
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// cypherName quotes a label, relationship type or key with backticks unless it is a plain identifier.
+func cypherName(s string) string {
+	if cypherIdentifier.MatchString(s) {
+		return s
+	}
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
+
+func cypherString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return "'" + r.Replace(s) + "'"
+}
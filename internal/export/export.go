@@ -0,0 +1,163 @@
+// Package export writes knowledge graphs in formats understood by external graph tools.
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+// Supported formats.
+const (
+	FormatGraphML = "graphml"
+	FormatGEXF    = "gexf"
+	FormatCypher  = "cypher"
+	FormatJSONLD  = "jsonld"
+	FormatTurtle  = "turtle"
+	FormatCSV     = "csv" // zip archive holding nodes.csv and edges.csv
+)
+
+// ErrUnsupportedFormat is returned for an unknown export format.
+var ErrUnsupportedFormat = errors.New("export: unsupported format")
+
+// Graph is the data to export. Edges with an endpoint outside Nodes are dropped, since most
+// formats cannot reference nodes that are not part of the file.
+type Graph struct {
+	Name  string
+	Nodes []*entity.Node
+	Edges []*entity.Edge
+}
+
+type format struct {
+	contentType string
+	extension   string
+	write       func(w io.Writer, g *Graph) error
+}
+
+var formats = map[string]format{
+	FormatGraphML: {"application/graphml+xml", "graphml", writeGraphML},
+	FormatGEXF:    {"application/gexf+xml", "gexf", writeGEXF},
+	FormatCypher:  {"application/x-cypher-query; charset=utf-8", "cypher", writeCypher},
+	FormatJSONLD:  {"application/ld+json", "jsonld", writeJSONLD},
+	FormatTurtle:  {"text/turtle; charset=utf-8", "ttl", writeTurtle},
+	FormatCSV:     {"application/zip", "zip", writeCSV},
+}
+
+// Supported reports whether name is a known export format.
+func Supported(name string) bool {
+	_, ok := formats[name]
+	return ok
+}
+
+// ContentType returns the MIME type of a format.
+func ContentType(name string) string {
+	return formats[name].contentType
+}
+
+// Extension returns the file extension of a format, without the dot.
+func Extension(name string) string {
+	return formats[name].extension
+}
+
+// Write writes g to w in the named format.
+func Write(w io.Writer, name string, g *Graph) error {
+	f, ok := formats[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, name)
+	}
+	return f.write(w, &Graph{Name: g.Name, Nodes: g.Nodes, Edges: connectedEdges(g)})
+}
+
+func connectedEdges(g *Graph) []*entity.Edge {
+	known := make(map[int64]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		known[n.ID] = true
+	}
+	edges := make([]*entity.Edge, 0, len(g.Edges))
+	for _, e := range g.Edges {
+		if known[e.SourceNodeID] && known[e.TargetNodeID] {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// property is a top-level entry of a node or edge properties object.
+type property struct {
+	Key   string
+	Value any
+}
+
+// properties returns the entries of a properties object, sorted by key.
+func properties(m entity.Properties) []property {
+	props := make([]property, 0, len(m))
+	for k, v := range m {
+		props = append(props, property{Key: k, Value: v})
+	}
+	sort.Slice(props, func(i, j int) bool { return props[i].Key < props[j].Key })
+	return props
+}
+
+// propertyString renders a property value as text: strings as is, anything else as JSON.
+func propertyString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// propertyKeys collects the distinct property keys used by a set of property strings, sorted.
+func propertyKeys(all []entity.Properties) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range all {
+		for _, p := range properties(m) {
+			if !seen[p.Key] {
+				seen[p.Key] = true
+				keys = append(keys, p.Key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func nodeProperties(nodes []*entity.Node) []entity.Properties {
+	all := make([]entity.Properties, len(nodes))
+	for i, n := range nodes {
+		all[i] = n.Properties
+	}
+	return all
+}
+
+func edgeProperties(edges []*entity.Edge) []entity.Properties {
+	all := make([]entity.Properties, len(edges))
+	for i, e := range edges {
+		all[i] = e.Properties
+	}
+	return all
+}
+
+func nodeID(id int64) string {
+	return "n" + strconv.FormatInt(id, 10)
+}
+
+func edgeID(id int64) string {
+	return "e" + strconv.FormatInt(id, 10)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
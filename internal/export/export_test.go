@@ -0,0 +1,165 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+func testGraph() *Graph {
+	community := int64(3)
+	return &Graph{
+		Name: "test",
+		Nodes: []*entity.Node{
+			{ID: 1, DocumentID: 10, Label: "Person", Name: `Ada "the Countess"`, Properties: entity.Properties{"description": "Mathematician", "born": 1815.0}, CommunityID: &community},
+			{ID: 2, DocumentID: 10, Label: "Machine Type", Name: "Analytical Engine", Properties: entity.Properties{}},
+		},
+		Edges: []*entity.Edge{
+			{ID: 5, DocumentID: 10, SourceNodeID: 1, TargetNodeID: 2, RelationType: "WROTE_ABOUT", Properties: entity.Properties{"description": "Notes, 1843"}},
+			// Points at a node of another document that is not exported
+			{ID: 6, DocumentID: 10, SourceNodeID: 1, TargetNodeID: 99, RelationType: "KNOWS"},
+		},
+	}
+}
+
+func write(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, format, testGraph()); err != nil {
+		t.Fatalf("Write(%s) failed: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+func TestWrite_UnsupportedFormat(t *testing.T) {
+	err := Write(&bytes.Buffer{}, "dot", testGraph())
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestWriteGraphML(t *testing.T) {
+	var doc graphML
+	if err := xml.Unmarshal(write(t, FormatGraphML), &doc); err != nil {
+		t.Fatalf("invalid GraphML: %v", err)
+	}
+	if len(doc.Graph.Nodes) != 2 || len(doc.Graph.Edges) != 1 {
+		t.Fatalf("expected 2 nodes and 1 edge, got %d and %d", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+	if e := doc.Graph.Edges[0]; e.Source != "n1" || e.Target != "n2" {
+		t.Errorf("unexpected edge endpoints %s -> %s", e.Source, e.Target)
+	}
+
+	keys := make(map[string]string)
+	for _, k := range doc.Keys {
+		keys[k.ID] = k.Name
+	}
+	found := false
+	for _, d := range doc.Graph.Nodes[0].Data {
+		if keys[d.Key] == "description" && d.Value == "Mathematician" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the description property as a data element, got %+v", doc.Graph.Nodes[0].Data)
+	}
+}
+
+func TestWriteGEXF(t *testing.T) {
+	var doc gexf
+	if err := xml.Unmarshal(write(t, FormatGEXF), &doc); err != nil {
+		t.Fatalf("invalid GEXF: %v", err)
+	}
+	if len(doc.Graph.Nodes) != 2 || len(doc.Graph.Edges) != 1 {
+		t.Fatalf("expected 2 nodes and 1 edge, got %d and %d", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+	if doc.Graph.Edges[0].Label != "WROTE_ABOUT" {
+		t.Errorf("expected edge label WROTE_ABOUT, got %q", doc.Graph.Edges[0].Label)
+	}
+}
+
+func TestWriteCypher(t *testing.T) {
+	out := string(write(t, FormatCypher))
+	for _, want := range []string{
+		`CREATE (:Entity:Person {id: 1, document_id: 10, name: 'Ada "the Countess"'`,
+		"CREATE (:Entity:`Machine Type` {id: 2",
+		"born: 1815",
+		"MATCH (a:Entity {id: 1}), (b:Entity {id: 2}) CREATE (a)-[:WROTE_ABOUT {id: 5",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected script to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "KNOWS") {
+		t.Errorf("expected the edge to an unexported node to be dropped")
+	}
+}
+
+func TestWriteTurtle(t *testing.T) {
+	out := string(write(t, FormatTurtle))
+	for _, want := range []string{
+		`<urn:graphweaver:node:1> a <urn:graphweaver:type:Person> ;`,
+		`rdfs:label "Ada \"the Countess\""`,
+		`<urn:graphweaver:type:Machine%20Type>`,
+		`<urn:graphweaver:node:1> <urn:graphweaver:relation:WROTE_ABOUT> <urn:graphweaver:node:2> .`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected Turtle to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteJSONLD(t *testing.T) {
+	var doc struct {
+		Context map[string]string `json:"@context"`
+		Graph   []map[string]any  `json:"@graph"`
+	}
+	if err := json.Unmarshal(write(t, FormatJSONLD), &doc); err != nil {
+		t.Fatalf("invalid JSON-LD: %v", err)
+	}
+	if doc.Context["gw"] != NSVocab {
+		t.Errorf("expected the gw prefix in the context, got %v", doc.Context)
+	}
+	// Two nodes and one reified edge
+	if len(doc.Graph) != 3 {
+		t.Fatalf("expected 3 graph objects, got %d", len(doc.Graph))
+	}
+	if _, ok := doc.Graph[0][relationIRI("WROTE_ABOUT")]; !ok {
+		t.Errorf("expected the relation on the source node, got %v", doc.Graph[0])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	data := write(t, FormatCSV)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+
+	rows := make(map[string][][]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		rows[f.Name], err = csv.NewReader(rc).ReadAll()
+		rc.Close()
+		if err != nil {
+			t.Fatalf("invalid CSV in %s: %v", f.Name, err)
+		}
+	}
+
+	if len(rows["nodes.csv"]) != 3 || len(rows["edges.csv"]) != 2 {
+		t.Fatalf("expected header plus 2 nodes and header plus 1 edge, got %d and %d rows", len(rows["nodes.csv"]), len(rows["edges.csv"]))
+	}
+	if got := rows["nodes.csv"][1][2]; got != `Ada "the Countess"` {
+		t.Errorf("expected the name to round-trip, got %q", got)
+	}
+}
@@ -0,0 +1,163 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+// RDF vocabulary. Nodes become resources typed by their label; each edge becomes a direct
+// triple plus a reified rdf:Statement carrying the edge's own fields and properties.
+const (
+	NSNode     = "urn:graphweaver:node:"
+	NSEdge     = "urn:graphweaver:edge:"
+	NSType     = "urn:graphweaver:type:"
+	NSRelation = "urn:graphweaver:relation:"
+	NSProperty = "urn:graphweaver:property:"
+	NSVocab    = "urn:graphweaver:vocab:"
+
+	nsRDF  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsRDFS = "http://www.w3.org/2000/01/rdf-schema#"
+	nsXSD  = "http://www.w3.org/2001/XMLSchema#"
+)
+
+func nodeIRI(id int64) string           { return NSNode + strconv.FormatInt(id, 10) }
+func edgeIRI(id int64) string           { return NSEdge + strconv.FormatInt(id, 10) }
+func typeIRI(label string) string       { return NSType + url.PathEscape(label) }
+func relationIRI(relType string) string { return NSRelation + url.PathEscape(relType) }
+func propertyIRI(key string) string     { return NSProperty + url.PathEscape(key) }
+
+func writeTurtle(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# GraphWeaver export: %s\n", g.Name)
+	fmt.Fprintf(bw, "@prefix rdf: <%s> .\n", nsRDF)
+	fmt.Fprintf(bw, "@prefix rdfs: <%s> .\n", nsRDFS)
+	fmt.Fprintf(bw, "@prefix xsd: <%s> .\n", nsXSD)
+	fmt.Fprintf(bw, "@prefix gw: <%s> .\n\n", NSVocab)
+
+	for _, n := range g.Nodes {
+		fmt.Fprintf(bw, "<%s> a <%s> ;\n", nodeIRI(n.ID), typeIRI(n.Label))
+		fmt.Fprintf(bw, "    rdfs:label %s ;\n", turtleString(n.Name))
+		fmt.Fprintf(bw, "    gw:documentId %d ;\n", n.DocumentID)
+		fmt.Fprintf(bw, "    gw:pagerank %s ;\n", turtleDouble(n.PageRank))
+		fmt.Fprintf(bw, "    gw:degreeCentrality %s ;\n", turtleDouble(n.DegreeCentrality))
+		fmt.Fprintf(bw, "    gw:betweenness %s", turtleDouble(n.Betweenness))
+		if n.CommunityID != nil {
+			fmt.Fprintf(bw, " ;\n    gw:communityId %d", *n.CommunityID)
+		}
+		writeTurtleProperties(bw, n.Properties)
+		bw.WriteString(" .\n\n")
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "<%s> <%s> <%s> .\n", nodeIRI(e.SourceNodeID), relationIRI(e.RelationType), nodeIRI(e.TargetNodeID))
+		fmt.Fprintf(bw, "<%s> a rdf:Statement ;\n", edgeIRI(e.ID))
+		fmt.Fprintf(bw, "    rdf:subject <%s> ;\n", nodeIRI(e.SourceNodeID))
+		fmt.Fprintf(bw, "    rdf:predicate <%s> ;\n", relationIRI(e.RelationType))
+		fmt.Fprintf(bw, "    rdf:object <%s> ;\n", nodeIRI(e.TargetNodeID))
+		fmt.Fprintf(bw, "    gw:relationType %s ;\n", turtleString(e.RelationType))
+		fmt.Fprintf(bw, "    gw:documentId %d", e.DocumentID)
+		writeTurtleProperties(bw, e.Properties)
+		bw.WriteString(" .\n\n")
+	}
+
+	return bw.Flush()
+}
+
+func writeTurtleProperties(bw *bufio.Writer, props entity.Properties) {
+	for _, p := range properties(props) {
+		var literal string
+		switch v := p.Value.(type) {
+		case nil:
+			continue
+		case float64:
+			literal = turtleDouble(v)
+		case bool:
+			literal = strconv.FormatBool(v)
+		default:
+			literal = turtleString(propertyString(v))
+		}
+		fmt.Fprintf(bw, " ;\n    <%s> %s", propertyIRI(p.Key), literal)
+	}
+}
+
+func turtleString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func turtleDouble(f float64) string {
+	return `"` + formatFloat(f) + `"^^xsd:double`
+}
+
+func writeJSONLD(w io.Writer, g *Graph) error {
+	graph := make([]map[string]any, 0, len(g.Nodes)+len(g.Edges))
+	byID := make(map[int64]map[string]any, len(g.Nodes))
+
+	for _, n := range g.Nodes {
+		obj := map[string]any{
+			"@id":                 nodeIRI(n.ID),
+			"@type":               typeIRI(n.Label),
+			"rdfs:label":          n.Name,
+			"gw:documentId":       n.DocumentID,
+			"gw:pagerank":         n.PageRank,
+			"gw:degreeCentrality": n.DegreeCentrality,
+			"gw:betweenness":      n.Betweenness,
+		}
+		if n.CommunityID != nil {
+			obj["gw:communityId"] = *n.CommunityID
+		}
+		addJSONLDProperties(obj, n.Properties)
+		byID[n.ID] = obj
+		graph = append(graph, obj)
+	}
+
+	for _, e := range g.Edges {
+		// The direct relation, as a property of the source node
+		source := byID[e.SourceNodeID]
+		rel := relationIRI(e.RelationType)
+		targets, _ := source[rel].([]map[string]string)
+		source[rel] = append(targets, map[string]string{"@id": nodeIRI(e.TargetNodeID)})
+
+		stmt := map[string]any{
+			"@id":             edgeIRI(e.ID),
+			"@type":           "rdf:Statement",
+			"rdf:subject":     map[string]string{"@id": nodeIRI(e.SourceNodeID)},
+			"rdf:predicate":   map[string]string{"@id": rel},
+			"rdf:object":      map[string]string{"@id": nodeIRI(e.TargetNodeID)},
+			"gw:relationType": e.RelationType,
+			"gw:documentId":   e.DocumentID,
+		}
+		addJSONLDProperties(stmt, e.Properties)
+		graph = append(graph, stmt)
+	}
+
+	doc := map[string]any{
+		"@context": map[string]any{
+			"rdf":  nsRDF,
+			"rdfs": nsRDFS,
+			"xsd":  nsXSD,
+			"gw":   NSVocab,
+		},
+		"@graph": graph,
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func addJSONLDProperties(obj map[string]any, props entity.Properties) {
+	for _, p := range properties(props) {
+		if p.Value == nil {
+			continue
+		}
+		obj[propertyIRI(p.Key)] = p.Value
+	}
+}
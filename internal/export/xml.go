@@ -0,0 +1,235 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// GraphML, see http://graphml.graphdrawing.org/specification.html
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func writeGraphML(w io.Writer, g *Graph) error {
+	nodeKeys := propertyKeys(nodeProperties(g.Nodes))
+	edgeKeys := propertyKeys(edgeProperties(g.Edges))
+
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "name", For: "node", Name: "name", Type: "string"},
+			{ID: "document_id", For: "node", Name: "document_id", Type: "long"},
+			{ID: "pagerank", For: "node", Name: "pagerank", Type: "double"},
+			{ID: "degree_centrality", For: "node", Name: "degree_centrality", Type: "double"},
+			{ID: "betweenness", For: "node", Name: "betweenness", Type: "double"},
+			{ID: "community_id", For: "node", Name: "community_id", Type: "long"},
+			{ID: "relation_type", For: "edge", Name: "relation_type", Type: "string"},
+			{ID: "edge_document_id", For: "edge", Name: "document_id", Type: "long"},
+		},
+		Graph: graphMLGraph{ID: g.Name, EdgeDefault: "directed"},
+	}
+	nodeKeyIDs := make(map[string]string, len(nodeKeys))
+	for i, k := range nodeKeys {
+		nodeKeyIDs[k] = fmt.Sprintf("np%d", i)
+		doc.Keys = append(doc.Keys, graphMLKey{ID: nodeKeyIDs[k], For: "node", Name: k, Type: "string"})
+	}
+	edgeKeyIDs := make(map[string]string, len(edgeKeys))
+	for i, k := range edgeKeys {
+		edgeKeyIDs[k] = fmt.Sprintf("ep%d", i)
+		doc.Keys = append(doc.Keys, graphMLKey{ID: edgeKeyIDs[k], For: "edge", Name: k, Type: "string"})
+	}
+
+	for _, n := range g.Nodes {
+		node := graphMLNode{ID: nodeID(n.ID), Data: []graphMLData{
+			{Key: "label", Value: n.Label},
+			{Key: "name", Value: n.Name},
+			{Key: "document_id", Value: strconv.FormatInt(n.DocumentID, 10)},
+			{Key: "pagerank", Value: formatFloat(n.PageRank)},
+			{Key: "degree_centrality", Value: formatFloat(n.DegreeCentrality)},
+			{Key: "betweenness", Value: formatFloat(n.Betweenness)},
+		}}
+		if n.CommunityID != nil {
+			node.Data = append(node.Data, graphMLData{Key: "community_id", Value: strconv.FormatInt(*n.CommunityID, 10)})
+		}
+		for _, p := range properties(n.Properties) {
+			node.Data = append(node.Data, graphMLData{Key: nodeKeyIDs[p.Key], Value: propertyString(p.Value)})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for _, e := range g.Edges {
+		edge := graphMLEdge{ID: edgeID(e.ID), Source: nodeID(e.SourceNodeID), Target: nodeID(e.TargetNodeID), Data: []graphMLData{
+			{Key: "relation_type", Value: e.RelationType},
+			{Key: "edge_document_id", Value: strconv.FormatInt(e.DocumentID, 10)},
+		}}
+		for _, p := range properties(e.Properties) {
+			edge.Data = append(edge.Data, graphMLData{Key: edgeKeyIDs[p.Key], Value: propertyString(p.Value)})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	return writeXML(w, doc)
+}
+
+// GEXF 1.3, see https://gexf.net
+
+type gexf struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Mode            string           `xml:"mode,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue,omitempty"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue,omitempty"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+func writeGEXF(w io.Writer, g *Graph) error {
+	nodeKeys := propertyKeys(nodeProperties(g.Nodes))
+	edgeKeys := propertyKeys(edgeProperties(g.Edges))
+
+	nodeAttrs := gexfAttributes{Class: "node", Attributes: []gexfAttribute{
+		{ID: "type", Title: "type", Type: "string"},
+		{ID: "document_id", Title: "document_id", Type: "long"},
+		{ID: "pagerank", Title: "pagerank", Type: "double"},
+		{ID: "degree_centrality", Title: "degree_centrality", Type: "double"},
+		{ID: "betweenness", Title: "betweenness", Type: "double"},
+		{ID: "community_id", Title: "community_id", Type: "long"},
+	}}
+	nodeAttrIDs := make(map[string]string, len(nodeKeys))
+	for i, k := range nodeKeys {
+		nodeAttrIDs[k] = fmt.Sprintf("np%d", i)
+		nodeAttrs.Attributes = append(nodeAttrs.Attributes, gexfAttribute{ID: nodeAttrIDs[k], Title: k, Type: "string"})
+	}
+	edgeAttrs := gexfAttributes{Class: "edge", Attributes: []gexfAttribute{
+		{ID: "document_id", Title: "document_id", Type: "long"},
+	}}
+	edgeAttrIDs := make(map[string]string, len(edgeKeys))
+	for i, k := range edgeKeys {
+		edgeAttrIDs[k] = fmt.Sprintf("ep%d", i)
+		edgeAttrs.Attributes = append(edgeAttrs.Attributes, gexfAttribute{ID: edgeAttrIDs[k], Title: k, Type: "string"})
+	}
+
+	doc := gexf{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Graph: gexfGraph{
+			DefaultEdgeType: "directed",
+			Mode:            "static",
+			Attributes:      []gexfAttributes{nodeAttrs, edgeAttrs},
+		},
+	}
+
+	for _, n := range g.Nodes {
+		node := gexfNode{ID: nodeID(n.ID), Label: n.Name, AttValues: []gexfAttValue{
+			{For: "type", Value: n.Label},
+			{For: "document_id", Value: strconv.FormatInt(n.DocumentID, 10)},
+			{For: "pagerank", Value: formatFloat(n.PageRank)},
+			{For: "degree_centrality", Value: formatFloat(n.DegreeCentrality)},
+			{For: "betweenness", Value: formatFloat(n.Betweenness)},
+		}}
+		if n.CommunityID != nil {
+			node.AttValues = append(node.AttValues, gexfAttValue{For: "community_id", Value: strconv.FormatInt(*n.CommunityID, 10)})
+		}
+		for _, p := range properties(n.Properties) {
+			node.AttValues = append(node.AttValues, gexfAttValue{For: nodeAttrIDs[p.Key], Value: propertyString(p.Value)})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for _, e := range g.Edges {
+		edge := gexfEdge{ID: edgeID(e.ID), Source: nodeID(e.SourceNodeID), Target: nodeID(e.TargetNodeID), Label: e.RelationType,
+			AttValues: []gexfAttValue{{For: "document_id", Value: strconv.FormatInt(e.DocumentID, 10)}}}
+		for _, p := range properties(e.Properties) {
+			edge.AttValues = append(edge.AttValues, gexfAttValue{For: edgeAttrIDs[p.Key], Value: propertyString(p.Value)})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
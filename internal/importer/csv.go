@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Columns written by the exporter that describe where a graph came from rather than the
+// entities themselves; they are not imported as properties.
+var exportOnlyColumns = map[string]bool{
+	"document_id":       true,
+	"pagerank":          true,
+	"degree_centrality": true,
+	"betweenness":       true,
+	"community_id":      true,
+}
+
+// ParseCSV reads node and edge CSV files. Nodes need an id and a name column and may have a
+// label; edges need source and target columns and a relation_type (or type) column. A
+// properties column holding a JSON object is merged into the properties, and any other
+// column becomes a property. Edges referencing undeclared nodes are kept; the caller decides
+// how to resolve them. edges may be nil.
+func ParseCSV(nodes, edges io.Reader) (*Graph, error) {
+	b := newBuilder()
+
+	rows, err := readCSV(nodes)
+	if err != nil {
+		return nil, fmt.Errorf("nodes: %w", err)
+	}
+	if len(rows) > 0 {
+		cols := columnIndex(rows[0])
+		idCol, ok := cols["id"]
+		if !ok {
+			return nil, fmt.Errorf("nodes: missing id column")
+		}
+		nameCol, ok := cols["name"]
+		if !ok {
+			return nil, fmt.Errorf("nodes: missing name column")
+		}
+		for i, row := range rows[1:] {
+			key := strings.TrimSpace(row[idCol])
+			if key == "" {
+				return nil, fmt.Errorf("nodes: row %d: empty id", i+2)
+			}
+			n := b.node(key)
+			n.Name = strings.TrimSpace(row[nameCol])
+			if c, ok := cols["label"]; ok {
+				n.Label = strings.TrimSpace(row[c])
+			}
+			if err := rowProperties(n.Properties, rows[0], row, "id", "name", "label"); err != nil {
+				return nil, fmt.Errorf("nodes: row %d: %w", i+2, err)
+			}
+		}
+	}
+
+	if edges == nil {
+		return b.graph, nil
+	}
+	rows, err = readCSV(edges)
+	if err != nil {
+		return nil, fmt.Errorf("edges: %w", err)
+	}
+	if len(rows) == 0 {
+		return b.graph, nil
+	}
+	cols := columnIndex(rows[0])
+	sourceCol, ok1 := cols["source"]
+	targetCol, ok2 := cols["target"]
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("edges: missing source or target column")
+	}
+	typeCol, ok := cols["relation_type"]
+	if !ok {
+		if typeCol, ok = cols["type"]; !ok {
+			return nil, fmt.Errorf("edges: missing relation_type column")
+		}
+	}
+	for i, row := range rows[1:] {
+		e := &Edge{
+			Source:       strings.TrimSpace(row[sourceCol]),
+			Target:       strings.TrimSpace(row[targetCol]),
+			RelationType: strings.TrimSpace(row[typeCol]),
+			Properties:   make(map[string]any),
+		}
+		if e.Source == "" || e.Target == "" {
+			return nil, fmt.Errorf("edges: row %d: empty source or target", i+2)
+		}
+		if err := rowProperties(e.Properties, rows[0], row, "id", "source", "target", "relation_type", "type"); err != nil {
+			return nil, fmt.Errorf("edges: row %d: %w", i+2, err)
+		}
+		b.graph.Edges = append(b.graph.Edges, e)
+	}
+	return b.graph, nil
+}
+
+// ParseCSVZip reads nodes.csv and, if present, edges.csv from a zip archive such as the
+// CSV export. Files are matched by base name, so they may sit in a folder.
+func ParseCSVZip(r io.ReaderAt, size int64) (*Graph, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+	var nodesFile, edgesFile *zip.File
+	for _, f := range zr.File {
+		switch strings.ToLower(path.Base(f.Name)) {
+		case "nodes.csv":
+			nodesFile = f
+		case "edges.csv":
+			edgesFile = f
+		}
+	}
+	if nodesFile == nil {
+		return nil, fmt.Errorf("zip archive has no nodes.csv")
+	}
+
+	nodes, err := nodesFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer nodes.Close()
+	var edges io.Reader
+	if edgesFile != nil {
+		rc, err := edgesFile.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		edges = rc
+	}
+	return ParseCSV(nodes, edges)
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		// Spreadsheet exports often start with a byte order mark
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\uFEFF")
+	}
+	return rows, nil
+}
+
+func columnIndex(header []string) map[string]int {
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	return cols
+}
+
+// rowProperties adds the properties of a CSV row, skipping the reserved columns.
+func rowProperties(props map[string]any, header, row []string, reserved ...string) error {
+	skip := make(map[string]bool, len(reserved))
+	for _, r := range reserved {
+		skip[r] = true
+	}
+	for i, h := range header {
+		col := strings.ToLower(strings.TrimSpace(h))
+		value := strings.TrimSpace(row[i])
+		switch {
+		case skip[col] || exportOnlyColumns[col] || value == "":
+		case col == "properties":
+			var m map[string]any
+			if err := json.Unmarshal([]byte(value), &m); err != nil {
+				return fmt.Errorf("invalid properties JSON: %w", err)
+			}
+			for k, v := range m {
+				props[k] = v
+			}
+		default:
+			props[strings.TrimSpace(h)] = value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Package importer parses graphs from external sources into nodes and edges keyed by the
+// identifiers used in the source.
+package importer
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// Supported formats.
+const (
+	FormatCSV    = "csv"    // nodes.csv and edges.csv, separately or in a zip archive
+	FormatJSON   = "json"   // GraphData JSON, as returned by the graph endpoints
+	FormatTurtle = "turtle" // RDF Turtle
+)
+
+// ErrUnsupportedFormat is returned for an unknown import format.
+var ErrUnsupportedFormat = errors.New("importer: unsupported format")
+
+// Node is an imported entity. Key identifies it within the source.
+type Node struct {
+	Key        string
+	Label      string
+	Name       string
+	Properties map[string]any
+}
+
+// Edge is an imported relation between two node keys.
+type Edge struct {
+	Source       string
+	Target       string
+	RelationType string
+	Properties   map[string]any
+}
+
+// Graph is the result of parsing a source.
+type Graph struct {
+	Nodes []*Node
+	Edges []*Edge
+}
+
+// DetectFormat guesses the format from a file name, returning "" if unknown.
+func DetectFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".zip":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	case ".ttl", ".turtle":
+		return FormatTurtle
+	default:
+		return ""
+	}
+}
+
+// builder collects nodes in first-seen order and merges repeated keys.
+type builder struct {
+	graph *Graph
+	nodes map[string]*Node
+}
+
+func newBuilder() *builder {
+	return &builder{graph: &Graph{}, nodes: make(map[string]*Node)}
+}
+
+func (b *builder) node(key string) *Node {
+	if n, ok := b.nodes[key]; ok {
+		return n
+	}
+	n := &Node{Key: key, Properties: make(map[string]any)}
+	b.nodes[key] = n
+	b.graph.Nodes = append(b.graph.Nodes, n)
+	return n
+}
+
+func (b *builder) has(key string) bool {
+	_, ok := b.nodes[key]
+	return ok
+}
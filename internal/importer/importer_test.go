@@ -0,0 +1,166 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/export"
+)
+
+func exportedGraph() *export.Graph {
+	return &export.Graph{
+		Name: "test",
+		Nodes: []*entity.Node{
+			{ID: 1, DocumentID: 10, Label: "Person", Name: `Ada "the Countess"`, Properties: entity.Properties{"description": "Mathematician", "born": 1815.0}},
+			{ID: 2, DocumentID: 10, Label: "Machine Type", Name: "Analytical Engine", Properties: entity.Properties{}},
+		},
+		Edges: []*entity.Edge{
+			{ID: 5, DocumentID: 10, SourceNodeID: 1, TargetNodeID: 2, RelationType: "WROTE_ABOUT", Properties: entity.Properties{"year": 1843.0}},
+		},
+	}
+}
+
+// checkRoundTrip verifies a graph parsed from an export of exportedGraph.
+func checkRoundTrip(t *testing.T, g *Graph) {
+	t.Helper()
+	if len(g.Nodes) != 2 || len(g.Edges) != 1 {
+		t.Fatalf("expected 2 nodes and 1 edge, got %d and %d", len(g.Nodes), len(g.Edges))
+	}
+	ada := g.Nodes[0]
+	if ada.Name != `Ada "the Countess"` || ada.Label != "Person" {
+		t.Errorf("unexpected node %q (%q)", ada.Name, ada.Label)
+	}
+	if ada.Properties["description"] != "Mathematician" {
+		t.Errorf("expected description property, got %v", ada.Properties)
+	}
+	if _, ok := ada.Properties["pagerank"]; ok {
+		t.Errorf("export-only field imported as property: %v", ada.Properties)
+	}
+	if g.Nodes[1].Label != "Machine Type" {
+		t.Errorf("expected unescaped label, got %q", g.Nodes[1].Label)
+	}
+	e := g.Edges[0]
+	if e.Source != ada.Key || e.Target != g.Nodes[1].Key || e.RelationType != "WROTE_ABOUT" {
+		t.Errorf("unexpected edge %s -[%s]-> %s", e.Source, e.RelationType, e.Target)
+	}
+	if len(e.Properties) != 1 || e.Properties["year"] == nil {
+		t.Errorf("expected year edge property, got %v", e.Properties)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []string{export.FormatCSV, FormatJSON, FormatTurtle} {
+		t.Run(format, func(t *testing.T) {
+			var g *Graph
+			var err error
+			switch format {
+			case export.FormatCSV:
+				var buf bytes.Buffer
+				if err := export.Write(&buf, export.FormatCSV, exportedGraph()); err != nil {
+					t.Fatalf("export failed: %v", err)
+				}
+				g, err = ParseCSVZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			case FormatJSON:
+				g, err = ParseJSON(strings.NewReader(`{
+					"nodes": [
+						{"id": 1, "label": "Person", "name": "Ada \"the Countess\"", "properties": "{\"description\": \"Mathematician\"}", "pagerank": 0.5},
+						{"id": 2, "label": "Machine Type", "name": "Analytical Engine", "properties": "{}"}
+					],
+					"edges": [{"id": 5, "source_node_id": 1, "target_node_id": 2, "relation_type": "WROTE_ABOUT", "properties": {"year": 1843}}]
+				}`))
+			case FormatTurtle:
+				var buf bytes.Buffer
+				if err := export.Write(&buf, export.FormatTurtle, exportedGraph()); err != nil {
+					t.Fatalf("export failed: %v", err)
+				}
+				g, err = ParseTurtle(&buf)
+			}
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			checkRoundTrip(t, g)
+		})
+	}
+}
+
+func TestParseTurtle(t *testing.T) {
+	src := `
+PREFIX ex: <http://example.org/>
+@prefix schema: <http://schema.org/> .
+@base <http://example.org/people/> .
+
+# Blank lines and comments are ignored
+<alan> a schema:Person ;
+    schema:name "Alan Turing"@en ;
+    ex:born 1912 ;
+    ex:active false ;
+    ex:knows ex:church, <grace> ;
+    ex:note """Worked at
+Bletchley""" .
+
+ex:church schema:name 'Alonzo Church' ;
+    ex:advisor [ schema:name "Oswald Veblen" ] .
+`
+	g, err := ParseTurtle(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ParseTurtle failed: %v", err)
+	}
+
+	byName := make(map[string]*Node)
+	for _, n := range g.Nodes {
+		byName[n.Name] = n
+	}
+	alan := byName["Alan Turing"]
+	if alan == nil || alan.Key != "http://example.org/people/alan" || alan.Label != "Person" {
+		t.Fatalf("unexpected node for Alan Turing: %+v", alan)
+	}
+	if alan.Properties["born"] != float64(1912) || alan.Properties["active"] != false || alan.Properties["note"] != "Worked at\nBletchley" {
+		t.Errorf("unexpected properties %v", alan.Properties)
+	}
+	// An object without statements of its own is named after its IRI
+	if byName["grace"] == nil || byName["Alonzo Church"] == nil || byName["Oswald Veblen"] == nil {
+		t.Errorf("missing nodes, got %d", len(g.Nodes))
+	}
+	if len(g.Edges) != 3 {
+		t.Fatalf("expected 3 edges, got %d", len(g.Edges))
+	}
+	for _, e := range g.Edges[:2] {
+		if e.Source != alan.Key || e.RelationType != "knows" {
+			t.Errorf("unexpected edge %s -[%s]-> %s", e.Source, e.RelationType, e.Target)
+		}
+	}
+
+	if _, err := ParseTurtle(strings.NewReader(`<a> <b> ( <c> ) .`)); err == nil {
+		t.Error("expected an error for collections")
+	}
+	if _, err := ParseTurtle(strings.NewReader(`ex:a ex:b ex:c .`)); err == nil {
+		t.Error("expected an error for an undefined prefix")
+	}
+}
+
+func TestParseCSV_MissingColumns(t *testing.T) {
+	if _, err := ParseCSV(strings.NewReader("id,label\n1,Person\n"), nil); err == nil {
+		t.Error("expected an error for a nodes file without a name column")
+	}
+	if _, err := ParseCSV(strings.NewReader("id,name\n1,Ada\n"), strings.NewReader("from,to,type\n1,2,KNOWS\n")); err == nil {
+		t.Error("expected an error for an edges file without source and target columns")
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	cases := map[string]string{
+		"graph.CSV":    FormatCSV,
+		"export.zip":   FormatCSV,
+		"graph.json":   FormatJSON,
+		"graph.ttl":    FormatTurtle,
+		"graph.gexf":   "",
+		"no-extension": "",
+	}
+	for name, want := range cases {
+		if got := DetectFormat(name); got != want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ParseJSON reads GraphData JSON: {"nodes": [...], "edges": [...]} with the fields of the
+// graph endpoints. Node IDs become the keys; properties may be a JSON object or a string
+// holding one.
+func ParseJSON(r io.Reader) (*Graph, error) {
+	var data struct {
+		Nodes []struct {
+			ID         json.Number     `json:"id"`
+			Label      string          `json:"label"`
+			Name       string          `json:"name"`
+			Properties json.RawMessage `json:"properties"`
+		} `json:"nodes"`
+		Edges []struct {
+			SourceNodeID json.Number     `json:"source_node_id"`
+			TargetNodeID json.Number     `json:"target_node_id"`
+			RelationType string          `json:"relation_type"`
+			Properties   json.RawMessage `json:"properties"`
+		} `json:"edges"`
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return nil, fmt.Errorf("invalid graph JSON: %w", err)
+	}
+
+	b := newBuilder()
+	for i, n := range data.Nodes {
+		if n.ID == "" {
+			return nil, fmt.Errorf("node %d: missing id", i)
+		}
+		node := b.node(n.ID.String())
+		node.Label = n.Label
+		node.Name = n.Name
+		if err := decodeProperties(node.Properties, n.Properties); err != nil {
+			return nil, fmt.Errorf("node %s: %w", n.ID, err)
+		}
+	}
+	for i, e := range data.Edges {
+		if e.SourceNodeID == "" || e.TargetNodeID == "" {
+			return nil, fmt.Errorf("edge %d: missing source_node_id or target_node_id", i)
+		}
+		edge := &Edge{
+			Source:       e.SourceNodeID.String(),
+			Target:       e.TargetNodeID.String(),
+			RelationType: e.RelationType,
+			Properties:   make(map[string]any),
+		}
+		if err := decodeProperties(edge.Properties, e.Properties); err != nil {
+			return nil, fmt.Errorf("edge %d: %w", i, err)
+		}
+		b.graph.Edges = append(b.graph.Edges, edge)
+	}
+	return b.graph, nil
+}
+
+func decodeProperties(props map[string]any, raw json.RawMessage) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	// The graph endpoints return properties as a string holding JSON
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return fmt.Errorf("invalid properties: %w", err)
+		}
+		if s == "" {
+			return nil
+		}
+		raw = json.RawMessage(s)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return fmt.Errorf("invalid properties: %w", err)
+	}
+	for k, v := range m {
+		props[k] = v
+	}
+	return nil
+}
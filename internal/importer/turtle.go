@@ -0,0 +1,719 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/suyw-0123/graphweaver/internal/export"
+)
+
+// This is a minimal Turtle reader: it covers prefixes, base IRIs, predicate and object
+// lists, blank nodes (labelled and [...]) and literals, but not collections.
+
+const (
+	rdfNS  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	rdfsNS = "http://www.w3.org/2000/01/rdf-schema#"
+	xsdNS  = "http://www.w3.org/2001/XMLSchema#"
+
+	rdfType      = rdfNS + "type"
+	rdfStatement = rdfNS + "Statement"
+)
+
+// namePredicates hold an entity's display name, in order of preference.
+var namePredicates = []string{
+	rdfsNS + "label",
+	"http://www.w3.org/2004/02/skos/core#prefLabel",
+	"http://schema.org/name",
+	"https://schema.org/name",
+	"http://xmlns.com/foaf/0.1/name",
+	"http://purl.org/dc/terms/title",
+	"http://purl.org/dc/elements/1.1/title",
+}
+
+// term is an RDF term: an IRI, a blank node (IRI starting with "_:") or a literal.
+type term struct {
+	iri     string
+	literal any // string, float64 or bool
+	isLit   bool
+}
+
+type triple struct {
+	s, p string
+	o    term
+}
+
+// ParseTurtle reads RDF Turtle. Subjects and IRI objects become nodes: rdf:type gives the
+// label and rdfs:label (or a similar naming predicate) the name, falling back to the IRI's
+// local name. Triples with an IRI object become edges typed by the predicate's local name,
+// other literals become properties. Reified rdf:Statements, as written by the exporter,
+// contribute their literals as edge properties.
+func ParseTurtle(r io.Reader) (*Graph, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &turtleParser{
+		lex:      &turtleLexer{src: []rune(strings.TrimPrefix(string(data), "\uFEFF")), line: 1},
+		prefixes: make(map[string]string),
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return triplesToGraph(p.triples), nil
+}
+
+func triplesToGraph(triples []triple) *Graph {
+	// Reified statements are not entities
+	statements := make(map[string]bool)
+	for _, t := range triples {
+		if t.p == rdfType && !t.o.isLit && t.o.iri == rdfStatement {
+			statements[t.s] = true
+		}
+	}
+
+	b := newBuilder()
+	names := make(map[string]map[string]string) // node key -> name predicate -> name
+	type stmtParts struct {
+		s, p, o string
+		props   map[string]any
+	}
+	stmts := make(map[string]*stmtParts)
+
+	for _, t := range triples {
+		if statements[t.s] {
+			st := stmts[t.s]
+			if st == nil {
+				st = &stmtParts{props: make(map[string]any)}
+				stmts[t.s] = st
+			}
+			switch {
+			case t.p == rdfNS+"subject" && !t.o.isLit:
+				st.s = t.o.iri
+			case t.p == rdfNS+"predicate" && !t.o.isLit:
+				st.p = t.o.iri
+			case t.p == rdfNS+"object" && !t.o.isLit:
+				st.o = t.o.iri
+			case t.o.isLit && !strings.HasPrefix(t.p, export.NSVocab):
+				st.props[localName(t.p)] = t.o.literal
+			}
+			continue
+		}
+
+		n := b.node(t.s)
+		switch {
+		case t.p == rdfType:
+			if !t.o.isLit && n.Label == "" {
+				n.Label = localName(t.o.iri)
+			}
+		case t.o.isLit:
+			if isNamePredicate(t.p) {
+				if names[t.s] == nil {
+					names[t.s] = make(map[string]string)
+				}
+				if _, ok := names[t.s][t.p]; !ok {
+					names[t.s][t.p] = fmt.Sprint(t.o.literal)
+				}
+			} else if !strings.HasPrefix(t.p, export.NSVocab) {
+				n.Properties[localName(t.p)] = t.o.literal
+			}
+		default:
+			b.node(t.o.iri)
+			b.graph.Edges = append(b.graph.Edges, &Edge{
+				Source:       t.s,
+				Target:       t.o.iri,
+				RelationType: localName(t.p),
+				Properties:   make(map[string]any),
+			})
+		}
+	}
+
+	for _, n := range b.graph.Nodes {
+		for _, p := range namePredicates {
+			if name, ok := names[n.Key][p]; ok {
+				n.Name = name
+				break
+			}
+		}
+		if n.Name == "" {
+			n.Name = localName(n.Key)
+		}
+	}
+
+	// Attach the properties of reified statements to the matching edges
+	for _, st := range stmts {
+		if len(st.props) == 0 {
+			continue
+		}
+		relType := localName(st.p)
+		for _, e := range b.graph.Edges {
+			if e.Source == st.s && e.Target == st.o && e.RelationType == relType {
+				for k, v := range st.props {
+					e.Properties[k] = v
+				}
+				break
+			}
+		}
+	}
+	return b.graph
+}
+
+func isNamePredicate(p string) bool {
+	for _, np := range namePredicates {
+		if p == np {
+			return true
+		}
+	}
+	return false
+}
+
+// localName returns the part of an IRI after the last '#', '/' or ':', unescaped.
+func localName(iri string) string {
+	i := strings.LastIndexAny(iri, "#/:")
+	name := iri[i+1:]
+	if name == "" {
+		return iri
+	}
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		return unescaped
+	}
+	return name
+}
+
+type turtleParser struct {
+	lex      *turtleLexer
+	prefixes map[string]string
+	base     string
+	triples  []triple
+	blank    int
+	peeked   *token
+}
+
+func (p *turtleParser) next() (token, error) {
+	if p.peeked != nil {
+		t := *p.peeked
+		p.peeked = nil
+		return t, nil
+	}
+	return p.lex.next()
+}
+
+func (p *turtleParser) peek() (token, error) {
+	if p.peeked == nil {
+		t, err := p.lex.next()
+		if err != nil {
+			return t, err
+		}
+		p.peeked = &t
+	}
+	return *p.peeked, nil
+}
+
+func (p *turtleParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("turtle line %d: %s", p.lex.line, fmt.Sprintf(format, args...))
+}
+
+func (p *turtleParser) expect(kind tokenKind, value string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != kind || t.value != value {
+		return p.errorf("expected %q, got %q", value, t.value)
+	}
+	return nil
+}
+
+func (p *turtleParser) parse() error {
+	for {
+		t, err := p.peek()
+		if err != nil {
+			return err
+		}
+		switch {
+		case t.kind == tokEOF:
+			return nil
+		case t.kind == tokDirective || (t.kind == tokName && (strings.EqualFold(t.value, "PREFIX") || strings.EqualFold(t.value, "BASE"))):
+			if err := p.directive(); err != nil {
+				return err
+			}
+		default:
+			subject, err := p.subject()
+			if err != nil {
+				return err
+			}
+			if err := p.predicateObjectList(subject, "."); err != nil {
+				return err
+			}
+			if err := p.expect(tokPunct, "."); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *turtleParser) directive() error {
+	t, _ := p.next()
+	sparql := t.kind == tokName
+	keyword := strings.ToLower(strings.TrimPrefix(t.value, "@"))
+
+	switch keyword {
+	case "prefix":
+		name, err := p.next()
+		if err != nil {
+			return err
+		}
+		if name.kind != tokName || !strings.HasSuffix(name.value, ":") {
+			return p.errorf("invalid prefix name %q", name.value)
+		}
+		iri, err := p.next()
+		if err != nil {
+			return err
+		}
+		if iri.kind != tokIRI {
+			return p.errorf("expected IRI for prefix %s", name.value)
+		}
+		p.prefixes[strings.TrimSuffix(name.value, ":")] = p.resolve(iri.value)
+	case "base":
+		iri, err := p.next()
+		if err != nil {
+			return err
+		}
+		if iri.kind != tokIRI {
+			return p.errorf("expected IRI for base")
+		}
+		p.base = p.resolve(iri.value)
+	default:
+		return p.errorf("unknown directive %q", t.value)
+	}
+
+	if !sparql {
+		return p.expect(tokPunct, ".")
+	}
+	return nil
+}
+
+func (p *turtleParser) subject() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case t.kind == tokIRI:
+		return p.resolve(t.value), nil
+	case t.kind == tokName:
+		return p.expand(t.value)
+	case t.kind == tokPunct && t.value == "[":
+		return p.anonymous()
+	default:
+		return "", p.errorf("unexpected %q as subject", t.value)
+	}
+}
+
+// anonymous parses the rest of a [ ... ] blank node and returns its generated label.
+func (p *turtleParser) anonymous() (string, error) {
+	p.blank++
+	node := fmt.Sprintf("_:anon%d", p.blank)
+	t, err := p.peek()
+	if err != nil {
+		return "", err
+	}
+	if !(t.kind == tokPunct && t.value == "]") {
+		if err := p.predicateObjectList(node, "]"); err != nil {
+			return "", err
+		}
+	}
+	return node, p.expect(tokPunct, "]")
+}
+
+func (p *turtleParser) predicateObjectList(subject, end string) error {
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		var predicate string
+		switch {
+		case t.kind == tokName && t.value == "a":
+			predicate = rdfType
+		case t.kind == tokIRI:
+			predicate = p.resolve(t.value)
+		case t.kind == tokName:
+			if predicate, err = p.expand(t.value); err != nil {
+				return err
+			}
+		default:
+			return p.errorf("unexpected %q as predicate", t.value)
+		}
+
+		for {
+			object, err := p.object()
+			if err != nil {
+				return err
+			}
+			p.triples = append(p.triples, triple{s: subject, p: predicate, o: object})
+
+			t, err := p.peek()
+			if err != nil {
+				return err
+			}
+			if t.kind == tokPunct && t.value == "," {
+				p.next()
+				continue
+			}
+			break
+		}
+
+		// One or more ';', optionally followed by the end of the list
+		t, err = p.peek()
+		if err != nil {
+			return err
+		}
+		if !(t.kind == tokPunct && t.value == ";") {
+			return nil
+		}
+		for t.kind == tokPunct && t.value == ";" {
+			p.next()
+			if t, err = p.peek(); err != nil {
+				return err
+			}
+		}
+		if t.kind == tokPunct && t.value == end {
+			return nil
+		}
+	}
+}
+
+func (p *turtleParser) object() (term, error) {
+	t, err := p.next()
+	if err != nil {
+		return term{}, err
+	}
+	switch t.kind {
+	case tokIRI:
+		return term{iri: p.resolve(t.value)}, nil
+	case tokName:
+		switch t.value {
+		case "true", "false":
+			return term{literal: t.value == "true", isLit: true}, nil
+		}
+		iri, err := p.expand(t.value)
+		return term{iri: iri}, err
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return term{}, p.errorf("invalid number %q", t.value)
+		}
+		return term{literal: f, isLit: true}, nil
+	case tokString:
+		return p.literalSuffix(t.value)
+	case tokPunct:
+		switch t.value {
+		case "[":
+			node, err := p.anonymous()
+			return term{iri: node}, err
+		case "(":
+			return term{}, p.errorf("collections are not supported")
+		}
+	}
+	return term{}, p.errorf("unexpected %q as object", t.value)
+}
+
+// literalSuffix handles an optional language tag or datatype after a string.
+func (p *turtleParser) literalSuffix(value string) (term, error) {
+	t, err := p.peek()
+	if err != nil {
+		return term{}, err
+	}
+	switch {
+	case t.kind == tokDirective: // language tag
+		p.next()
+	case t.kind == tokPunct && t.value == "^^":
+		p.next()
+		dt, err := p.next()
+		if err != nil {
+			return term{}, err
+		}
+		var datatype string
+		switch dt.kind {
+		case tokIRI:
+			datatype = p.resolve(dt.value)
+		case tokName:
+			if datatype, err = p.expand(dt.value); err != nil {
+				return term{}, err
+			}
+		default:
+			return term{}, p.errorf("invalid datatype %q", dt.value)
+		}
+		switch strings.TrimPrefix(datatype, xsdNS) {
+		case "integer", "int", "long", "short", "decimal", "double", "float":
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				return term{literal: f, isLit: true}, nil
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(value); err == nil {
+				return term{literal: b, isLit: true}, nil
+			}
+		}
+	}
+	return term{literal: value, isLit: true}, nil
+}
+
+// expand turns a prefixed name or blank node label into an IRI.
+func (p *turtleParser) expand(name string) (string, error) {
+	if strings.HasPrefix(name, "_:") {
+		return name, nil
+	}
+	i := strings.Index(name, ":")
+	if i < 0 {
+		return "", p.errorf("unexpected %q", name)
+	}
+	ns, ok := p.prefixes[name[:i]]
+	if !ok {
+		return "", p.errorf("undefined prefix %q", name[:i])
+	}
+	return ns + unescapeLocal(name[i+1:]), nil
+}
+
+func (p *turtleParser) resolve(iri string) string {
+	if p.base == "" || strings.Contains(iri, ":") {
+		return iri
+	}
+	return p.base + iri
+}
+
+func unescapeLocal(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+type tokenKind int
+
+const (
+	tokEOF       tokenKind = iota
+	tokIRI                 // <...>
+	tokName                // prefixed name, blank node label, 'a', true/false, PREFIX/BASE
+	tokString              // string literal, unescaped
+	tokNumber              // integer, decimal or double
+	tokDirective           // @prefix, @base or a language tag
+	tokPunct               // . ; , [ ] ( ) ^^
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+type turtleLexer struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func (l *turtleLexer) errorf(format string, args ...any) error {
+	return fmt.Errorf("turtle line %d: %s", l.line, fmt.Sprintf(format, args...))
+}
+
+// at returns the rune i positions ahead, or 0 past the end.
+func (l *turtleLexer) at(i int) rune {
+	if l.pos+i < len(l.src) {
+		return l.src[l.pos+i]
+	}
+	return 0
+}
+
+func (l *turtleLexer) advance() rune {
+	c := l.src[l.pos]
+	l.pos++
+	if c == '\n' {
+		l.line++
+	}
+	return c
+}
+
+func (l *turtleLexer) next() (token, error) {
+	// Skip whitespace and comments
+	for l.pos < len(l.src) {
+		c := l.at(0)
+		if c == '#' {
+			for l.pos < len(l.src) && l.at(0) != '\n' {
+				l.advance()
+			}
+			continue
+		}
+		if !unicode.IsSpace(c) {
+			break
+		}
+		l.advance()
+	}
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF}, nil
+	}
+
+	c := l.at(0)
+	switch {
+	case c == '<':
+		l.advance()
+		start := l.pos
+		for l.pos < len(l.src) && l.at(0) != '>' {
+			if l.advance() == '\n' {
+				return token{}, l.errorf("newline in IRI")
+			}
+		}
+		if l.pos >= len(l.src) {
+			return token{}, l.errorf("unterminated IRI")
+		}
+		iri := string(l.src[start:l.pos])
+		l.advance()
+		return token{kind: tokIRI, value: iri}, nil
+	case c == '"' || c == '\'':
+		s, err := l.readString(c)
+		return token{kind: tokString, value: s}, err
+	case c == '@':
+		l.advance()
+		word := l.readWhile(func(c rune) bool { return c == '-' || unicode.IsLetter(c) || unicode.IsDigit(c) })
+		return token{kind: tokDirective, value: "@" + word}, nil
+	case c == '^':
+		if l.at(1) != '^' {
+			return token{}, l.errorf("expected ^^")
+		}
+		l.pos += 2
+		return token{kind: tokPunct, value: "^^"}, nil
+	case c == '.' && unicode.IsDigit(l.at(1)):
+		return token{kind: tokNumber, value: l.readNumber()}, nil
+	case strings.ContainsRune(".;,[]()", c):
+		l.advance()
+		return token{kind: tokPunct, value: string(c)}, nil
+	case c == '+' || c == '-' || unicode.IsDigit(c):
+		return token{kind: tokNumber, value: l.readNumber()}, nil
+	default:
+		start := l.pos
+		for l.pos < len(l.src) {
+			c := l.at(0)
+			if unicode.IsSpace(c) || strings.ContainsRune(";,[]()<\"'#^", c) {
+				break
+			}
+			// A dot ends the name unless more name characters follow
+			if c == '.' {
+				next := l.at(1)
+				if next == 0 || unicode.IsSpace(next) || strings.ContainsRune(".;,[]()#", next) {
+					break
+				}
+			}
+			if c == '\\' && l.pos+1 < len(l.src) {
+				l.advance()
+			}
+			l.advance()
+		}
+		if l.pos == start {
+			return token{}, l.errorf("unexpected character %q", c)
+		}
+		return token{kind: tokName, value: string(l.src[start:l.pos])}, nil
+	}
+}
+
+func (l *turtleLexer) readWhile(ok func(rune) bool) string {
+	start := l.pos
+	for l.pos < len(l.src) && ok(l.at(0)) {
+		l.advance()
+	}
+	return string(l.src[start:l.pos])
+}
+
+// readNumber reads a number, leaving a trailing statement-ending dot in place.
+func (l *turtleLexer) readNumber() string {
+	start := l.pos
+	if c := l.at(0); c == '+' || c == '-' {
+		l.advance()
+	}
+	for l.pos < len(l.src) {
+		c := l.at(0)
+		switch {
+		case unicode.IsDigit(c):
+		case c == '.' && unicode.IsDigit(l.at(1)):
+		case (c == 'e' || c == 'E') && l.pos > start:
+			if s := l.at(1); s == '+' || s == '-' {
+				l.advance()
+			}
+		default:
+			return string(l.src[start:l.pos])
+		}
+		l.advance()
+	}
+	return string(l.src[start:l.pos])
+}
+
+func (l *turtleLexer) readString(quote rune) (string, error) {
+	// Long strings use three quotes and may span lines
+	long := l.at(1) == quote && l.at(2) == quote
+	if long {
+		l.pos += 3
+	} else {
+		l.advance()
+	}
+
+	var sb strings.Builder
+	for {
+		if l.pos >= len(l.src) {
+			return "", l.errorf("unterminated string")
+		}
+		c := l.advance()
+		switch {
+		case c == '\\':
+			if l.pos >= len(l.src) {
+				return "", l.errorf("unterminated string")
+			}
+			e := l.advance()
+			switch e {
+			case 'n':
+				sb.WriteRune('\n')
+			case 'r':
+				sb.WriteRune('\r')
+			case 't':
+				sb.WriteRune('\t')
+			case 'b':
+				sb.WriteRune('\b')
+			case 'f':
+				sb.WriteRune('\f')
+			case 'u', 'U':
+				n := 4
+				if e == 'U' {
+					n = 8
+				}
+				if l.pos+n > len(l.src) {
+					return "", l.errorf("unterminated string")
+				}
+				hex := string(l.src[l.pos : l.pos+n])
+				code, err := strconv.ParseUint(hex, 16, 32)
+				if err != nil {
+					return "", l.errorf("invalid escape \\%c%s", e, hex)
+				}
+				l.pos += n
+				sb.WriteRune(rune(code))
+			default:
+				sb.WriteRune(e)
+			}
+		case c == quote && !long:
+			return sb.String(), nil
+		case c == quote && l.at(0) == quote && l.at(1) == quote:
+			l.pos += 2
+			return sb.String(), nil
+		case c == '\n' && !long:
+			return "", l.errorf("newline in string")
+		default:
+			sb.WriteRune(c)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// maxCacheCandidates bounds the cached answers compared against a question.
+const maxCacheCandidates = 500
+
+// ChatCacheEntry is a chat answer cached for a question about one version of a notebook.
+type ChatCacheEntry struct {
+	ID             int64     `db:"id"`
+	NotebookID     int64     `db:"notebook_id"`
+	ContentVersion int64     `db:"content_version"`
+	RequestKey     string    `db:"request_key"`
+	Query          string    `db:"query"`
+	Embedding      []float32 `db:"-"`
+	// Response is the JSON encoded answer
+	Response  []byte    `db:"response"`
+	CreatedAt time.Time `db:"created_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// ChatCacheRepository stores chat answers for lookup by question similarity.
+type ChatCacheRepository interface {
+	// Find returns the unexpired entry for the notebook version and request key whose question
+	// embedding is most similar to embedding, with that cosine similarity. It returns nil when
+	// no entry reaches minSimilarity.
+	Find(ctx context.Context, notebookID, contentVersion int64, requestKey string, embedding []float32, minSimilarity float64) (*ChatCacheEntry, float64, error)
+	// Save stores an entry, dropping the notebook's expired entries and those of older versions.
+	Save(ctx context.Context, entry *ChatCacheEntry) error
+}
+
+type PostgresChatCacheRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresChatCacheRepository(db *sqlx.DB) *PostgresChatCacheRepository {
+	return &PostgresChatCacheRepository{db: db}
+}
+
+// chatCacheRow is a chat_cache row with its raw embedding.
+type chatCacheRow struct {
+	ChatCacheEntry
+	RawEmbedding []byte `db:"embedding"`
+}
+
+func (r *PostgresChatCacheRepository) Find(ctx context.Context, notebookID, contentVersion int64, requestKey string, embedding []float32, minSimilarity float64) (*ChatCacheEntry, float64, error) {
+	query := `
+		SELECT * FROM chat_cache
+		WHERE notebook_id = $1 AND content_version = $2 AND request_key = $3 AND expires_at > NOW()
+		ORDER BY created_at DESC
+		LIMIT $4
+	`
+	var rows []*chatCacheRow
+	if err := r.db.SelectContext(ctx, &rows, query, notebookID, contentVersion, requestKey, maxCacheCandidates); err != nil {
+		return nil, 0, fmt.Errorf("failed to find cached chat response: %w", err)
+	}
+
+	entries := make([]*ChatCacheEntry, len(rows))
+	for i, row := range rows {
+		row.Embedding = decodeEmbedding(row.RawEmbedding)
+		entries[i] = &row.ChatCacheEntry
+	}
+	best, score := mostSimilarEntry(entries, embedding, minSimilarity)
+	return best, score, nil
+}
+
+func (r *PostgresChatCacheRepository) Save(ctx context.Context, entry *ChatCacheEntry) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM chat_cache WHERE notebook_id = $1 AND (content_version < $2 OR expires_at <= NOW())`,
+		entry.NotebookID, entry.ContentVersion); err != nil {
+		return fmt.Errorf("failed to delete stale chat cache entries: %w", err)
+	}
+
+	entry.CreatedAt = time.Now()
+	query := `
+		INSERT INTO chat_cache (notebook_id, content_version, request_key, query, embedding, response, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	if err := tx.QueryRowxContext(ctx, query,
+		entry.NotebookID, entry.ContentVersion, entry.RequestKey, entry.Query,
+		encodeEmbedding(entry.Embedding), string(entry.Response), entry.CreatedAt, entry.ExpiresAt,
+	).Scan(&entry.ID); err != nil {
+		return fmt.Errorf("failed to save chat cache entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit chat cache entry: %w", err)
+	}
+	return nil
+}
+
+// MemoryChatCacheRepository keeps chat cache entries in process memory, for single instance
+// deployments. Each notebook keeps at most maxEntries entries, dropping the oldest.
+type MemoryChatCacheRepository struct {
+	mu         sync.Mutex
+	nextID     int64
+	maxEntries int
+	entries    map[int64][]*ChatCacheEntry
+}
+
+func NewMemoryChatCacheRepository(maxEntries int) *MemoryChatCacheRepository {
+	if maxEntries <= 0 {
+		maxEntries = maxCacheCandidates
+	}
+	return &MemoryChatCacheRepository{maxEntries: maxEntries, entries: make(map[int64][]*ChatCacheEntry)}
+}
+
+func (r *MemoryChatCacheRepository) Find(ctx context.Context, notebookID, contentVersion int64, requestKey string, embedding []float32, minSimilarity float64) (*ChatCacheEntry, float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var candidates []*ChatCacheEntry
+	for _, e := range r.entries[notebookID] {
+		if e.ContentVersion == contentVersion && e.RequestKey == requestKey && e.ExpiresAt.After(now) {
+			candidates = append(candidates, e)
+		}
+	}
+	best, score := mostSimilarEntry(candidates, embedding, minSimilarity)
+	return best, score, nil
+}
+
+func (r *MemoryChatCacheRepository) Save(ctx context.Context, entry *ChatCacheEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	kept := []*ChatCacheEntry{}
+	for _, e := range r.entries[entry.NotebookID] {
+		if e.ContentVersion >= entry.ContentVersion && e.ExpiresAt.After(now) {
+			kept = append(kept, e)
+		}
+	}
+	r.nextID++
+	entry.ID = r.nextID
+	entry.CreatedAt = now
+	kept = append(kept, entry)
+	if len(kept) > r.maxEntries {
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].CreatedAt.Before(kept[j].CreatedAt) })
+		kept = kept[len(kept)-r.maxEntries:]
+	}
+	r.entries[entry.NotebookID] = kept
+	return nil
+}
+
+// mostSimilarEntry returns the entry whose embedding is closest to embedding by cosine
+// similarity, if it reaches minSimilarity.
+func mostSimilarEntry(entries []*ChatCacheEntry, embedding []float32, minSimilarity float64) (*ChatCacheEntry, float64) {
+	var best *ChatCacheEntry
+	bestScore := minSimilarity
+	for _, e := range entries {
+		score := cosineSimilarity(e.Embedding, embedding)
+		if score >= bestScore {
+			best, bestScore = e, score
+		}
+	}
+	if best == nil {
+		return nil, 0
+	}
+	return best, bestScore
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func encodeEmbedding(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func decodeEmbedding(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
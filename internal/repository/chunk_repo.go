@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/suyw-0123/graphweaver/internal/entity"
@@ -12,6 +13,19 @@ import (
 type ChunkRepository interface {
 	CreateChunks(ctx context.Context, chunks []*entity.Chunk) error
 	GetChunksByDocumentID(ctx context.Context, docID int64) ([]*entity.Chunk, error)
+	GetChunksByIDs(ctx context.Context, ids []string) ([]*entity.Chunk, error)
+	ListChunkIDs(ctx context.Context, createdBefore time.Time) ([]string, error)
+	DeleteByDocumentID(ctx context.Context, docID int64) error
+	// SearchChunks runs a full-text search over the chunks of a notebook's non-deleted
+	// documents, or of the given documents among them, best matches first. Chunks matching
+	// any query term are returned.
+	SearchChunks(ctx context.Context, notebookID int64, query string, docIDs []int64, limit int) ([]*LexicalMatch, error)
+}
+
+// LexicalMatch is a chunk found by full-text search with its rank.
+type LexicalMatch struct {
+	entity.Chunk
+	Score float64 `db:"score"`
 }
 
 // PostgresChunkRepository implements ChunkRepository using PostgreSQL
@@ -47,13 +61,76 @@ func (r *PostgresChunkRepository) CreateChunks(ctx context.Context, chunks []*en
 	return tx.Commit()
 }
 
+// DeleteByDocumentID deletes a document's chunks, and with them their mentions
+func (r *PostgresChunkRepository) DeleteByDocumentID(ctx context.Context, docID int64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM chunks WHERE document_id = $1`, docID); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
 // GetChunksByDocumentID retrieves all chunks for a document
 func (r *PostgresChunkRepository) GetChunksByDocumentID(ctx context.Context, docID int64) ([]*entity.Chunk, error) {
 	chunks := []*entity.Chunk{}
-	query := `SELECT * FROM chunks WHERE document_id = $1 ORDER BY chunk_index ASC`
+	query := `
+		SELECT id, document_id, chunk_index, content, token_count
+		FROM chunks WHERE document_id = $1 ORDER BY chunk_index ASC
+	`
 
 	if err := r.db.SelectContext(ctx, &chunks, query, docID); err != nil {
 		return nil, fmt.Errorf("failed to get chunks: %w", err)
 	}
 	return chunks, nil
 }
+
+// GetChunksByIDs retrieves chunks by ID. Missing IDs are skipped.
+func (r *PostgresChunkRepository) GetChunksByIDs(ctx context.Context, ids []string) ([]*entity.Chunk, error) {
+	chunks := []*entity.Chunk{}
+	if len(ids) == 0 {
+		return chunks, nil
+	}
+	query := `
+		SELECT id, document_id, chunk_index, content, token_count
+		FROM chunks WHERE id = ANY($1::uuid[])
+	`
+
+	if err := r.db.SelectContext(ctx, &chunks, query, ids); err != nil {
+		return nil, fmt.Errorf("failed to get chunks by ids: %w", err)
+	}
+	return chunks, nil
+}
+
+// ListChunkIDs retrieves the IDs of all chunks created before the given time.
+func (r *PostgresChunkRepository) ListChunkIDs(ctx context.Context, createdBefore time.Time) ([]string, error) {
+	ids := []string{}
+	query := `SELECT id::text FROM chunks WHERE created_at < $1`
+
+	if err := r.db.SelectContext(ctx, &ids, query, createdBefore); err != nil {
+		return nil, fmt.Errorf("failed to list chunk ids: %w", err)
+	}
+	return ids, nil
+}
+
+// SearchChunks ORs the query terms, so a question containing a rare name still matches the
+// chunks naming it; ts_rank_cd ranks chunks matching more terms, and closer together, higher.
+func (r *PostgresChunkRepository) SearchChunks(ctx context.Context, notebookID int64, query string, docIDs []int64, limit int) ([]*LexicalMatch, error) {
+	matches := []*LexicalMatch{}
+	if docIDs == nil {
+		docIDs = []int64{}
+	}
+	sqlQuery := `
+		SELECT c.id, c.document_id, c.chunk_index, c.content, c.token_count, ts_rank_cd(c.content_tsv, q) AS score
+		FROM chunks c
+		JOIN documents d ON d.id = c.document_id,
+		     to_tsquery('simple', replace(plainto_tsquery('simple', $2)::text, ' & ', ' | ')) q
+		WHERE d.notebook_id = $1 AND d.is_deleted = false AND c.content_tsv @@ q
+		  AND (cardinality($3::bigint[]) = 0 OR c.document_id = ANY($3::bigint[]))
+		ORDER BY score DESC, c.id
+		LIMIT $4
+	`
+
+	if err := r.db.SelectContext(ctx, &matches, sqlQuery, notebookID, query, docIDs, limit); err != nil {
+		return nil, fmt.Errorf("failed to search chunks: %w", err)
+	}
+	return matches, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+// CommunityRepository stores the community hierarchy of notebook graphs.
+type CommunityRepository interface {
+	ReplaceForNotebook(ctx context.Context, notebookID int64, communities []*entity.Community) error
+	ListByNotebook(ctx context.Context, notebookID int64, level *int) ([]*entity.Community, error)
+	// StartBuild records a running build of a notebook's communities. It returns nil if a
+	// build is already running.
+	StartBuild(ctx context.Context, notebookID int64) (*entity.CommunityBuild, error)
+	FinishBuild(ctx context.Context, notebookID int64, communities int, errorMessage *string) error
+	// GetBuild returns the latest build of a notebook's communities, nil if there was none.
+	GetBuild(ctx context.Context, notebookID int64) (*entity.CommunityBuild, error)
+}
+
+type PostgresCommunityRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresCommunityRepository(db *sqlx.DB) *PostgresCommunityRepository {
+	return &PostgresCommunityRepository{db: db}
+}
+
+// ReplaceForNotebook deletes a notebook's communities and inserts new ones in one transaction.
+// Communities must have negative placeholder IDs and come parents first; a ParentID refers to
+// the placeholder of an earlier community. Real IDs are set on the structs after commit.
+func (r *PostgresCommunityRepository) ReplaceForNotebook(ctx context.Context, notebookID int64, communities []*entity.Community) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM communities WHERE notebook_id = $1`, notebookID); err != nil {
+		return fmt.Errorf("failed to delete communities: %w", err)
+	}
+
+	ids := make(map[int64]int64, len(communities))
+	parents := make([]*int64, len(communities))
+	for i, c := range communities {
+		if c.ID >= 0 {
+			return fmt.Errorf("community %d is not a placeholder", c.ID)
+		}
+		if c.ParentID != nil {
+			parentID, ok := ids[*c.ParentID]
+			if !ok {
+				return fmt.Errorf("community %d references unknown parent %d", c.ID, *c.ParentID)
+			}
+			parents[i] = &parentID
+		}
+
+		var id int64
+		query := `
+			INSERT INTO communities (notebook_id, parent_id, level, title, summary, rank, node_count)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id
+		`
+		err := tx.QueryRowxContext(ctx, query, notebookID, parents[i], c.Level, c.Title, c.Summary, c.Rank, len(c.NodeIDs)).Scan(&id)
+		if err != nil {
+			return fmt.Errorf("failed to insert community: %w", err)
+		}
+		ids[c.ID] = id
+
+		if len(c.NodeIDs) > 0 {
+			query := `
+				INSERT INTO community_nodes (community_id, node_id)
+				SELECT $1, unnest($2::bigint[])
+			`
+			if _, err := tx.ExecContext(ctx, query, id, c.NodeIDs); err != nil {
+				return fmt.Errorf("failed to insert community nodes: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for i, c := range communities {
+		c.ID = ids[c.ID]
+		c.NotebookID = notebookID
+		c.ParentID = parents[i]
+		c.NodeCount = len(c.NodeIDs)
+	}
+	return nil
+}
+
+// ListByNotebook retrieves a notebook's communities with their member node IDs, highest rank
+// first. If level is nil, communities of all levels are returned.
+func (r *PostgresCommunityRepository) ListByNotebook(ctx context.Context, notebookID int64, level *int) ([]*entity.Community, error) {
+	communities := []*entity.Community{}
+	query := `
+		SELECT * FROM communities
+		WHERE notebook_id = $1 AND ($2::int IS NULL OR level = $2)
+		ORDER BY level ASC, rank DESC, id ASC
+	`
+	if err := r.db.SelectContext(ctx, &communities, query, notebookID, level); err != nil {
+		return nil, fmt.Errorf("failed to list communities: %w", err)
+	}
+	if len(communities) == 0 {
+		return communities, nil
+	}
+
+	ids := make([]int64, len(communities))
+	byID := make(map[int64]*entity.Community, len(communities))
+	for i, c := range communities {
+		ids[i] = c.ID
+		byID[c.ID] = c
+	}
+
+	var members []struct {
+		CommunityID int64 `db:"community_id"`
+		NodeID      int64 `db:"node_id"`
+	}
+	query = `
+		SELECT community_id, node_id FROM community_nodes
+		WHERE community_id = ANY($1::bigint[])
+		ORDER BY community_id, node_id
+	`
+	if err := r.db.SelectContext(ctx, &members, query, ids); err != nil {
+		return nil, fmt.Errorf("failed to list community nodes: %w", err)
+	}
+	for _, m := range members {
+		c := byID[m.CommunityID]
+		c.NodeIDs = append(c.NodeIDs, m.NodeID)
+	}
+	return communities, nil
+}
+
+// staleCommunityBuild is how long after starting a running build is taken to have died with
+// the server, so that it no longer blocks a new one.
+const staleCommunityBuild = "1 hour"
+
+// StartBuild records a running build unless one is already running.
+func (r *PostgresCommunityRepository) StartBuild(ctx context.Context, notebookID int64) (*entity.CommunityBuild, error) {
+	query := `
+		INSERT INTO community_builds (notebook_id, status)
+		VALUES ($1, $2)
+		ON CONFLICT (notebook_id) DO UPDATE
+		SET status = EXCLUDED.status, error_message = NULL, communities = 0, started_at = NOW(), finished_at = NULL
+		WHERE community_builds.status <> $2 OR community_builds.started_at < NOW() - $3::interval
+		RETURNING *
+	`
+	var build entity.CommunityBuild
+	err := r.db.GetContext(ctx, &build, query, notebookID, entity.CommunityBuildRunning, staleCommunityBuild)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to start community build: %w", err)
+	}
+	return &build, nil
+}
+
+// FinishBuild marks a notebook's running build completed, or failed with errorMessage.
+func (r *PostgresCommunityRepository) FinishBuild(ctx context.Context, notebookID int64, communities int, errorMessage *string) error {
+	status := entity.CommunityBuildCompleted
+	if errorMessage != nil {
+		status = entity.CommunityBuildFailed
+	}
+	query := `
+		UPDATE community_builds
+		SET status = $2, error_message = $3, communities = $4, finished_at = NOW()
+		WHERE notebook_id = $1
+	`
+	if _, err := r.db.ExecContext(ctx, query, notebookID, status, errorMessage, communities); err != nil {
+		return fmt.Errorf("failed to finish community build: %w", err)
+	}
+	return nil
+}
+
+// GetBuild retrieves the latest build of a notebook's communities.
+func (r *PostgresCommunityRepository) GetBuild(ctx context.Context, notebookID int64) (*entity.CommunityBuild, error) {
+	var build entity.CommunityBuild
+	err := r.db.GetContext(ctx, &build, `SELECT * FROM community_builds WHERE notebook_id = $1`, notebookID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get community build: %w", err)
+	}
+	return &build, nil
+}
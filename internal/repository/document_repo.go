@@ -17,6 +17,15 @@ type DocumentRepository interface {
 	List(ctx context.Context, limit, offset int, notebookID *int64) ([]*entity.Document, error)
 	UpdateStatus(ctx context.Context, id int64, status string, errorMessage *string) error
 	UpdateSummary(ctx context.Context, id int64, summary string) error
+	FindByContentHash(ctx context.Context, hash string, notebookID *int64) (*entity.Document, error)
+	FindCompletedByContentHash(ctx context.Context, hash string) (*entity.Document, error)
+	SoftDelete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64) error
+	ListDeleted(ctx context.Context, deletedBefore time.Time) ([]*entity.Document, error)
+	ListAllByNotebook(ctx context.Context, notebookID int64) ([]*entity.Document, error)
+	CountByFilePath(ctx context.Context, filePath string) (int, error)
+	ListFilePaths(ctx context.Context) ([]string, error)
+	ExistingIDs(ctx context.Context, ids []int64) (map[int64]bool, error)
 }
 
 // PostgresDocumentRepository implements DocumentRepository using PostgreSQL.
@@ -32,8 +41,8 @@ func NewPostgresDocumentRepository(db *sqlx.DB) *PostgresDocumentRepository {
 // Create inserts a new document into the database.
 func (r *PostgresDocumentRepository) Create(ctx context.Context, doc *entity.Document) error {
 	query := `
-		INSERT INTO documents (filename, file_path, mime_type, file_size, status, notebook_id, created_at, updated_at)
-		VALUES (:filename, :file_path, :mime_type, :file_size, :status, :notebook_id, :created_at, :updated_at)
+		INSERT INTO documents (filename, file_path, mime_type, file_size, content_hash, status, notebook_id, created_at, updated_at)
+		VALUES (:filename, :file_path, :mime_type, :file_size, :content_hash, :status, :notebook_id, :created_at, :updated_at)
 		RETURNING id
 	`
 
@@ -129,3 +138,136 @@ func (r *PostgresDocumentRepository) UpdateSummary(ctx context.Context, id int64
 	}
 	return nil
 }
+
+// FindByContentHash retrieves the most recent document with the given content hash in a notebook.
+// A nil notebookID matches documents that are not attached to any notebook.
+func (r *PostgresDocumentRepository) FindByContentHash(ctx context.Context, hash string, notebookID *int64) (*entity.Document, error) {
+	var doc entity.Document
+	query := `
+		SELECT * FROM documents
+		WHERE content_hash = $1 AND notebook_id IS NOT DISTINCT FROM $2 AND is_deleted = false
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	if err := r.db.GetContext(ctx, &doc, query, hash, notebookID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find document by content hash: %w", err)
+	}
+	return &doc, nil
+}
+
+// FindCompletedByContentHash retrieves the most recent fully processed document with the given content hash,
+// regardless of notebook.
+func (r *PostgresDocumentRepository) FindCompletedByContentHash(ctx context.Context, hash string) (*entity.Document, error) {
+	var doc entity.Document
+	query := `
+		SELECT * FROM documents
+		WHERE content_hash = $1 AND status = 'completed' AND is_deleted = false
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	if err := r.db.GetContext(ctx, &doc, query, hash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find completed document by content hash: %w", err)
+	}
+	return &doc, nil
+}
+
+// SoftDelete marks a document as deleted. Its data is removed later by a purge.
+func (r *PostgresDocumentRepository) SoftDelete(ctx context.Context, id int64) error {
+	query := `
+		UPDATE documents
+		SET is_deleted = true, deleted_at = $1, updated_at = $1
+		WHERE id = $2 AND is_deleted = false
+	`
+
+	res, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to soft delete document: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Delete permanently removes a document. Chunks, nodes and edges are removed by cascade.
+func (r *PostgresDocumentRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM documents WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+// ListDeleted retrieves soft-deleted documents that were deleted before the given time.
+func (r *PostgresDocumentRepository) ListDeleted(ctx context.Context, deletedBefore time.Time) ([]*entity.Document, error) {
+	docs := []*entity.Document{}
+	query := `
+		SELECT * FROM documents
+		WHERE is_deleted = true AND (deleted_at IS NULL OR deleted_at < $1)
+		ORDER BY id
+	`
+
+	if err := r.db.SelectContext(ctx, &docs, query, deletedBefore); err != nil {
+		return nil, fmt.Errorf("failed to list deleted documents: %w", err)
+	}
+	return docs, nil
+}
+
+// ListAllByNotebook retrieves every document of a notebook, including soft-deleted ones.
+func (r *PostgresDocumentRepository) ListAllByNotebook(ctx context.Context, notebookID int64) ([]*entity.Document, error) {
+	docs := []*entity.Document{}
+	query := `SELECT * FROM documents WHERE notebook_id = $1 ORDER BY id`
+
+	if err := r.db.SelectContext(ctx, &docs, query, notebookID); err != nil {
+		return nil, fmt.Errorf("failed to list notebook documents: %w", err)
+	}
+	return docs, nil
+}
+
+// CountByFilePath counts documents, including soft-deleted ones, that reference a stored file.
+func (r *PostgresDocumentRepository) CountByFilePath(ctx context.Context, filePath string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM documents WHERE file_path = $1`
+
+	if err := r.db.GetContext(ctx, &count, query, filePath); err != nil {
+		return 0, fmt.Errorf("failed to count documents by file path: %w", err)
+	}
+	return count, nil
+}
+
+// ListFilePaths retrieves the distinct stored file paths referenced by any document.
+func (r *PostgresDocumentRepository) ListFilePaths(ctx context.Context) ([]string, error) {
+	paths := []string{}
+	query := `SELECT DISTINCT file_path FROM documents`
+
+	if err := r.db.SelectContext(ctx, &paths, query); err != nil {
+		return nil, fmt.Errorf("failed to list file paths: %w", err)
+	}
+	return paths, nil
+}
+
+// ExistingIDs reports which of the given document IDs still have a row, soft-deleted or not.
+func (r *PostgresDocumentRepository) ExistingIDs(ctx context.Context, ids []int64) (map[int64]bool, error) {
+	existing := make(map[int64]bool, len(ids))
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	found := []int64{}
+	query := `SELECT id FROM documents WHERE id = ANY($1)`
+	if err := r.db.SelectContext(ctx, &found, query, ids); err != nil {
+		return nil, fmt.Errorf("failed to check document ids: %w", err)
+	}
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
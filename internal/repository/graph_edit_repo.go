@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+// GraphEditRepository stores the audit trail of manual graph edits.
+type GraphEditRepository interface {
+	Create(ctx context.Context, edit *entity.GraphEdit) error
+	// ListByDocument returns the edits of a document's entities, oldest first.
+	ListByDocument(ctx context.Context, docID int64) ([]*entity.GraphEdit, error)
+	// ListByEntity returns the edits of one node or edge, oldest first.
+	ListByEntity(ctx context.Context, entityType string, entityID int64) ([]*entity.GraphEdit, error)
+	// ListByNotebook returns the most recent edits across a notebook's documents, newest first.
+	ListByNotebook(ctx context.Context, notebookID int64, limit int) ([]*entity.GraphEdit, error)
+}
+
+type PostgresGraphEditRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresGraphEditRepository(db *sqlx.DB) *PostgresGraphEditRepository {
+	return &PostgresGraphEditRepository{db: db}
+}
+
+func (r *PostgresGraphEditRepository) Create(ctx context.Context, edit *entity.GraphEdit) error {
+	query := `
+		INSERT INTO graph_edits (document_id, entity_type, entity_id, action, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowxContext(ctx, query, edit.DocumentID, edit.EntityType, edit.EntityID, edit.Action, edit.Before, edit.After).
+		Scan(&edit.ID, &edit.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create graph edit: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresGraphEditRepository) ListByDocument(ctx context.Context, docID int64) ([]*entity.GraphEdit, error) {
+	edits := []*entity.GraphEdit{}
+	query := `SELECT * FROM graph_edits WHERE document_id = $1 ORDER BY id`
+	if err := r.db.SelectContext(ctx, &edits, query, docID); err != nil {
+		return nil, fmt.Errorf("failed to list graph edits: %w", err)
+	}
+	return edits, nil
+}
+
+func (r *PostgresGraphEditRepository) ListByEntity(ctx context.Context, entityType string, entityID int64) ([]*entity.GraphEdit, error) {
+	edits := []*entity.GraphEdit{}
+	query := `SELECT * FROM graph_edits WHERE entity_type = $1 AND entity_id = $2 ORDER BY id`
+	if err := r.db.SelectContext(ctx, &edits, query, entityType, entityID); err != nil {
+		return nil, fmt.Errorf("failed to list graph edits: %w", err)
+	}
+	return edits, nil
+}
+
+func (r *PostgresGraphEditRepository) ListByNotebook(ctx context.Context, notebookID int64, limit int) ([]*entity.GraphEdit, error) {
+	edits := []*entity.GraphEdit{}
+	query := `
+		SELECT ge.* FROM graph_edits ge
+		JOIN documents d ON d.id = ge.document_id
+		WHERE d.notebook_id = $1
+		ORDER BY ge.id DESC
+		LIMIT $2
+	`
+	if err := r.db.SelectContext(ctx, &edits, query, notebookID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list graph edits: %w", err)
+	}
+	return edits, nil
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -17,8 +20,100 @@ type GraphRepository interface {
 	GetNodesByDocumentID(ctx context.Context, docID int64) ([]*entity.Node, error)
 	GetEdgesByDocumentID(ctx context.Context, docID int64) ([]*entity.Edge, error)
 	FindNode(ctx context.Context, docID int64, name, label string) (*entity.Node, error)
+	// SaveGraph atomically replaces the extracted graph of a document. Node IDs passed in are
+	// negative placeholders; edges reference either those placeholders or IDs of existing nodes.
+	// Human-edited nodes and edges of the document are kept, and an existing node with the same
+	// name and label keeps its ID. On success the nodes and edges carry their assigned IDs.
+	SaveGraph(ctx context.Context, docID int64, nodes []*entity.Node, edges []*entity.Edge) error
+	GetNode(ctx context.Context, id int64) (*entity.Node, error)
+	// GetNeighbors walks up to q.Depth hops from a node and returns the reached nodes,
+	// including the start node, and the traversed edges.
+	GetNeighbors(ctx context.Context, nodeID int64, q NeighborQuery) ([]*entity.Node, []*entity.Edge, error)
+	// ShortestPath returns the nodes and edges of a shortest path, in path order,
+	// or empty slices when the nodes are not connected within maxDepth hops. A non-nil
+	// asOf only follows edges valid on that day.
+	ShortestPath(ctx context.Context, fromID, toID int64, direction string, maxDepth int, asOf *entity.Date) ([]*entity.Node, []*entity.Edge, error)
+	// GetSubgraph returns the nodes within depth hops of a node in either direction,
+	// and every edge between them. A non-nil asOf only uses edges valid on that day.
+	GetSubgraph(ctx context.Context, nodeID int64, depth, limit int, asOf *entity.Date) ([]*entity.Node, []*entity.Edge, error)
+	GetNodesByNotebookID(ctx context.Context, notebookID int64) ([]*entity.Node, error)
+	GetEdgesByNotebookID(ctx context.Context, notebookID int64) ([]*entity.Edge, error)
+	// FindNodes returns the nodes of a notebook matching the filter, highest PageRank first.
+	FindNodes(ctx context.Context, notebookID int64, f PropertyFilter) ([]*entity.Node, error)
+	// MatchNodeNames returns the nodes of a notebook whose name fuzzily occurs in text, by
+	// pg_trgm word similarity, most similar first.
+	MatchNodeNames(ctx context.Context, notebookID int64, text string, minSimilarity float64, limit int) ([]*NodeMatch, error)
+	// FindEdges returns the edges of a notebook matching the filter, oldest first.
+	FindEdges(ctx context.Context, notebookID int64, f PropertyFilter) ([]*entity.Edge, error)
+	// UpdateNodeScores stores scores computed over a node's document graph on the node.
+	UpdateNodeScores(ctx context.Context, scores []*entity.NodeScore) error
+	// ReplaceNotebookNodeScores replaces the scores computed over a notebook's whole graph,
+	// which are kept apart from the per-document scores on the nodes.
+	ReplaceNotebookNodeScores(ctx context.Context, notebookID int64, scores []*entity.NodeScore) error
+	// GetNotebookNodeScores returns the notebook-wide scores of a notebook's nodes.
+	GetNotebookNodeScores(ctx context.Context, notebookID int64) ([]*entity.NodeScore, error)
+	// DeleteByDocumentID removes a document's nodes and edges, and edges of other documents
+	// that point at its nodes.
+	DeleteByDocumentID(ctx context.Context, docID int64) error
+
+	// UpdateNode saves a node's label, name, properties and source.
+	UpdateNode(ctx context.Context, node *entity.Node) error
+	// DeleteNode removes a node and every edge attached to it.
+	DeleteNode(ctx context.Context, id int64) error
+	GetEdge(ctx context.Context, id int64) (*entity.Edge, error)
+	// UpdateEdge saves an edge's relation type, properties, source and validity interval.
+	UpdateEdge(ctx context.Context, edge *entity.Edge) error
+	DeleteEdge(ctx context.Context, id int64) error
+	// MergeNodes moves the edges of the source nodes onto the target node and deletes the
+	// sources. Edges between merged nodes and duplicates of another edge are dropped and
+	// returned, with their endpoints as moved; see mergePlan.
+	MergeNodes(ctx context.Context, targetID int64, sourceIDs []int64) ([]*entity.Edge, error)
+	// SplitNode creates each part's node in the document of the split node and moves the
+	// part's edges from the split node to it. On success the parts' nodes carry their IDs.
+	SplitNode(ctx context.Context, nodeID int64, parts []NodeSplit) error
+}
+
+// NodeSplit is one part of a split node: a new node and the edges it takes over.
+type NodeSplit struct {
+	Node    *entity.Node
+	EdgeIDs []int64
+}
+
+// PropertyFilter selects nodes or edges by type and property values.
+type PropertyFilter struct {
+	Type       string            // node label or edge relation type; empty matches every type
+	Properties entity.Properties // entries the properties must contain, as with JSONB @>
+	AsOf       *entity.Date      // edges only: keep edges valid on that day
+	Limit      int
+}
+
+// NodeMatch is a node whose name matched a text, with the trigram word similarity of the
+// name to the best-matching run of words in the text, from 0 to 1.
+type NodeMatch struct {
+	entity.Node
+	Similarity float64 `db:"similarity"`
+}
+
+// Traversal directions relative to the start node.
+const (
+	DirectionOut  = "out"
+	DirectionIn   = "in"
+	DirectionBoth = "both"
+)
+
+// NeighborQuery filters a neighborhood traversal.
+type NeighborQuery struct {
+	Direction     string   // DirectionOut, DirectionIn or DirectionBoth
+	RelationTypes []string // empty matches every relation type
+	Depth         int
+	Limit         int          // maximum number of nodes, closest first
+	AsOf          *entity.Date // only follow edges valid on that day
 }
 
+// insertBatchSize bounds the rows per multi-row INSERT, keeping well below the
+// 65535 bind parameter limit of the Postgres protocol.
+const insertBatchSize = 1000
+
 // PostgresGraphRepository implements GraphRepository using PostgreSQL.
 type PostgresGraphRepository struct {
 	db *sqlx.DB
@@ -31,11 +126,12 @@ func NewPostgresGraphRepository(db *sqlx.DB) *PostgresGraphRepository {
 
 func (r *PostgresGraphRepository) CreateNode(ctx context.Context, node *entity.Node) error {
 	query := `
-		INSERT INTO nodes (document_id, label, name, properties, created_at)
-		VALUES (:document_id, :label, :name, :properties, :created_at)
+		INSERT INTO nodes (document_id, label, name, properties, source, created_at)
+		VALUES (:document_id, :label, :name, :properties, :source, :created_at)
 		RETURNING id
 	`
 	node.CreatedAt = time.Now()
+	node.Source = sourceOrDefault(node.Source)
 
 	rows, err := r.db.NamedQueryContext(ctx, query, node)
 	if err != nil {
@@ -53,11 +149,12 @@ func (r *PostgresGraphRepository) CreateNode(ctx context.Context, node *entity.N
 
 func (r *PostgresGraphRepository) CreateEdge(ctx context.Context, edge *entity.Edge) error {
 	query := `
-		INSERT INTO edges (document_id, source_node_id, target_node_id, relation_type, properties, created_at)
-		VALUES (:document_id, :source_node_id, :target_node_id, :relation_type, :properties, :created_at)
+		INSERT INTO edges (document_id, source_node_id, target_node_id, relation_type, properties, source, valid_from, valid_to, created_at)
+		VALUES (:document_id, :source_node_id, :target_node_id, :relation_type, :properties, :source, :valid_from, :valid_to, :created_at)
 		RETURNING id
 	`
 	edge.CreatedAt = time.Now()
+	edge.Source = sourceOrDefault(edge.Source)
 
 	rows, err := r.db.NamedQueryContext(ctx, query, edge)
 	if err != nil {
@@ -102,3 +199,979 @@ func (r *PostgresGraphRepository) FindNode(ctx context.Context, docID int64, nam
 	}
 	return &node, nil
 }
+
+func (r *PostgresGraphRepository) SaveGraph(ctx context.Context, docID int64, nodes []*entity.Node, edges []*entity.Edge) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Edges first: they reference the nodes being replaced
+	if _, err := tx.ExecContext(ctx, `DELETE FROM edges WHERE document_id = $1 AND source <> $2`, docID, entity.SourceHuman); err != nil {
+		return fmt.Errorf("failed to delete previous edges: %w", err)
+	}
+	var existing []*entity.Node
+	if err := tx.SelectContext(ctx, &existing, `SELECT * FROM nodes WHERE document_id = $1 AND source <> $2`, docID, entity.SourceHuman); err != nil {
+		return fmt.Errorf("failed to list previous nodes: %w", err)
+	}
+	reused, stale := reuseNodeIDs(existing, nodes)
+	if len(stale) > 0 {
+		// Nodes that curators connected are kept, or deleting them would cascade to the edges
+		query := `
+			DELETE FROM nodes n WHERE n.id = ANY($1::bigint[]) AND NOT EXISTS (
+				SELECT 1 FROM edges e WHERE e.source = $2 AND (e.source_node_id = n.id OR e.target_node_id = n.id)
+			)
+		`
+		if _, err := tx.ExecContext(ctx, query, stale, entity.SourceHuman); err != nil {
+			return fmt.Errorf("failed to delete previous nodes: %w", err)
+		}
+	}
+
+	nodeIDs, err := reserveIDs(ctx, tx, "nodes", len(nodes)-len(reused))
+	if err != nil {
+		return err
+	}
+	edgeIDs, err := reserveIDs(ctx, tx, "edges", len(edges))
+	if err != nil {
+		return err
+	}
+
+	assigned, resolved, err := assignGraphIDs(nodes, edges, reused, nodeIDs)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var nodeRows, updateRows [][]interface{}
+	for i, n := range nodes {
+		if _, ok := reused[i]; ok {
+			updateRows = append(updateRows, []interface{}{assigned[i], n.Label, n.Name, n.Properties, sourceOrDefault(n.Source)})
+		} else {
+			nodeRows = append(nodeRows, []interface{}{assigned[i], docID, n.Label, n.Name, n.Properties, sourceOrDefault(n.Source), now})
+		}
+	}
+	edgeRows := make([][]interface{}, len(edges))
+	for i, e := range edges {
+		edgeRows[i] = []interface{}{edgeIDs[i], docID, resolved[i][0], resolved[i][1], e.RelationType, e.Properties, sourceOrDefault(e.Source), e.ValidFrom, e.ValidTo, now}
+	}
+
+	if err := updateNodeRows(ctx, tx, updateRows); err != nil {
+		return fmt.Errorf("failed to update nodes: %w", err)
+	}
+	if err := insertRows(ctx, tx, "nodes", []string{"id", "document_id", "label", "name", "properties", "source", "created_at"}, nodeRows); err != nil {
+		return fmt.Errorf("failed to insert nodes: %w", err)
+	}
+	if err := insertRows(ctx, tx, "edges", []string{"id", "document_id", "source_node_id", "target_node_id", "relation_type", "properties", "source", "valid_from", "valid_to", "created_at"}, edgeRows); err != nil {
+		return fmt.Errorf("failed to insert edges: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit graph: %w", err)
+	}
+
+	// Only expose assigned IDs once they are durable
+	for i, n := range nodes {
+		n.ID = assigned[i]
+		n.DocumentID = docID
+		n.Source = sourceOrDefault(n.Source)
+		if _, ok := reused[i]; !ok {
+			n.CreatedAt = now
+		}
+	}
+	for i, e := range edges {
+		e.ID = edgeIDs[i]
+		e.DocumentID = docID
+		e.SourceNodeID = resolved[i][0]
+		e.TargetNodeID = resolved[i][1]
+		e.Source = sourceOrDefault(e.Source)
+		e.CreatedAt = now
+	}
+	return nil
+}
+
+// reuseNodeIDs matches saved nodes to the previously extracted nodes of a document by name and
+// label, so entities that are extracted again keep their IDs. It returns the matched IDs by
+// index into nodes and the IDs of the previous nodes that were not matched.
+func reuseNodeIDs(existing, nodes []*entity.Node) (map[int]int64, []int64) {
+	byKey := make(map[[2]string][]int64)
+	for _, n := range existing {
+		key := [2]string{n.Name, n.Label}
+		byKey[key] = append(byKey[key], n.ID)
+	}
+	reused := make(map[int]int64)
+	for i, n := range nodes {
+		key := [2]string{n.Name, n.Label}
+		if ids := byKey[key]; len(ids) > 0 {
+			reused[i] = ids[0]
+			byKey[key] = ids[1:]
+		}
+	}
+	stale := []int64{}
+	for _, ids := range byKey {
+		stale = append(stale, ids...)
+	}
+	return reused, stale
+}
+
+// assignGraphIDs gives every node its reused ID or the next of newIDs, in order, and resolves
+// the placeholder endpoints of the edges. Nodes must carry distinct negative placeholders;
+// edges may also reference existing nodes by their positive ID.
+func assignGraphIDs(nodes []*entity.Node, edges []*entity.Edge, reused map[int]int64, newIDs []int64) (assigned []int64, resolved [][2]int64, err error) {
+	idMap := make(map[int64]int64, len(nodes)) // placeholder -> assigned ID
+	assigned = make([]int64, len(nodes))
+	next := 0
+	for i, n := range nodes {
+		if n.ID >= 0 {
+			return nil, nil, fmt.Errorf("node %q must have a negative placeholder ID, got %d", n.Name, n.ID)
+		}
+		if _, dup := idMap[n.ID]; dup {
+			return nil, nil, fmt.Errorf("duplicate node placeholder ID %d", n.ID)
+		}
+		if id, ok := reused[i]; ok {
+			assigned[i] = id
+		} else {
+			assigned[i] = newIDs[next]
+			next++
+		}
+		idMap[n.ID] = assigned[i]
+	}
+
+	resolved = make([][2]int64, len(edges))
+	for i, e := range edges {
+		source, err := resolveNodeID(idMap, e.SourceNodeID)
+		if err != nil {
+			return nil, nil, err
+		}
+		target, err := resolveNodeID(idMap, e.TargetNodeID)
+		if err != nil {
+			return nil, nil, err
+		}
+		resolved[i] = [2]int64{source, target}
+	}
+	return assigned, resolved, nil
+}
+
+// sourceOrDefault treats nodes and edges without a provenance as extracted.
+func sourceOrDefault(source string) string {
+	if source == "" {
+		return entity.SourceLLM
+	}
+	return source
+}
+
+// sqlStatement is a query with its bind arguments.
+type sqlStatement struct {
+	query string
+	args  []interface{}
+}
+
+// updateNodeRows updates label, name, properties and source of nodes given as
+// (id, label, name, properties, source) rows.
+func updateNodeRows(ctx context.Context, tx *sqlx.Tx, rows [][]interface{}) error {
+	for _, stmt := range updateNodeStatements(rows) {
+		if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateNodeStatements builds the UPDATE statements of updateNodeRows, one per
+// insertBatchSize rows.
+func updateNodeStatements(rows [][]interface{}) []sqlStatement {
+	var stmts []sqlStatement
+	for start := 0; start < len(rows); start += insertBatchSize {
+		batch := rows[start:min(start+insertBatchSize, len(rows))]
+
+		var sb strings.Builder
+		args := make([]interface{}, 0, len(batch)*5)
+		sb.WriteString(`
+			UPDATE nodes AS n
+			SET label = v.label, name = v.name, properties = v.properties, source = v.source
+			FROM (VALUES `)
+		for i, row := range batch {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			p := len(args)
+			fmt.Fprintf(&sb, "($%d::bigint, $%d::text, $%d::text, $%d::jsonb, $%d::text)", p+1, p+2, p+3, p+4, p+5)
+			args = append(args, row...)
+		}
+		sb.WriteString(`) AS v(id, label, name, properties, source)
+			WHERE n.id = v.id`)
+		stmts = append(stmts, sqlStatement{query: sb.String(), args: args})
+	}
+	return stmts
+}
+
+// reserveIDs draws n values from the id sequence of a table.
+func reserveIDs(ctx context.Context, tx *sqlx.Tx, table string, n int) ([]int64, error) {
+	ids := make([]int64, 0, n)
+	if n == 0 {
+		return ids, nil
+	}
+	query := `SELECT nextval(pg_get_serial_sequence($1, 'id')) FROM generate_series(1, $2)`
+	if err := tx.SelectContext(ctx, &ids, query, table, n); err != nil {
+		return nil, fmt.Errorf("failed to reserve %s ids: %w", table, err)
+	}
+	return ids, nil
+}
+
+// insertRows inserts rows with multi-row VALUES statements of at most insertBatchSize rows.
+func insertRows(ctx context.Context, tx *sqlx.Tx, table string, columns []string, rows [][]interface{}) error {
+	for _, stmt := range insertStatements(table, columns, rows) {
+		if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertStatements builds the INSERT statements of insertRows.
+func insertStatements(table string, columns []string, rows [][]interface{}) []sqlStatement {
+	var stmts []sqlStatement
+	for start := 0; start < len(rows); start += insertBatchSize {
+		batch := rows[start:min(start+insertBatchSize, len(rows))]
+
+		var sb strings.Builder
+		args := make([]interface{}, 0, len(batch)*len(columns))
+		fmt.Fprintf(&sb, "INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+		for i, row := range batch {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString("(")
+			for j := range row {
+				if j > 0 {
+					sb.WriteString(", ")
+				}
+				fmt.Fprintf(&sb, "$%d", len(args)+j+1)
+			}
+			sb.WriteString(")")
+			args = append(args, row...)
+		}
+		stmts = append(stmts, sqlStatement{query: sb.String(), args: args})
+	}
+	return stmts
+}
+
+// resolveNodeID maps a placeholder to its assigned ID; positive IDs refer to existing nodes.
+func resolveNodeID(idMap map[int64]int64, id int64) (int64, error) {
+	if real, ok := idMap[id]; ok {
+		return real, nil
+	}
+	if id > 0 {
+		return id, nil
+	}
+	return 0, fmt.Errorf("edge references unknown node placeholder %d", id)
+}
+
+func (r *PostgresGraphRepository) GetNode(ctx context.Context, id int64) (*entity.Node, error) {
+	var node entity.Node
+	query := `SELECT * FROM nodes WHERE id = $1`
+	if err := r.db.GetContext(ctx, &node, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+	return &node, nil
+}
+
+func (r *PostgresGraphRepository) GetNeighbors(ctx context.Context, nodeID int64, q NeighborQuery) ([]*entity.Node, []*entity.Edge, error) {
+	join, next, err := traversalJoin(q.Direction, "w.node_id")
+	if err != nil {
+		return nil, nil, err
+	}
+	relationTypes := q.RelationTypes
+	if relationTypes == nil {
+		relationTypes = []string{}
+	}
+
+	query := fmt.Sprintf(`
+		WITH RECURSIVE walk(node_id, edge_id, depth) AS (
+			SELECT $1::bigint, NULL::bigint, 0
+			UNION
+			SELECT %s, e.id, w.depth + 1
+			FROM walk w
+			JOIN edges e ON %s
+			WHERE w.depth < $2 AND (cardinality($3::text[]) = 0 OR e.relation_type = ANY($3::text[]))
+			  AND %s
+		)
+		SELECT node_id, edge_id, depth FROM walk
+	`, next, join, edgeValidAt("$4"))
+
+	var steps []struct {
+		NodeID int64         `db:"node_id"`
+		EdgeID sql.NullInt64 `db:"edge_id"`
+		Depth  int           `db:"depth"`
+	}
+	if err := r.db.SelectContext(ctx, &steps, query, nodeID, q.Depth, relationTypes, q.AsOf); err != nil {
+		return nil, nil, fmt.Errorf("failed to traverse neighbors: %w", err)
+	}
+
+	traversal := make([]traversalStep, len(steps))
+	for i, st := range steps {
+		traversal[i] = traversalStep{NodeID: st.NodeID, Depth: st.Depth}
+		if st.EdgeID.Valid {
+			traversal[i].EdgeIDs = []int64{st.EdgeID.Int64}
+		}
+	}
+	nodeIDs, edgeIDs := closestNodes(traversal, q.Limit)
+
+	nodes, err := r.getNodesByIDs(ctx, nodeIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	edges, err := r.getEdgesByIDs(ctx, edgeIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nodes, edgesWithin(edges, nodeIDs), nil
+}
+
+// ShortestPath searches breadth first with one query per level instead of a recursive CTE:
+// the CTE has to carry the path in each row to avoid cycles, so it enumerated every path up
+// to maxDepth, which explodes on dense graphs. See shortestPath.
+func (r *PostgresGraphRepository) ShortestPath(ctx context.Context, fromID, toID int64, direction string, maxDepth int, asOf *entity.Date) ([]*entity.Node, []*entity.Edge, error) {
+	join, _, err := traversalJoin(direction, "ANY($1)")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := `SELECT e.id, e.source_node_id, e.target_node_id FROM edges e WHERE ` + join + ` AND ` + edgeValidAt("$2")
+	edgesOf := func(ctx context.Context, frontier []int64) ([]pathEdge, error) {
+		edges := []pathEdge{}
+		if err := r.db.SelectContext(ctx, &edges, query, frontier, asOf); err != nil {
+			return nil, fmt.Errorf("failed to find path: %w", err)
+		}
+		return edges, nil
+	}
+	nodeIDs, edgeIDs, err := shortestPath(ctx, fromID, toID, direction, maxDepth, edgesOf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes, err := r.getNodesByIDs(ctx, nodeIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	edges, err := r.getEdgesByIDs(ctx, edgeIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return orderByIDs(nodes, nodeIDs, func(n *entity.Node) int64 { return n.ID }),
+		orderByIDs(edges, edgeIDs, func(e *entity.Edge) int64 { return e.ID }), nil
+}
+
+func (r *PostgresGraphRepository) GetSubgraph(ctx context.Context, nodeID int64, depth, limit int, asOf *entity.Date) ([]*entity.Node, []*entity.Edge, error) {
+	nodes, _, err := r.GetNeighbors(ctx, nodeID, NeighborQuery{Direction: DirectionBoth, Depth: depth, Limit: limit, AsOf: asOf})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodeIDs := make([]int64, len(nodes))
+	for i, n := range nodes {
+		nodeIDs[i] = n.ID
+	}
+
+	edges := []*entity.Edge{}
+	query := `SELECT * FROM edges e WHERE source_node_id = ANY($1) AND target_node_id = ANY($1) AND ` + edgeValidAt("$2")
+	if err := r.db.SelectContext(ctx, &edges, query, nodeIDs, asOf); err != nil {
+		return nil, nil, fmt.Errorf("failed to list subgraph edges: %w", err)
+	}
+	return nodes, edges, nil
+}
+
+func (r *PostgresGraphRepository) getNodesByIDs(ctx context.Context, ids []int64) ([]*entity.Node, error) {
+	nodes := []*entity.Node{}
+	if len(ids) == 0 {
+		return nodes, nil
+	}
+	query := `SELECT * FROM nodes WHERE id = ANY($1)`
+	if err := r.db.SelectContext(ctx, &nodes, query, ids); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	return nodes, nil
+}
+
+func (r *PostgresGraphRepository) getEdgesByIDs(ctx context.Context, ids []int64) ([]*entity.Edge, error) {
+	edges := []*entity.Edge{}
+	if len(ids) == 0 {
+		return edges, nil
+	}
+	query := `SELECT * FROM edges WHERE id = ANY($1)`
+	if err := r.db.SelectContext(ctx, &edges, query, ids); err != nil {
+		return nil, fmt.Errorf("failed to list edges: %w", err)
+	}
+	return edges, nil
+}
+
+// edgeValidAt is the condition that the edge e is valid on the date parameter param,
+// which matches every edge when NULL.
+func edgeValidAt(param string) string {
+	return fmt.Sprintf(`(%[1]s::date IS NULL OR ((e.valid_from IS NULL OR e.valid_from <= %[1]s::date)
+		AND (e.valid_to IS NULL OR e.valid_to >= %[1]s::date)))`, param)
+}
+
+// traversalJoin returns the edge join condition for a direction and the expression
+// for the node on the far side of the edge, relative to the node column cur.
+func traversalJoin(direction, cur string) (join string, next string, err error) {
+	switch direction {
+	case DirectionOut:
+		return fmt.Sprintf("e.source_node_id = %s", cur), "e.target_node_id", nil
+	case DirectionIn:
+		return fmt.Sprintf("e.target_node_id = %s", cur), "e.source_node_id", nil
+	case DirectionBoth, "":
+		return fmt.Sprintf("(e.source_node_id = %[1]s OR e.target_node_id = %[1]s)", cur),
+			fmt.Sprintf("(CASE WHEN e.source_node_id = %s THEN e.target_node_id ELSE e.source_node_id END)", cur), nil
+	default:
+		return "", "", fmt.Errorf("invalid direction: %q", direction)
+	}
+}
+
+// traversalStep is a node reached by a traversal, the edges leading to it and its distance
+// from the start node.
+type traversalStep struct {
+	NodeID  int64
+	EdgeIDs []int64
+	Depth   int
+}
+
+// closestNodes picks the nodes of a traversal closest to the start first, up to limit,
+// and returns them with every traversed edge.
+func closestNodes(steps []traversalStep, limit int) (nodeIDs, edgeIDs []int64) {
+	depths := make(map[int64]int)
+	for _, st := range steps {
+		if d, ok := depths[st.NodeID]; !ok || st.Depth < d {
+			depths[st.NodeID] = st.Depth
+		}
+	}
+	nodeIDs = make([]int64, 0, len(depths))
+	for id := range depths {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool {
+		if depths[nodeIDs[i]] != depths[nodeIDs[j]] {
+			return depths[nodeIDs[i]] < depths[nodeIDs[j]]
+		}
+		return nodeIDs[i] < nodeIDs[j]
+	})
+	if limit > 0 && len(nodeIDs) > limit {
+		nodeIDs = nodeIDs[:limit]
+	}
+
+	edgeIDs = []int64{}
+	seen := make(map[int64]bool)
+	for _, st := range steps {
+		for _, id := range st.EdgeIDs {
+			if !seen[id] {
+				seen[id] = true
+				edgeIDs = append(edgeIDs, id)
+			}
+		}
+	}
+	return nodeIDs, edgeIDs
+}
+
+// edgesWithin keeps the edges whose endpoints are both in nodeIDs.
+func edgesWithin(edges []*entity.Edge, nodeIDs []int64) []*entity.Edge {
+	in := make(map[int64]bool, len(nodeIDs))
+	for _, id := range nodeIDs {
+		in[id] = true
+	}
+	kept := []*entity.Edge{}
+	for _, e := range edges {
+		if in[e.SourceNodeID] && in[e.TargetNodeID] {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// pathEdge is an edge considered by shortestPath.
+type pathEdge struct {
+	ID     int64 `db:"id"`
+	Source int64 `db:"source_node_id"`
+	Target int64 `db:"target_node_id"`
+}
+
+// shortestPath searches breadth first from fromID, loading the edges of a whole frontier per
+// call to edgesOf. Every node is visited once and the search stops at the first level that
+// reaches toID, so dense graphs cost one query per level rather than one row per path. It
+// returns the node and edge IDs of the path in order, both empty when toID is not reachable
+// within maxDepth.
+func shortestPath(ctx context.Context, fromID, toID int64, direction string, maxDepth int, edgesOf func(ctx context.Context, frontier []int64) ([]pathEdge, error)) (nodeIDs, edgeIDs []int64, err error) {
+	if fromID == toID {
+		return []int64{fromID}, []int64{}, nil
+	}
+
+	type hop struct{ prev, edge int64 }
+	visited := map[int64]hop{fromID: {}}
+	frontier := []int64{fromID}
+	for depth := 0; depth < maxDepth && len(frontier) > 0; depth++ {
+		edges, err := edgesOf(ctx, frontier)
+		if err != nil {
+			return nil, nil, err
+		}
+		sort.Slice(edges, func(i, j int) bool { return edges[i].ID < edges[j].ID })
+		inFrontier := make(map[int64]bool, len(frontier))
+		for _, id := range frontier {
+			inFrontier[id] = true
+		}
+
+		next := []int64{}
+		for _, e := range edges {
+			for _, step := range [][2]int64{{e.Source, e.Target}, {e.Target, e.Source}} {
+				from, to := step[0], step[1]
+				if !inFrontier[from] || (direction == DirectionOut && from != e.Source) || (direction == DirectionIn && from != e.Target) {
+					continue
+				}
+				if _, ok := visited[to]; ok {
+					continue
+				}
+				visited[to] = hop{prev: from, edge: e.ID}
+				if to != toID {
+					next = append(next, to)
+					continue
+				}
+
+				nodeIDs = []int64{toID}
+				for id := toID; id != fromID; id = visited[id].prev {
+					nodeIDs = append(nodeIDs, visited[id].prev)
+					edgeIDs = append(edgeIDs, visited[id].edge)
+				}
+				slices.Reverse(nodeIDs)
+				slices.Reverse(edgeIDs)
+				return nodeIDs, edgeIDs, nil
+			}
+		}
+		frontier = next
+	}
+	return []int64{}, []int64{}, nil
+}
+
+// walkNeighbors walks breadth first from nodeID up to maxDepth, loading the edges of a whole
+// frontier per call to edgesOf like shortestPath. Every node is expanded once, at its
+// distance from the start, and every edge leaving an expanded node in direction is
+// returned as a step for closestNodes.
+func walkNeighbors(ctx context.Context, nodeID int64, direction string, maxDepth int, edgesOf func(ctx context.Context, frontier []int64) ([]pathEdge, error)) ([]traversalStep, error) {
+	steps := []traversalStep{{NodeID: nodeID}}
+	visited := map[int64]bool{nodeID: true}
+	frontier := []int64{nodeID}
+	for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+		edges, err := edgesOf(ctx, frontier)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(edges, func(i, j int) bool { return edges[i].ID < edges[j].ID })
+		inFrontier := make(map[int64]bool, len(frontier))
+		for _, id := range frontier {
+			inFrontier[id] = true
+		}
+
+		next := []int64{}
+		for _, e := range edges {
+			for _, step := range [][2]int64{{e.Source, e.Target}, {e.Target, e.Source}} {
+				from, to := step[0], step[1]
+				if !inFrontier[from] || (direction == DirectionOut && from != e.Source) || (direction == DirectionIn && from != e.Target) {
+					continue
+				}
+				steps = append(steps, traversalStep{NodeID: to, EdgeIDs: []int64{e.ID}, Depth: depth})
+				if !visited[to] {
+					visited[to] = true
+					next = append(next, to)
+				}
+			}
+		}
+		frontier = next
+	}
+	return steps, nil
+}
+
+// orderByIDs returns items in the order of ids.
+func orderByIDs[T any](items []T, ids []int64, id func(T) int64) []T {
+	byID := make(map[int64]T, len(items))
+	for _, item := range items {
+		byID[id(item)] = item
+	}
+	ordered := make([]T, 0, len(ids))
+	for _, i := range ids {
+		if item, ok := byID[i]; ok {
+			ordered = append(ordered, item)
+		}
+	}
+	return ordered
+}
+
+// GetNodesByNotebookID retrieves the nodes of all non-deleted documents in a notebook.
+func (r *PostgresGraphRepository) GetNodesByNotebookID(ctx context.Context, notebookID int64) ([]*entity.Node, error) {
+	nodes := []*entity.Node{}
+	query := `
+		SELECT n.* FROM nodes n
+		JOIN documents d ON d.id = n.document_id
+		WHERE d.notebook_id = $1 AND d.is_deleted = false
+	`
+	if err := r.db.SelectContext(ctx, &nodes, query, notebookID); err != nil {
+		return nil, fmt.Errorf("failed to list notebook nodes: %w", err)
+	}
+	return nodes, nil
+}
+
+// GetEdgesByNotebookID retrieves the edges of all non-deleted documents in a notebook.
+func (r *PostgresGraphRepository) GetEdgesByNotebookID(ctx context.Context, notebookID int64) ([]*entity.Edge, error) {
+	edges := []*entity.Edge{}
+	query := `
+		SELECT e.* FROM edges e
+		JOIN documents d ON d.id = e.document_id
+		WHERE d.notebook_id = $1 AND d.is_deleted = false
+	`
+	if err := r.db.SelectContext(ctx, &edges, query, notebookID); err != nil {
+		return nil, fmt.Errorf("failed to list notebook edges: %w", err)
+	}
+	return edges, nil
+}
+
+func (r *PostgresGraphRepository) FindNodes(ctx context.Context, notebookID int64, f PropertyFilter) ([]*entity.Node, error) {
+	nodes := []*entity.Node{}
+	query := `
+		SELECT n.* FROM nodes n
+		JOIN documents d ON d.id = n.document_id
+		WHERE d.notebook_id = $1 AND d.is_deleted = false
+		  AND ($2 = '' OR n.label = $2) AND n.properties @> $3::jsonb
+		ORDER BY n.pagerank DESC, n.id
+		LIMIT $4
+	`
+	if err := r.db.SelectContext(ctx, &nodes, query, notebookID, f.Type, f.Properties, f.Limit); err != nil {
+		return nil, fmt.Errorf("failed to find nodes: %w", err)
+	}
+	return nodes, nil
+}
+
+func (r *PostgresGraphRepository) MatchNodeNames(ctx context.Context, notebookID int64, text string, minSimilarity float64, limit int) ([]*NodeMatch, error) {
+	matches := []*NodeMatch{}
+	query := `
+		SELECT n.*, word_similarity(n.name, $2) AS similarity FROM nodes n
+		JOIN documents d ON d.id = n.document_id
+		WHERE d.notebook_id = $1 AND d.is_deleted = false AND word_similarity(n.name, $2) >= $3
+		ORDER BY similarity DESC, n.pagerank DESC, n.id
+		LIMIT $4
+	`
+	if err := r.db.SelectContext(ctx, &matches, query, notebookID, text, minSimilarity, limit); err != nil {
+		return nil, fmt.Errorf("failed to match node names: %w", err)
+	}
+	return matches, nil
+}
+
+func (r *PostgresGraphRepository) FindEdges(ctx context.Context, notebookID int64, f PropertyFilter) ([]*entity.Edge, error) {
+	edges := []*entity.Edge{}
+	query := `
+		SELECT e.* FROM edges e
+		JOIN documents d ON d.id = e.document_id
+		WHERE d.notebook_id = $1 AND d.is_deleted = false
+		  AND ($2 = '' OR e.relation_type = $2) AND e.properties @> $3::jsonb AND ` + edgeValidAt("$5") + `
+		ORDER BY e.id
+		LIMIT $4
+	`
+	if err := r.db.SelectContext(ctx, &edges, query, notebookID, f.Type, f.Properties, f.Limit, f.AsOf); err != nil {
+		return nil, fmt.Errorf("failed to find edges: %w", err)
+	}
+	return edges, nil
+}
+
+// UpdateNodeScores stores analytics scores on nodes in one transaction.
+func (r *PostgresGraphRepository) UpdateNodeScores(ctx context.Context, scores []*entity.NodeScore) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(scores); start += insertBatchSize {
+		batch := scores[start:min(start+insertBatchSize, len(scores))]
+
+		var sb strings.Builder
+		args := make([]interface{}, 0, len(batch)*5)
+		sb.WriteString(`
+			UPDATE nodes AS n
+			SET pagerank = v.pagerank, degree_centrality = v.degree_centrality,
+				betweenness = v.betweenness, community_id = v.community_id
+			FROM (VALUES `)
+		for i, sc := range batch {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			p := len(args)
+			fmt.Fprintf(&sb, "($%d::bigint, $%d::double precision, $%d::double precision, $%d::double precision, $%d::bigint)", p+1, p+2, p+3, p+4, p+5)
+			args = append(args, sc.NodeID, sc.PageRank, sc.DegreeCentrality, sc.Betweenness, sc.CommunityID)
+		}
+		sb.WriteString(`) AS v(id, pagerank, degree_centrality, betweenness, community_id)
+			WHERE n.id = v.id`)
+
+		if _, err := tx.ExecContext(ctx, sb.String(), args...); err != nil {
+			return fmt.Errorf("failed to update node scores: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceNotebookNodeScores deletes a notebook's scores and inserts new ones in one transaction.
+func (r *PostgresGraphRepository) ReplaceNotebookNodeScores(ctx context.Context, notebookID int64, scores []*entity.NodeScore) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM notebook_node_scores WHERE notebook_id = $1`, notebookID); err != nil {
+		return fmt.Errorf("failed to delete notebook node scores: %w", err)
+	}
+	for start := 0; start < len(scores); start += insertBatchSize {
+		batch := scores[start:min(start+insertBatchSize, len(scores))]
+
+		var sb strings.Builder
+		args := make([]interface{}, 0, len(batch)*6)
+		sb.WriteString(`
+			INSERT INTO notebook_node_scores (notebook_id, node_id, pagerank, degree_centrality, betweenness, community_id)
+			VALUES `)
+		for i, sc := range batch {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			p := len(args)
+			fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d)", p+1, p+2, p+3, p+4, p+5, p+6)
+			args = append(args, notebookID, sc.NodeID, sc.PageRank, sc.DegreeCentrality, sc.Betweenness, sc.CommunityID)
+		}
+
+		if _, err := tx.ExecContext(ctx, sb.String(), args...); err != nil {
+			return fmt.Errorf("failed to insert notebook node scores: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetNotebookNodeScores retrieves the notebook-wide scores of a notebook's nodes.
+func (r *PostgresGraphRepository) GetNotebookNodeScores(ctx context.Context, notebookID int64) ([]*entity.NodeScore, error) {
+	scores := []*entity.NodeScore{}
+	query := `
+		SELECT node_id, pagerank, degree_centrality, betweenness, community_id
+		FROM notebook_node_scores WHERE notebook_id = $1
+	`
+	if err := r.db.SelectContext(ctx, &scores, query, notebookID); err != nil {
+		return nil, fmt.Errorf("failed to get notebook node scores: %w", err)
+	}
+	return scores, nil
+}
+
+func (r *PostgresGraphRepository) DeleteByDocumentID(ctx context.Context, docID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM edges WHERE document_id = $1`, docID); err != nil {
+		return fmt.Errorf("failed to delete edges: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM nodes WHERE document_id = $1`, docID); err != nil {
+		return fmt.Errorf("failed to delete nodes: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresGraphRepository) UpdateNode(ctx context.Context, node *entity.Node) error {
+	query := `UPDATE nodes SET label = $1, name = $2, properties = $3, source = $4 WHERE id = $5`
+	node.Source = sourceOrDefault(node.Source)
+	if _, err := r.db.ExecContext(ctx, query, node.Label, node.Name, node.Properties, node.Source, node.ID); err != nil {
+		return fmt.Errorf("failed to update node: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresGraphRepository) DeleteNode(ctx context.Context, id int64) error {
+	// Attached edges go with the node through ON DELETE CASCADE
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM nodes WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete node: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresGraphRepository) GetEdge(ctx context.Context, id int64) (*entity.Edge, error) {
+	var edge entity.Edge
+	query := `SELECT * FROM edges WHERE id = $1`
+	if err := r.db.GetContext(ctx, &edge, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get edge: %w", err)
+	}
+	return &edge, nil
+}
+
+func (r *PostgresGraphRepository) UpdateEdge(ctx context.Context, edge *entity.Edge) error {
+	query := `UPDATE edges SET relation_type = $1, properties = $2, source = $3, valid_from = $4, valid_to = $5 WHERE id = $6`
+	edge.Source = sourceOrDefault(edge.Source)
+	if _, err := r.db.ExecContext(ctx, query, edge.RelationType, edge.Properties, edge.Source, edge.ValidFrom, edge.ValidTo, edge.ID); err != nil {
+		return fmt.Errorf("failed to update edge: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresGraphRepository) DeleteEdge(ctx context.Context, id int64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM edges WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete edge: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresGraphRepository) MergeNodes(ctx context.Context, targetID int64, sourceIDs []int64) ([]*entity.Edge, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	merged := append([]int64{targetID}, sourceIDs...)
+	var edges []*entity.Edge
+	query := `
+		SELECT * FROM edges
+		WHERE source_node_id = ANY($1::bigint[]) OR target_node_id = ANY($1::bigint[])
+		ORDER BY id FOR UPDATE
+	`
+	if err := tx.SelectContext(ctx, &edges, query, merged); err != nil {
+		return nil, fmt.Errorf("failed to get merged edges: %w", err)
+	}
+	_, dropped := mergePlan(edges, targetID, sourceIDs)
+	droppedIDs := make([]int64, len(dropped))
+	for i, e := range dropped {
+		droppedIDs[i] = e.ID
+	}
+
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`DELETE FROM edges WHERE id = ANY($1::bigint[])`, []interface{}{droppedIDs}},
+		{`UPDATE edges SET source_node_id = $1 WHERE source_node_id = ANY($2::bigint[])`, []interface{}{targetID, sourceIDs}},
+		{`UPDATE edges SET target_node_id = $1 WHERE target_node_id = ANY($2::bigint[])`, []interface{}{targetID, sourceIDs}},
+		{`DELETE FROM nodes WHERE id = ANY($1::bigint[])`, []interface{}{sourceIDs}},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return nil, fmt.Errorf("failed to merge nodes: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit merge: %w", err)
+	}
+	return dropped, nil
+}
+
+// mergePlan decides the fate of the edges attached to nodes merged into targetID, ordered by
+// ID. Edges of source nodes get the target as endpoint; those that move are returned in
+// moved. Edges that would become self-loops are dropped, as are duplicates: edges that
+// now connect the same nodes with the same relation over the same validity interval. Of
+// duplicates, a curated edge is kept over extracted ones, then the oldest.
+func mergePlan(edges []*entity.Edge, targetID int64, sourceIDs []int64) (moved, dropped []*entity.Edge) {
+	isSource := make(map[int64]bool, len(sourceIDs))
+	for _, id := range sourceIDs {
+		isSource[id] = true
+	}
+
+	type mergedEdge struct {
+		edge    *entity.Edge
+		rewired bool
+		key     string
+	}
+	var candidates []mergedEdge
+	keep := make(map[string]*entity.Edge)
+	for _, e := range edges {
+		wasLoop := e.SourceNodeID == targetID && e.TargetNodeID == targetID
+		rewired := isSource[e.SourceNodeID] || isSource[e.TargetNodeID]
+		if isSource[e.SourceNodeID] {
+			e.SourceNodeID = targetID
+		}
+		if isSource[e.TargetNodeID] {
+			e.TargetNodeID = targetID
+		}
+		if e.SourceNodeID == targetID && e.TargetNodeID == targetID && !wasLoop {
+			dropped = append(dropped, e)
+			continue
+		}
+
+		key := fmt.Sprintf("%d|%d|%s|%s|%s", e.SourceNodeID, e.TargetNodeID, e.RelationType, dateKey(e.ValidFrom), dateKey(e.ValidTo))
+		if k, ok := keep[key]; !ok || (e.Source == entity.SourceHuman && k.Source != entity.SourceHuman) {
+			keep[key] = e
+		}
+		candidates = append(candidates, mergedEdge{edge: e, rewired: rewired, key: key})
+	}
+
+	for _, c := range candidates {
+		switch {
+		case keep[c.key] != c.edge:
+			dropped = append(dropped, c.edge)
+		case c.rewired:
+			moved = append(moved, c.edge)
+		}
+	}
+	sort.Slice(dropped, func(i, j int) bool { return dropped[i].ID < dropped[j].ID })
+	return moved, dropped
+}
+
+func dateKey(d *entity.Date) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
+
+func (r *PostgresGraphRepository) SplitNode(ctx context.Context, nodeID int64, parts []NodeSplit) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var docID int64
+	if err := tx.GetContext(ctx, &docID, `SELECT document_id FROM nodes WHERE id = $1`, nodeID); err != nil {
+		return fmt.Errorf("failed to get split node: %w", err)
+	}
+
+	now := time.Now()
+	ids := make([]int64, len(parts))
+	for i, part := range parts {
+		n := part.Node
+		query := `
+			INSERT INTO nodes (document_id, label, name, properties, source, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`
+		if err := tx.GetContext(ctx, &ids[i], query, docID, n.Label, n.Name, n.Properties, sourceOrDefault(n.Source), now); err != nil {
+			return fmt.Errorf("failed to create split node: %w", err)
+		}
+
+		query = `
+			UPDATE edges
+			SET source_node_id = CASE WHEN source_node_id = $1 THEN $2 ELSE source_node_id END,
+				target_node_id = CASE WHEN target_node_id = $1 THEN $2 ELSE target_node_id END
+			WHERE id = ANY($3::bigint[]) AND (source_node_id = $1 OR target_node_id = $1)
+		`
+		if _, err := tx.ExecContext(ctx, query, nodeID, ids[i], part.EdgeIDs); err != nil {
+			return fmt.Errorf("failed to move edges: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit split: %w", err)
+	}
+	for i, part := range parts {
+		part.Node.ID = ids[i]
+		part.Node.DocumentID = docID
+		part.Node.Source = sourceOrDefault(part.Node.Source)
+		part.Node.CreatedAt = now
+	}
+	return nil
+}
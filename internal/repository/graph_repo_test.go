@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+func TestShortestPathDenseGraph(t *testing.T) {
+	// A clique of 40 nodes, with a chain 40 -> 100 -> 101 -> 102 leading out of it. The clique
+	// has billions of simple paths of length 6, which enumerating paths would walk through.
+	var edges []pathEdge
+	nextID := int64(1)
+	addEdge := func(source, target int64) {
+		edges = append(edges, pathEdge{ID: nextID, Source: source, Target: target})
+		nextID++
+	}
+	for a := int64(1); a <= 40; a++ {
+		for b := int64(1); b <= 40; b++ {
+			if a != b {
+				addEdge(a, b)
+			}
+		}
+	}
+	addEdge(40, 100)
+	addEdge(101, 100) // points against the chain
+	addEdge(101, 102)
+
+	expanded := make(map[int64]int)
+	queries := 0
+	edgesOf := func(ctx context.Context, frontier []int64) ([]pathEdge, error) {
+		queries++
+		var found []pathEdge
+		for _, id := range frontier {
+			expanded[id]++
+		}
+		for _, e := range edges {
+			if slices.Contains(frontier, e.Source) || slices.Contains(frontier, e.Target) {
+				found = append(found, e)
+			}
+		}
+		return found, nil
+	}
+
+	nodeIDs, edgeIDs, err := shortestPath(context.Background(), 1, 102, DirectionBoth, 6, edgesOf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 40, 100, 101, 102}; !slices.Equal(nodeIDs, want) {
+		t.Errorf("expected path %v, got %v", want, nodeIDs)
+	}
+	if len(edgeIDs) != 4 {
+		t.Errorf("expected 4 edges, got %v", edgeIDs)
+	}
+	if queries != 4 {
+		t.Errorf("expected one query per level, got %d", queries)
+	}
+	for id, n := range expanded {
+		if n > 1 {
+			t.Errorf("node %d expanded %d times", id, n)
+		}
+	}
+
+	// Following edge direction, 100 -> 101 does not exist
+	nodeIDs, _, err = shortestPath(context.Background(), 1, 102, DirectionOut, 6, edgesOf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodeIDs) != 0 {
+		t.Errorf("expected no outgoing path, got %v", nodeIDs)
+	}
+}
+
+func TestWalkNeighborsDenseGraph(t *testing.T) {
+	// A clique of 40 nodes with a node 100 hanging off node 40
+	var edges []pathEdge
+	for a := int64(1); a <= 40; a++ {
+		for b := int64(1); b <= 40; b++ {
+			if a != b {
+				edges = append(edges, pathEdge{ID: int64(len(edges) + 1), Source: a, Target: b})
+			}
+		}
+	}
+	edges = append(edges, pathEdge{ID: int64(len(edges) + 1), Source: 100, Target: 40})
+
+	expanded := make(map[int64]int)
+	queries := 0
+	edgesOf := func(ctx context.Context, frontier []int64) ([]pathEdge, error) {
+		queries++
+		var found []pathEdge
+		for _, id := range frontier {
+			expanded[id]++
+		}
+		for _, e := range edges {
+			if slices.Contains(frontier, e.Source) || slices.Contains(frontier, e.Target) {
+				found = append(found, e)
+			}
+		}
+		return found, nil
+	}
+
+	steps, err := walkNeighbors(context.Background(), 1, DirectionBoth, 6, edgesOf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeIDs, edgeIDs := closestNodes(steps, 0)
+	if len(nodeIDs) != 41 || len(edgeIDs) != len(edges) {
+		t.Errorf("expected 41 nodes and %d edges, got %d and %d", len(edges), len(nodeIDs), len(edgeIDs))
+	}
+	if queries != 3 {
+		t.Errorf("expected one query per level until the graph is exhausted, got %d", queries)
+	}
+	for id, n := range expanded {
+		if n > 1 {
+			t.Errorf("node %d expanded %d times", id, n)
+		}
+	}
+	if nodeIDs, _ = closestNodes(steps, 40); slices.Contains(nodeIDs, 100) {
+		t.Errorf("expected the farthest node to be cut by the limit, got %v", nodeIDs)
+	}
+
+	// Following edge direction, 100 is not reachable from the clique
+	steps, err = walkNeighbors(context.Background(), 1, DirectionOut, 6, edgesOf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodeIDs, _ = closestNodes(steps, 0); len(nodeIDs) != 40 {
+		t.Errorf("expected the 40 clique nodes, got %v", nodeIDs)
+	}
+}
+
+func TestMergePlan(t *testing.T) {
+	// Node 2 is merged into node 1, both related to node 3
+	from := entity.NewDate(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	edges := []*entity.Edge{
+		{ID: 1, SourceNodeID: 1, TargetNodeID: 3, RelationType: "KNOWS", Source: entity.SourceLLM},
+		{ID: 2, SourceNodeID: 2, TargetNodeID: 3, RelationType: "KNOWS", Source: entity.SourceHuman},
+		{ID: 3, SourceNodeID: 2, TargetNodeID: 3, RelationType: "KNOWS", Source: entity.SourceLLM, ValidFrom: &from},
+		{ID: 4, SourceNodeID: 2, TargetNodeID: 1, RelationType: "KNOWS"},
+		{ID: 5, SourceNodeID: 1, TargetNodeID: 1, RelationType: "SELF"},
+	}
+
+	moved, dropped := mergePlan(edges, 1, []int64{2})
+	ids := func(edges []*entity.Edge) []int64 {
+		var ids []int64
+		for _, e := range edges {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+	// The curated duplicate is kept over the older extracted one, the dated edge is no
+	// duplicate, the new loop is dropped and the existing loop is left alone
+	if got := ids(moved); !slices.Equal(got, []int64{2, 3}) {
+		t.Errorf("moved edges = %v, want [2 3]", got)
+	}
+	if got := ids(dropped); !slices.Equal(got, []int64{1, 4}) {
+		t.Errorf("dropped edges = %v, want [1 4]", got)
+	}
+	for _, e := range moved {
+		if e.SourceNodeID != 1 {
+			t.Errorf("moved edge %d starts at node %d, want 1", e.ID, e.SourceNodeID)
+		}
+	}
+}
+
+func TestReuseNodeIDs(t *testing.T) {
+	existing := []*entity.Node{
+		{ID: 10, Name: "Alice", Label: "Person"},
+		{ID: 11, Name: "Alice", Label: "Person"},
+		{ID: 12, Name: "Paris", Label: "Place"},
+		{ID: 13, Name: "Bob", Label: "Person"},
+	}
+	nodes := []*entity.Node{
+		{ID: -1, Name: "Alice", Label: "Person"},
+		{ID: -2, Name: "Paris", Label: "City"}, // relabelled, so a new entity
+		{ID: -3, Name: "Alice", Label: "Person"},
+		{ID: -4, Name: "Alice", Label: "Person"},
+	}
+
+	reused, stale := reuseNodeIDs(existing, nodes)
+	if len(reused) != 2 || reused[0] != 10 || reused[2] != 11 {
+		t.Errorf("expected nodes 0 and 2 to reuse IDs 10 and 11, got %v", reused)
+	}
+	slices.Sort(stale)
+	if !slices.Equal(stale, []int64{12, 13}) {
+		t.Errorf("expected stale nodes [12 13], got %v", stale)
+	}
+}
+
+func TestAssignGraphIDs(t *testing.T) {
+	nodes := []*entity.Node{{ID: -1, Name: "a"}, {ID: -2, Name: "b"}, {ID: -3, Name: "c"}}
+	edges := []*entity.Edge{
+		{SourceNodeID: -1, TargetNodeID: -2},
+		{SourceNodeID: -3, TargetNodeID: 7}, // an existing node of another document
+	}
+	assigned, resolved, err := assignGraphIDs(nodes, edges, map[int]int64{1: 50}, []int64{100, 101})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(assigned, []int64{100, 50, 101}) {
+		t.Errorf("expected IDs [100 50 101], got %v", assigned)
+	}
+	if resolved[0] != [2]int64{100, 50} || resolved[1] != [2]int64{101, 7} {
+		t.Errorf("expected edges 100 -> 50 and 101 -> 7, got %v", resolved)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		nodes []*entity.Node
+		edges []*entity.Edge
+	}{
+		{"positive node ID", []*entity.Node{{ID: 1}}, nil},
+		{"duplicate placeholder", []*entity.Node{{ID: -1}, {ID: -1}}, nil},
+		{"unknown placeholder", []*entity.Node{{ID: -1}}, []*entity.Edge{{SourceNodeID: -1, TargetNodeID: -2}}},
+	} {
+		if _, _, err := assignGraphIDs(tc.nodes, tc.edges, nil, []int64{100, 101}); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
+
+func TestInsertStatementsBatches(t *testing.T) {
+	columns := []string{"id", "name", "source"}
+	rows := make([][]interface{}, 2*insertBatchSize+1)
+	for i := range rows {
+		rows[i] = []interface{}{int64(i), "n", "llm"}
+	}
+
+	stmts := insertStatements("nodes", columns, rows)
+	if len(stmts) != 3 {
+		t.Fatalf("expected 3 statements, got %d", len(stmts))
+	}
+	for i, want := range []int{insertBatchSize, insertBatchSize, 1} {
+		stmt := stmts[i]
+		if len(stmt.args) != want*len(columns) {
+			t.Errorf("statement %d: expected %d args, got %d", i, want*len(columns), len(stmt.args))
+		}
+		if n := strings.Count(stmt.query, "("); n != want+1 {
+			t.Errorf("statement %d: expected %d rows, got %d", i, want, n-1)
+		}
+		if last := fmt.Sprintf("$%d)", len(stmt.args)); !strings.HasSuffix(stmt.query, last) {
+			t.Errorf("statement %d: expected the last parameter to be %s", i, last)
+		}
+	}
+	if stmts[1].args[0] != int64(insertBatchSize) || stmts[2].args[0] != int64(2*insertBatchSize) {
+		t.Errorf("expected batches to continue where the previous one stopped, got %v and %v", stmts[1].args[0], stmts[2].args[0])
+	}
+
+	updateRows := make([][]interface{}, insertBatchSize+1)
+	for i := range updateRows {
+		updateRows[i] = []interface{}{int64(i), "Person", "n", "{}", "llm"}
+	}
+	updates := updateNodeStatements(updateRows)
+	if len(updates) != 2 || len(updates[0].args) != insertBatchSize*5 || len(updates[1].args) != 5 {
+		t.Fatalf("expected updates of %d and 1 rows, got %d statements", insertBatchSize, len(updates))
+	}
+	if last := fmt.Sprintf("$%d::text)", insertBatchSize*5); !strings.Contains(updates[0].query, last) {
+		t.Errorf("expected the last parameter to be %s", last)
+	}
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+// MentionRepository stores the evidence linking graph entities to source chunks.
+type MentionRepository interface {
+	// ReplaceByDocument atomically replaces the mentions of a document.
+	ReplaceByDocument(ctx context.Context, docID int64, mentions []*entity.Mention) error
+	ListByDocument(ctx context.Context, docID int64) ([]*entity.Mention, error)
+	ListByNode(ctx context.Context, nodeID int64) ([]*entity.Mention, error)
+	ListByEdge(ctx context.Context, edgeID int64) ([]*entity.Mention, error)
+	// ListByChunks returns the mentions located in the given chunks.
+	ListByChunks(ctx context.Context, chunkIDs []string) ([]*entity.Mention, error)
+	// ListByEntities returns the mentions of the given nodes and edges.
+	ListByEntities(ctx context.Context, nodeIDs, edgeIDs []int64) ([]*entity.Mention, error)
+	// ReassignNodes moves the mentions of the given nodes to another node.
+	ReassignNodes(ctx context.Context, fromIDs []int64, toID int64) error
+}
+
+type PostgresMentionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresMentionRepository(db *sqlx.DB) *PostgresMentionRepository {
+	return &PostgresMentionRepository{db: db}
+}
+
+const selectMentions = `
+	SELECT m.*, c.chunk_index FROM mentions m
+	JOIN chunks c ON c.id = m.chunk_id
+`
+
+func (r *PostgresMentionRepository) ReplaceByDocument(ctx context.Context, docID int64, mentions []*entity.Mention) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mentions WHERE document_id = $1`, docID); err != nil {
+		return fmt.Errorf("failed to delete mentions: %w", err)
+	}
+
+	columns := []string{"document_id", "node_id", "edge_id", "chunk_id", "start_offset", "end_offset", "quote"}
+	rows := make([][]interface{}, len(mentions))
+	for i, m := range mentions {
+		rows[i] = []interface{}{docID, m.NodeID, m.EdgeID, m.ChunkID, m.StartOffset, m.EndOffset, m.Quote}
+	}
+	if err := insertRows(ctx, tx, "mentions", columns, rows); err != nil {
+		return fmt.Errorf("failed to insert mentions: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresMentionRepository) ListByDocument(ctx context.Context, docID int64) ([]*entity.Mention, error) {
+	return r.list(ctx, selectMentions+`WHERE m.document_id = $1 ORDER BY m.id`, docID)
+}
+
+func (r *PostgresMentionRepository) ListByNode(ctx context.Context, nodeID int64) ([]*entity.Mention, error) {
+	return r.list(ctx, selectMentions+`WHERE m.node_id = $1 ORDER BY m.document_id, c.chunk_index, m.start_offset`, nodeID)
+}
+
+func (r *PostgresMentionRepository) ListByEdge(ctx context.Context, edgeID int64) ([]*entity.Mention, error) {
+	return r.list(ctx, selectMentions+`WHERE m.edge_id = $1 ORDER BY m.document_id, c.chunk_index, m.start_offset`, edgeID)
+}
+
+func (r *PostgresMentionRepository) ListByChunks(ctx context.Context, chunkIDs []string) ([]*entity.Mention, error) {
+	if len(chunkIDs) == 0 {
+		return []*entity.Mention{}, nil
+	}
+	return r.list(ctx, selectMentions+`WHERE m.chunk_id = ANY($1::uuid[]) ORDER BY m.id`, chunkIDs)
+}
+
+func (r *PostgresMentionRepository) ListByEntities(ctx context.Context, nodeIDs, edgeIDs []int64) ([]*entity.Mention, error) {
+	if len(nodeIDs) == 0 && len(edgeIDs) == 0 {
+		return []*entity.Mention{}, nil
+	}
+	if nodeIDs == nil {
+		nodeIDs = []int64{}
+	}
+	if edgeIDs == nil {
+		edgeIDs = []int64{}
+	}
+	query := selectMentions + `WHERE m.node_id = ANY($1::bigint[]) OR m.edge_id = ANY($2::bigint[]) ORDER BY m.id`
+	return r.list(ctx, query, nodeIDs, edgeIDs)
+}
+
+func (r *PostgresMentionRepository) ReassignNodes(ctx context.Context, fromIDs []int64, toID int64) error {
+	query := `UPDATE mentions SET node_id = $1 WHERE node_id = ANY($2::bigint[])`
+	if _, err := r.db.ExecContext(ctx, query, toID, fromIDs); err != nil {
+		return fmt.Errorf("failed to reassign mentions: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresMentionRepository) list(ctx context.Context, query string, args ...interface{}) ([]*entity.Mention, error) {
+	mentions := []*entity.Mention{}
+	if err := r.db.SelectContext(ctx, &mentions, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list mentions: %w", err)
+	}
+	return mentions, nil
+}
@@ -0,0 +1,1173 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+// Neo4jConfig holds the connection settings of a Neo4j server.
+type Neo4jConfig struct {
+	URI      string
+	Username string
+	Password string
+	Database string // empty selects the server's default database
+}
+
+// Neo4jGraphRepository implements GraphRepository using Neo4j.
+//
+// Nodes are stored as :Entity nodes and edges as :RELATES relationships, both carrying the
+// same fields as the Postgres rows. IDs are int64 values drawn from :Sequence counter nodes so
+// they stay compatible with the rest of the schema. Documents live in Postgres, so notebook
+// lookups resolve the notebook's documents through the DocumentRepository.
+type Neo4jGraphRepository struct {
+	driver   neo4j.DriverWithContext
+	database string
+	docRepo  DocumentRepository
+}
+
+// NewNeo4jGraphRepository connects to Neo4j and ensures the constraints and indexes exist.
+func NewNeo4jGraphRepository(ctx context.Context, cfg Neo4jConfig, docRepo DocumentRepository) (*Neo4jGraphRepository, error) {
+	driver, err := neo4j.NewDriverWithContext(cfg.URI, neo4j.BasicAuth(cfg.Username, cfg.Password, ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create neo4j driver: %w", err)
+	}
+	if err := driver.VerifyConnectivity(ctx); err != nil {
+		driver.Close(ctx)
+		return nil, fmt.Errorf("failed to connect to neo4j: %w", err)
+	}
+
+	r := &Neo4jGraphRepository{driver: driver, database: cfg.Database, docRepo: docRepo}
+	if err := r.ensureSchema(ctx); err != nil {
+		driver.Close(ctx)
+		return nil, err
+	}
+	return r, nil
+}
+
+// Close closes the underlying driver.
+func (r *Neo4jGraphRepository) Close(ctx context.Context) error {
+	return r.driver.Close(ctx)
+}
+
+func (r *Neo4jGraphRepository) ensureSchema(ctx context.Context) error {
+	statements := []string{
+		`CREATE CONSTRAINT entity_id IF NOT EXISTS FOR (n:Entity) REQUIRE n.id IS UNIQUE`,
+		`CREATE CONSTRAINT sequence_name IF NOT EXISTS FOR (s:Sequence) REQUIRE s.name IS UNIQUE`,
+		`CREATE INDEX entity_document_id IF NOT EXISTS FOR (n:Entity) ON (n.document_id)`,
+		`CREATE INDEX relates_id IF NOT EXISTS FOR ()-[r:RELATES]-() ON (r.id)`,
+		`CREATE INDEX relates_document_id IF NOT EXISTS FOR ()-[r:RELATES]-() ON (r.document_id)`,
+	}
+	for _, stmt := range statements {
+		if _, err := r.query(ctx, stmt, nil); err != nil {
+			return fmt.Errorf("failed to create neo4j schema: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *Neo4jGraphRepository) CreateNode(ctx context.Context, node *entity.Node) error {
+	node.CreatedAt = time.Now()
+	node.Source = sourceOrDefault(node.Source)
+	_, err := r.write(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		ids, err := reserveNeo4jIDs(ctx, tx, "nodes", 1)
+		if err != nil {
+			return nil, err
+		}
+		node.ID = ids[0]
+		query := `CREATE (n:Entity) SET n = $props`
+		return nil, runDiscard(ctx, tx, query, map[string]any{"props": nodeParams(node)})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create node: %w", err)
+	}
+	return nil
+}
+
+func (r *Neo4jGraphRepository) CreateEdge(ctx context.Context, edge *entity.Edge) error {
+	edge.CreatedAt = time.Now()
+	edge.Source = sourceOrDefault(edge.Source)
+	_, err := r.write(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		ids, err := reserveNeo4jIDs(ctx, tx, "edges", 1)
+		if err != nil {
+			return nil, err
+		}
+		edge.ID = ids[0]
+		created, err := createRelationships(ctx, tx, []map[string]any{edgeParams(edge)})
+		if err != nil {
+			return nil, err
+		}
+		if created != 1 {
+			return nil, fmt.Errorf("edge endpoints %d -> %d not found", edge.SourceNodeID, edge.TargetNodeID)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create edge: %w", err)
+	}
+	return nil
+}
+
+func (r *Neo4jGraphRepository) GetNodesByDocumentID(ctx context.Context, docID int64) ([]*entity.Node, error) {
+	query := `MATCH (n:Entity {document_id: $doc}) RETURN properties(n) AS n ORDER BY n.id`
+	nodes, err := r.queryNodes(ctx, query, map[string]any{"doc": docID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	return nodes, nil
+}
+
+func (r *Neo4jGraphRepository) GetEdgesByDocumentID(ctx context.Context, docID int64) ([]*entity.Edge, error) {
+	query := `
+		MATCH (s:Entity)-[e:RELATES {document_id: $doc}]->(t:Entity)
+		RETURN properties(e) AS e, s.id AS source, t.id AS target ORDER BY e.id
+	`
+	edges, err := r.queryEdges(ctx, query, map[string]any{"doc": docID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edges: %w", err)
+	}
+	return edges, nil
+}
+
+func (r *Neo4jGraphRepository) FindNode(ctx context.Context, docID int64, name, label string) (*entity.Node, error) {
+	query := `
+		MATCH (n:Entity {document_id: $doc, name: $name, label: $label})
+		RETURN properties(n) AS n LIMIT 1
+	`
+	nodes, err := r.queryNodes(ctx, query, map[string]any{"doc": docID, "name": name, "label": label})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find node: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	return nodes[0], nil
+}
+
+func (r *Neo4jGraphRepository) SaveGraph(ctx context.Context, docID int64, nodes []*entity.Node, edges []*entity.Edge) error {
+	now := time.Now()
+	var edgeIDs []int64
+	var reused map[int]int64
+	var assigned []int64
+	var resolved [][2]int64
+
+	_, err := r.write(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		params := map[string]any{"doc": docID, "human": entity.SourceHuman}
+		query := `MATCH ()-[e:RELATES {document_id: $doc}]->() WHERE coalesce(e.source, 'llm') <> $human DELETE e`
+		if err := runDiscard(ctx, tx, query, params); err != nil {
+			return nil, fmt.Errorf("failed to delete previous edges: %w", err)
+		}
+		query = `MATCH (n:Entity {document_id: $doc}) WHERE coalesce(n.source, 'llm') <> $human RETURN properties(n) AS n`
+		existing, err := txNodes(ctx, tx, query, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list previous nodes: %w", err)
+		}
+		var stale []int64
+		reused, stale = reuseNodeIDs(existing, nodes)
+		// Nodes that curators connected are kept, or deleting them would drop the edges
+		query = `
+			MATCH (n:Entity) WHERE n.id IN $ids
+			  AND NOT EXISTS { MATCH (n)-[e:RELATES]-() WHERE e.source = $human }
+			DETACH DELETE n
+		`
+		if err := runDiscard(ctx, tx, query, map[string]any{"ids": stale, "human": entity.SourceHuman}); err != nil {
+			return nil, fmt.Errorf("failed to delete previous nodes: %w", err)
+		}
+
+		nodeIDs, err := reserveNeo4jIDs(ctx, tx, "nodes", len(nodes)-len(reused))
+		if err != nil {
+			return nil, err
+		}
+		if edgeIDs, err = reserveNeo4jIDs(ctx, tx, "edges", len(edges)); err != nil {
+			return nil, err
+		}
+
+		if assigned, resolved, err = assignGraphIDs(nodes, edges, reused, nodeIDs); err != nil {
+			return nil, err
+		}
+
+		var nodeRows, updateRows []map[string]any
+		for i, n := range nodes {
+			row := *n
+			row.ID, row.DocumentID, row.CreatedAt, row.Source = assigned[i], docID, now, sourceOrDefault(n.Source)
+			if _, ok := reused[i]; ok {
+				updateRows = append(updateRows, nodeParams(&row))
+			} else {
+				nodeRows = append(nodeRows, nodeParams(&row))
+			}
+		}
+		edgeRows := make([]map[string]any, len(edges))
+		for i, e := range edges {
+			row := *e
+			row.ID, row.DocumentID, row.SourceNodeID, row.TargetNodeID, row.CreatedAt = edgeIDs[i], docID, resolved[i][0], resolved[i][1], now
+			row.Source = sourceOrDefault(e.Source)
+			edgeRows[i] = edgeParams(&row)
+		}
+
+		for start := 0; start < len(updateRows); start += insertBatchSize {
+			query := `
+				UNWIND $rows AS row
+				MATCH (n:Entity {id: row.id})
+				SET n.label = row.label, n.name = row.name, n.properties = row.properties, n.source = row.source
+			`
+			if err := runDiscard(ctx, tx, query, map[string]any{"rows": updateRows[start:min(start+insertBatchSize, len(updateRows))]}); err != nil {
+				return nil, fmt.Errorf("failed to update nodes: %w", err)
+			}
+		}
+		if err := createEntities(ctx, tx, nodeRows); err != nil {
+			return nil, err
+		}
+		created, err := createRelationships(ctx, tx, edgeRows)
+		if err != nil {
+			return nil, err
+		}
+		if created != len(edgeRows) {
+			// Postgres rejects these through the foreign keys
+			return nil, fmt.Errorf("%d edges reference nodes that do not exist", len(edgeRows)-created)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save graph: %w", err)
+	}
+
+	for i, n := range nodes {
+		n.ID = assigned[i]
+		n.DocumentID = docID
+		n.Source = sourceOrDefault(n.Source)
+		if _, ok := reused[i]; !ok {
+			n.CreatedAt = now
+		}
+	}
+	for i, e := range edges {
+		e.ID = edgeIDs[i]
+		e.DocumentID = docID
+		e.SourceNodeID = resolved[i][0]
+		e.TargetNodeID = resolved[i][1]
+		e.Source = sourceOrDefault(e.Source)
+		e.CreatedAt = now
+	}
+	return nil
+}
+
+func (r *Neo4jGraphRepository) GetNode(ctx context.Context, id int64) (*entity.Node, error) {
+	nodes, err := r.getNodesByIDs(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	return nodes[0], nil
+}
+
+// GetNeighbors walks one level per query, as a variable-length match enumerates every path
+// and explodes on dense graphs.
+func (r *Neo4jGraphRepository) GetNeighbors(ctx context.Context, nodeID int64, q NeighborQuery) ([]*entity.Node, []*entity.Edge, error) {
+	pattern, err := cypherPattern(q.Direction, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	relationTypes := q.RelationTypes
+	if relationTypes == nil {
+		relationTypes = []string{}
+	}
+
+	query := fmt.Sprintf(`
+		MATCH (f:Entity)%s(m:Entity)
+		WHERE f.id IN $ids AND all(r IN [rels] WHERE (size($types) = 0 OR r.relation_type IN $types) AND `+relValidAt+`)
+		RETURN rels.id AS id, startNode(rels).id AS source, endNode(rels).id AS target
+	`, pattern)
+	edgesOf := func(ctx context.Context, frontier []int64) ([]pathEdge, error) {
+		result, err := r.query(ctx, query, map[string]any{"ids": frontier, "types": relationTypes, "as_of": neo4jDate(q.AsOf)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to traverse neighbors: %w", err)
+		}
+		edges := make([]pathEdge, 0, len(result.Records))
+		for _, rec := range result.Records {
+			values := rec.AsMap()
+			edges = append(edges, pathEdge{
+				ID:     values["id"].(int64),
+				Source: values["source"].(int64),
+				Target: values["target"].(int64),
+			})
+		}
+		return edges, nil
+	}
+	steps, err := walkNeighbors(ctx, nodeID, q.Direction, q.Depth, edgesOf)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodeIDs, edgeIDs := closestNodes(steps, q.Limit)
+
+	nodes, err := r.getNodesByIDs(ctx, nodeIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	edges, err := r.getEdgesByIDs(ctx, edgeIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nodes, edgesWithin(edges, nodeIDs), nil
+}
+
+func (r *Neo4jGraphRepository) ShortestPath(ctx context.Context, fromID, toID int64, direction string, maxDepth int, asOf *entity.Date) ([]*entity.Node, []*entity.Edge, error) {
+	if fromID == toID {
+		// shortestPath() rejects paths from a node to itself
+		nodes, err := r.getNodesByIDs(ctx, []int64{fromID})
+		return nodes, []*entity.Edge{}, err
+	}
+
+	pattern, err := cypherPattern(direction, fmt.Sprintf("*1..%d", maxDepth))
+	if err != nil {
+		return nil, nil, err
+	}
+	query := fmt.Sprintf(`
+		MATCH (a:Entity {id: $from}), (b:Entity {id: $to})
+		MATCH p = shortestPath((a)%s(b))
+		WHERE all(r IN rels WHERE `+relValidAt+`)
+		RETURN [n IN nodes(p) | n.id] AS node_ids, [r IN rels | r.id] AS edge_ids
+	`, pattern)
+	result, err := r.query(ctx, query, map[string]any{"from": fromID, "to": toID, "as_of": neo4jDate(asOf)})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find path: %w", err)
+	}
+	if len(result.Records) == 0 {
+		return []*entity.Node{}, []*entity.Edge{}, nil
+	}
+
+	values := result.Records[0].AsMap()
+	nodeIDs := int64List(values["node_ids"])
+	edgeIDs := int64List(values["edge_ids"])
+
+	nodes, err := r.getNodesByIDs(ctx, nodeIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	edges, err := r.getEdgesByIDs(ctx, edgeIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return orderByIDs(nodes, nodeIDs, func(n *entity.Node) int64 { return n.ID }),
+		orderByIDs(edges, edgeIDs, func(e *entity.Edge) int64 { return e.ID }), nil
+}
+
+func (r *Neo4jGraphRepository) GetSubgraph(ctx context.Context, nodeID int64, depth, limit int, asOf *entity.Date) ([]*entity.Node, []*entity.Edge, error) {
+	nodes, _, err := r.GetNeighbors(ctx, nodeID, NeighborQuery{Direction: DirectionBoth, Depth: depth, Limit: limit, AsOf: asOf})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodeIDs := make([]int64, len(nodes))
+	for i, n := range nodes {
+		nodeIDs[i] = n.ID
+	}
+
+	query := `
+		MATCH (s:Entity)-[e:RELATES]->(t:Entity)
+		WHERE s.id IN $ids AND t.id IN $ids AND all(r IN [e] WHERE ` + relValidAt + `)
+		RETURN properties(e) AS e, s.id AS source, t.id AS target
+	`
+	edges, err := r.queryEdges(ctx, query, map[string]any{"ids": nodeIDs, "as_of": neo4jDate(asOf)})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list subgraph edges: %w", err)
+	}
+	return nodes, edges, nil
+}
+
+// GetNodesByNotebookID retrieves the nodes of all non-deleted documents in a notebook.
+func (r *Neo4jGraphRepository) GetNodesByNotebookID(ctx context.Context, notebookID int64) ([]*entity.Node, error) {
+	docIDs, err := r.notebookDocumentIDs(ctx, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	query := `MATCH (n:Entity) WHERE n.document_id IN $docs RETURN properties(n) AS n`
+	nodes, err := r.queryNodes(ctx, query, map[string]any{"docs": docIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notebook nodes: %w", err)
+	}
+	return nodes, nil
+}
+
+// FindNodes filters the notebook's nodes in memory: properties are stored as a JSON string
+// that Cypher cannot match on.
+func (r *Neo4jGraphRepository) FindNodes(ctx context.Context, notebookID int64, f PropertyFilter) ([]*entity.Node, error) {
+	all, err := r.GetNodesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*entity.Node{}
+	for _, n := range all {
+		if (f.Type == "" || n.Label == f.Type) && n.Properties.Contains(f.Properties) {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].PageRank != nodes[j].PageRank {
+			return nodes[i].PageRank > nodes[j].PageRank
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+	return nodes[:min(len(nodes), f.Limit)], nil
+}
+
+// MatchNodeNames scores the notebook's nodes in memory, as Neo4j has no trigram matching.
+func (r *Neo4jGraphRepository) MatchNodeNames(ctx context.Context, notebookID int64, text string, minSimilarity float64, limit int) ([]*NodeMatch, error) {
+	all, err := r.GetNodesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	matches := []*NodeMatch{}
+	for _, n := range all {
+		if sim := wordSimilarity(n.Name, text); sim >= minSimilarity {
+			matches = append(matches, &NodeMatch{Node: *n, Similarity: sim})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		if matches[i].PageRank != matches[j].PageRank {
+			return matches[i].PageRank > matches[j].PageRank
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches[:min(len(matches), limit)], nil
+}
+
+func (r *Neo4jGraphRepository) FindEdges(ctx context.Context, notebookID int64, f PropertyFilter) ([]*entity.Edge, error) {
+	all, err := r.GetEdgesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	edges := []*entity.Edge{}
+	for _, e := range all {
+		if (f.Type == "" || e.RelationType == f.Type) && e.Properties.Contains(f.Properties) && (f.AsOf == nil || e.ValidAt(*f.AsOf)) {
+			edges = append(edges, e)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].ID < edges[j].ID })
+	return edges[:min(len(edges), f.Limit)], nil
+}
+
+// GetEdgesByNotebookID retrieves the edges of all non-deleted documents in a notebook.
+func (r *Neo4jGraphRepository) GetEdgesByNotebookID(ctx context.Context, notebookID int64) ([]*entity.Edge, error) {
+	docIDs, err := r.notebookDocumentIDs(ctx, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		MATCH (s:Entity)-[e:RELATES]->(t:Entity)
+		WHERE e.document_id IN $docs
+		RETURN properties(e) AS e, s.id AS source, t.id AS target
+	`
+	edges, err := r.queryEdges(ctx, query, map[string]any{"docs": docIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notebook edges: %w", err)
+	}
+	return edges, nil
+}
+
+// UpdateNodeScores stores analytics scores on nodes in one transaction.
+func (r *Neo4jGraphRepository) UpdateNodeScores(ctx context.Context, scores []*entity.NodeScore) error {
+	_, err := r.write(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		for start := 0; start < len(scores); start += insertBatchSize {
+			batch := scores[start:min(start+insertBatchSize, len(scores))]
+			rows := make([]map[string]any, len(batch))
+			for i, sc := range batch {
+				rows[i] = map[string]any{
+					"id":                sc.NodeID,
+					"pagerank":          sc.PageRank,
+					"degree_centrality": sc.DegreeCentrality,
+					"betweenness":       sc.Betweenness,
+					"community_id":      sc.CommunityID,
+				}
+			}
+			query := `
+				UNWIND $rows AS row
+				MATCH (n:Entity {id: row.id})
+				SET n.pagerank = row.pagerank, n.degree_centrality = row.degree_centrality,
+					n.betweenness = row.betweenness, n.community_id = row.community_id
+			`
+			if err := runDiscard(ctx, tx, query, map[string]any{"rows": rows}); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update node scores: %w", err)
+	}
+	return nil
+}
+
+// ReplaceNotebookNodeScores stores a notebook's scores on its nodes as notebook_* properties,
+// next to the per-document scores.
+func (r *Neo4jGraphRepository) ReplaceNotebookNodeScores(ctx context.Context, notebookID int64, scores []*entity.NodeScore) error {
+	docIDs, err := r.notebookDocumentIDs(ctx, notebookID)
+	if err != nil {
+		return err
+	}
+	_, err = r.write(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		query := `
+			MATCH (n:Entity) WHERE n.document_id IN $docs
+			REMOVE n.notebook_pagerank, n.notebook_degree_centrality, n.notebook_betweenness, n.notebook_community_id
+		`
+		if err := runDiscard(ctx, tx, query, map[string]any{"docs": docIDs}); err != nil {
+			return nil, err
+		}
+		for start := 0; start < len(scores); start += insertBatchSize {
+			batch := scores[start:min(start+insertBatchSize, len(scores))]
+			rows := make([]map[string]any, len(batch))
+			for i, sc := range batch {
+				rows[i] = map[string]any{
+					"id":                sc.NodeID,
+					"pagerank":          sc.PageRank,
+					"degree_centrality": sc.DegreeCentrality,
+					"betweenness":       sc.Betweenness,
+					"community_id":      sc.CommunityID,
+				}
+			}
+			query := `
+				UNWIND $rows AS row
+				MATCH (n:Entity {id: row.id}) WHERE n.document_id IN $docs
+				SET n.notebook_pagerank = row.pagerank, n.notebook_degree_centrality = row.degree_centrality,
+					n.notebook_betweenness = row.betweenness, n.notebook_community_id = row.community_id
+			`
+			if err := runDiscard(ctx, tx, query, map[string]any{"rows": rows, "docs": docIDs}); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replace notebook node scores: %w", err)
+	}
+	return nil
+}
+
+func (r *Neo4jGraphRepository) GetNotebookNodeScores(ctx context.Context, notebookID int64) ([]*entity.NodeScore, error) {
+	docIDs, err := r.notebookDocumentIDs(ctx, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		MATCH (n:Entity) WHERE n.document_id IN $docs AND n.notebook_pagerank IS NOT NULL
+		RETURN n.id AS id, n.notebook_pagerank AS pagerank, n.notebook_degree_centrality AS degree_centrality,
+			n.notebook_betweenness AS betweenness, n.notebook_community_id AS community_id
+	`
+	result, err := r.query(ctx, query, map[string]any{"docs": docIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notebook node scores: %w", err)
+	}
+	scores := make([]*entity.NodeScore, 0, len(result.Records))
+	for _, rec := range result.Records {
+		values := rec.AsMap()
+		sc := &entity.NodeScore{}
+		sc.NodeID, _ = values["id"].(int64)
+		sc.PageRank, _ = values["pagerank"].(float64)
+		sc.DegreeCentrality, _ = values["degree_centrality"].(float64)
+		sc.Betweenness, _ = values["betweenness"].(float64)
+		sc.CommunityID, _ = values["community_id"].(int64)
+		scores = append(scores, sc)
+	}
+	return scores, nil
+}
+
+func (r *Neo4jGraphRepository) DeleteByDocumentID(ctx context.Context, docID int64) error {
+	_, err := r.write(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return nil, deleteDocumentGraph(ctx, tx, docID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete document graph: %w", err)
+	}
+	return nil
+}
+
+func (r *Neo4jGraphRepository) UpdateNode(ctx context.Context, node *entity.Node) error {
+	node.Source = sourceOrDefault(node.Source)
+	query := `
+		MATCH (n:Entity {id: $id})
+		SET n.label = $label, n.name = $name, n.properties = $properties, n.source = $source
+	`
+	_, err := r.query(ctx, query, map[string]any{
+		"id": node.ID, "label": node.Label, "name": node.Name,
+		"properties": node.Properties.String(), "source": node.Source,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update node: %w", err)
+	}
+	return nil
+}
+
+func (r *Neo4jGraphRepository) DeleteNode(ctx context.Context, id int64) error {
+	if _, err := r.query(ctx, `MATCH (n:Entity {id: $id}) DETACH DELETE n`, map[string]any{"id": id}); err != nil {
+		return fmt.Errorf("failed to delete node: %w", err)
+	}
+	return nil
+}
+
+func (r *Neo4jGraphRepository) GetEdge(ctx context.Context, id int64) (*entity.Edge, error) {
+	edges, err := r.getEdgesByIDs(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(edges) == 0 {
+		return nil, nil
+	}
+	return edges[0], nil
+}
+
+func (r *Neo4jGraphRepository) UpdateEdge(ctx context.Context, edge *entity.Edge) error {
+	edge.Source = sourceOrDefault(edge.Source)
+	query := `
+		MATCH ()-[e:RELATES {id: $id}]->()
+		SET e.relation_type = $relation_type, e.properties = $properties, e.source = $source,
+			e.valid_from = $valid_from, e.valid_to = $valid_to
+	`
+	_, err := r.query(ctx, query, map[string]any{
+		"id": edge.ID, "relation_type": edge.RelationType,
+		"properties": edge.Properties.String(), "source": edge.Source,
+		"valid_from": neo4jDate(edge.ValidFrom), "valid_to": neo4jDate(edge.ValidTo),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update edge: %w", err)
+	}
+	return nil
+}
+
+func (r *Neo4jGraphRepository) DeleteEdge(ctx context.Context, id int64) error {
+	if _, err := r.query(ctx, `MATCH ()-[e:RELATES {id: $id}]->() DELETE e`, map[string]any{"id": id}); err != nil {
+		return fmt.Errorf("failed to delete edge: %w", err)
+	}
+	return nil
+}
+
+// MergeNodes recreates the moved relationships, as Neo4j cannot change their endpoints.
+func (r *Neo4jGraphRepository) MergeNodes(ctx context.Context, targetID int64, sourceIDs []int64) ([]*entity.Edge, error) {
+	var dropped []*entity.Edge
+	_, err := r.write(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		merged := append([]int64{targetID}, sourceIDs...)
+		query := `
+			MATCH (s:Entity)-[e:RELATES]->(t:Entity)
+			WHERE s.id IN $ids OR t.id IN $ids
+			RETURN properties(e) AS e, s.id AS source, t.id AS target ORDER BY e.id
+		`
+		edges, err := txEdges(ctx, tx, query, map[string]any{"ids": merged})
+		if err != nil {
+			return nil, err
+		}
+
+		var moved []*entity.Edge
+		moved, dropped = mergePlan(edges, targetID, sourceIDs)
+		var removed []int64
+		rows := make([]map[string]any, len(moved))
+		for i, e := range moved {
+			removed = append(removed, e.ID)
+			rows[i] = edgeParams(e)
+		}
+		for _, e := range dropped {
+			removed = append(removed, e.ID)
+		}
+
+		if err := runDiscard(ctx, tx, `MATCH ()-[e:RELATES]->() WHERE e.id IN $ids DELETE e`, map[string]any{"ids": removed}); err != nil {
+			return nil, err
+		}
+		if _, err := createRelationships(ctx, tx, rows); err != nil {
+			return nil, err
+		}
+		return nil, runDiscard(ctx, tx, `MATCH (n:Entity) WHERE n.id IN $ids DETACH DELETE n`, map[string]any{"ids": sourceIDs})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge nodes: %w", err)
+	}
+	return dropped, nil
+}
+
+func (r *Neo4jGraphRepository) SplitNode(ctx context.Context, nodeID int64, parts []NodeSplit) error {
+	now := time.Now()
+	var docID int64
+	var ids []int64
+	_, err := r.write(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		split, err := txNodes(ctx, tx, `MATCH (n:Entity {id: $id}) RETURN properties(n) AS n`, map[string]any{"id": nodeID})
+		if err != nil {
+			return nil, err
+		}
+		if len(split) == 0 {
+			return nil, fmt.Errorf("node %d not found", nodeID)
+		}
+		docID = split[0].DocumentID
+
+		if ids, err = reserveNeo4jIDs(ctx, tx, "nodes", len(parts)); err != nil {
+			return nil, err
+		}
+		rows := make([]map[string]any, len(parts))
+		owner := make(map[int64]int64) // edge ID -> new endpoint
+		var edgeIDs []int64
+		for i, part := range parts {
+			row := *part.Node
+			row.ID, row.DocumentID, row.CreatedAt, row.Source = ids[i], docID, now, sourceOrDefault(row.Source)
+			rows[i] = nodeParams(&row)
+			for _, id := range part.EdgeIDs {
+				owner[id] = ids[i]
+				edgeIDs = append(edgeIDs, id)
+			}
+		}
+		if err := createEntities(ctx, tx, rows); err != nil {
+			return nil, err
+		}
+
+		query := `
+			MATCH (s:Entity)-[e:RELATES]->(t:Entity)
+			WHERE e.id IN $ids AND (s.id = $node OR t.id = $node)
+			RETURN properties(e) AS e, s.id AS source, t.id AS target
+		`
+		edges, err := txEdges(ctx, tx, query, map[string]any{"ids": edgeIDs, "node": nodeID})
+		if err != nil {
+			return nil, err
+		}
+		moved := make([]map[string]any, len(edges))
+		removed := make([]int64, len(edges))
+		for i, e := range edges {
+			if e.SourceNodeID == nodeID {
+				e.SourceNodeID = owner[e.ID]
+			}
+			if e.TargetNodeID == nodeID {
+				e.TargetNodeID = owner[e.ID]
+			}
+			moved[i] = edgeParams(e)
+			removed[i] = e.ID
+		}
+		if err := runDiscard(ctx, tx, `MATCH ()-[e:RELATES]->() WHERE e.id IN $ids DELETE e`, map[string]any{"ids": removed}); err != nil {
+			return nil, err
+		}
+		_, err = createRelationships(ctx, tx, moved)
+		return nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to split node: %w", err)
+	}
+
+	for i, part := range parts {
+		part.Node.ID = ids[i]
+		part.Node.DocumentID = docID
+		part.Node.Source = sourceOrDefault(part.Node.Source)
+		part.Node.CreatedAt = now
+	}
+	return nil
+}
+
+// ImportGraph writes nodes and edges with their existing IDs, replacing entities with the same
+// IDs, and advances the ID sequences past them. Edge endpoints must already exist or be among
+// the imported nodes. It is used to copy a graph from Postgres.
+func (r *Neo4jGraphRepository) ImportGraph(ctx context.Context, nodes []*entity.Node, edges []*entity.Edge) error {
+	_, err := r.write(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		var maxNodeID, maxEdgeID int64
+		nodeRows := make([]map[string]any, len(nodes))
+		for i, n := range nodes {
+			nodeRows[i] = nodeParams(n)
+			maxNodeID = max(maxNodeID, n.ID)
+		}
+		edgeRows := make([]map[string]any, len(edges))
+		for i, e := range edges {
+			edgeRows[i] = edgeParams(e)
+			maxEdgeID = max(maxEdgeID, e.ID)
+		}
+
+		for start := 0; start < len(nodeRows); start += insertBatchSize {
+			query := `UNWIND $rows AS row MERGE (n:Entity {id: row.id}) SET n = row`
+			if err := runDiscard(ctx, tx, query, map[string]any{"rows": nodeRows[start:min(start+insertBatchSize, len(nodeRows))]}); err != nil {
+				return nil, err
+			}
+		}
+		for start := 0; start < len(edgeRows); start += insertBatchSize {
+			query := `
+				UNWIND $rows AS row
+				MATCH (s:Entity {id: row.source_node_id}), (t:Entity {id: row.target_node_id})
+				MERGE (s)-[e:RELATES {id: row.id}]->(t)
+				SET e = row.props
+			`
+			if err := runDiscard(ctx, tx, query, map[string]any{"rows": edgeRows[start:min(start+insertBatchSize, len(edgeRows))]}); err != nil {
+				return nil, err
+			}
+		}
+
+		for name, maxID := range map[string]int64{"nodes": maxNodeID, "edges": maxEdgeID} {
+			query := `
+				MERGE (s:Sequence {name: $name})
+				ON CREATE SET s.value = 0
+				SET s.value = CASE WHEN s.value < $max THEN $max ELSE s.value END
+			`
+			if err := runDiscard(ctx, tx, query, map[string]any{"name": name, "max": maxID}); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import graph: %w", err)
+	}
+	return nil
+}
+
+// Reset deletes every entity and relationship. Sequences are kept so IDs are never reused.
+func (r *Neo4jGraphRepository) Reset(ctx context.Context) error {
+	// CALL ... IN TRANSACTIONS only runs in an auto-commit transaction
+	session := r.driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: r.database, AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `MATCH (n:Entity) CALL { WITH n DETACH DELETE n } IN TRANSACTIONS OF 1000 ROWS`, nil)
+	if err == nil {
+		_, err = result.Consume(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reset graph: %w", err)
+	}
+	return nil
+}
+
+func (r *Neo4jGraphRepository) notebookDocumentIDs(ctx context.Context, notebookID int64) ([]int64, error) {
+	docs, err := r.docRepo.ListAllByNotebook(ctx, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	ids := []int64{}
+	for _, d := range docs {
+		if !d.IsDeleted {
+			ids = append(ids, d.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *Neo4jGraphRepository) getNodesByIDs(ctx context.Context, ids []int64) ([]*entity.Node, error) {
+	if len(ids) == 0 {
+		return []*entity.Node{}, nil
+	}
+	query := `MATCH (n:Entity) WHERE n.id IN $ids RETURN properties(n) AS n`
+	nodes, err := r.queryNodes(ctx, query, map[string]any{"ids": ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	return nodes, nil
+}
+
+func (r *Neo4jGraphRepository) getEdgesByIDs(ctx context.Context, ids []int64) ([]*entity.Edge, error) {
+	if len(ids) == 0 {
+		return []*entity.Edge{}, nil
+	}
+	query := `
+		MATCH (s:Entity)-[e:RELATES]->(t:Entity)
+		WHERE e.id IN $ids
+		RETURN properties(e) AS e, s.id AS source, t.id AS target
+	`
+	edges, err := r.queryEdges(ctx, query, map[string]any{"ids": ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edges: %w", err)
+	}
+	return edges, nil
+}
+
+func (r *Neo4jGraphRepository) query(ctx context.Context, query string, params map[string]any) (*neo4j.EagerResult, error) {
+	return neo4j.ExecuteQuery(ctx, r.driver, query, params, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(r.database))
+}
+
+func (r *Neo4jGraphRepository) queryNodes(ctx context.Context, query string, params map[string]any) ([]*entity.Node, error) {
+	result, err := r.query(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*entity.Node, 0, len(result.Records))
+	for _, rec := range result.Records {
+		props, _ := rec.AsMap()["n"].(map[string]any)
+		nodes = append(nodes, nodeFromProps(props))
+	}
+	return nodes, nil
+}
+
+func (r *Neo4jGraphRepository) queryEdges(ctx context.Context, query string, params map[string]any) ([]*entity.Edge, error) {
+	result, err := r.query(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	edges := make([]*entity.Edge, 0, len(result.Records))
+	for _, rec := range result.Records {
+		values := rec.AsMap()
+		props, _ := values["e"].(map[string]any)
+		edge := edgeFromProps(props)
+		edge.SourceNodeID, _ = values["source"].(int64)
+		edge.TargetNodeID, _ = values["target"].(int64)
+		edges = append(edges, edge)
+	}
+	return edges, nil
+}
+
+// txNodes runs a query returning node properties as n inside a transaction.
+func txNodes(ctx context.Context, tx neo4j.ManagedTransaction, query string, params map[string]any) ([]*entity.Node, error) {
+	result, err := tx.Run(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	records, err := result.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*entity.Node, 0, len(records))
+	for _, rec := range records {
+		props, _ := rec.AsMap()["n"].(map[string]any)
+		nodes = append(nodes, nodeFromProps(props))
+	}
+	return nodes, nil
+}
+
+// txEdges runs a query returning edges as e, source and target inside a transaction.
+func txEdges(ctx context.Context, tx neo4j.ManagedTransaction, query string, params map[string]any) ([]*entity.Edge, error) {
+	result, err := tx.Run(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	records, err := result.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	edges := make([]*entity.Edge, 0, len(records))
+	for _, rec := range records {
+		values := rec.AsMap()
+		props, _ := values["e"].(map[string]any)
+		edge := edgeFromProps(props)
+		edge.SourceNodeID, _ = values["source"].(int64)
+		edge.TargetNodeID, _ = values["target"].(int64)
+		edges = append(edges, edge)
+	}
+	return edges, nil
+}
+
+func (r *Neo4jGraphRepository) write(ctx context.Context, work neo4j.ManagedTransactionWork) (any, error) {
+	session := r.driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: r.database, AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+	return session.ExecuteWrite(ctx, work)
+}
+
+// reserveNeo4jIDs draws n consecutive values from the named sequence.
+func reserveNeo4jIDs(ctx context.Context, tx neo4j.ManagedTransaction, name string, n int) ([]int64, error) {
+	ids := make([]int64, 0, n)
+	if n == 0 {
+		return ids, nil
+	}
+	query := `
+		MERGE (s:Sequence {name: $name})
+		ON CREATE SET s.value = 0
+		SET s.value = s.value + $n
+		RETURN s.value AS last
+	`
+	result, err := tx.Run(ctx, query, map[string]any{"name": name, "n": n})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve %s ids: %w", name, err)
+	}
+	rec, err := result.Single(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve %s ids: %w", name, err)
+	}
+	last, _ := rec.AsMap()["last"].(int64)
+	for id := last - int64(n) + 1; id <= last; id++ {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// deleteDocumentGraph removes a document's edges and nodes, together with any edge of
+// another document attached to those nodes, like the Postgres foreign keys cascade.
+func deleteDocumentGraph(ctx context.Context, tx neo4j.ManagedTransaction, docID int64) error {
+	if err := runDiscard(ctx, tx, `MATCH ()-[e:RELATES {document_id: $doc}]->() DELETE e`, map[string]any{"doc": docID}); err != nil {
+		return fmt.Errorf("failed to delete previous edges: %w", err)
+	}
+	if err := runDiscard(ctx, tx, `MATCH (n:Entity {document_id: $doc}) DETACH DELETE n`, map[string]any{"doc": docID}); err != nil {
+		return fmt.Errorf("failed to delete previous nodes: %w", err)
+	}
+	return nil
+}
+
+func createEntities(ctx context.Context, tx neo4j.ManagedTransaction, rows []map[string]any) error {
+	for start := 0; start < len(rows); start += insertBatchSize {
+		query := `UNWIND $rows AS row CREATE (n:Entity) SET n = row`
+		if err := runDiscard(ctx, tx, query, map[string]any{"rows": rows[start:min(start+insertBatchSize, len(rows))]}); err != nil {
+			return fmt.Errorf("failed to insert nodes: %w", err)
+		}
+	}
+	return nil
+}
+
+// createRelationships creates edges from edgeParams rows and returns how many were created;
+// rows whose endpoints do not exist are skipped.
+func createRelationships(ctx context.Context, tx neo4j.ManagedTransaction, rows []map[string]any) (int, error) {
+	created := 0
+	for start := 0; start < len(rows); start += insertBatchSize {
+		query := `
+			UNWIND $rows AS row
+			MATCH (s:Entity {id: row.source_node_id}), (t:Entity {id: row.target_node_id})
+			CREATE (s)-[e:RELATES]->(t)
+			SET e = row.props
+			RETURN count(e) AS created
+		`
+		result, err := tx.Run(ctx, query, map[string]any{"rows": rows[start:min(start+insertBatchSize, len(rows))]})
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert edges: %w", err)
+		}
+		rec, err := result.Single(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert edges: %w", err)
+		}
+		n, _ := rec.AsMap()["created"].(int64)
+		created += int(n)
+	}
+	return created, nil
+}
+
+func runDiscard(ctx context.Context, tx neo4j.ManagedTransaction, query string, params map[string]any) error {
+	result, err := tx.Run(ctx, query, params)
+	if err != nil {
+		return err
+	}
+	_, err = result.Consume(ctx)
+	return err
+}
+
+// cypherPattern returns the relationship pattern for a traversal direction, binding the
+// relationships to rels.
+func cypherPattern(direction, length string) (string, error) {
+	switch direction {
+	case DirectionOut:
+		return fmt.Sprintf("-[rels:RELATES%s]->", length), nil
+	case DirectionIn:
+		return fmt.Sprintf("<-[rels:RELATES%s]-", length), nil
+	case DirectionBoth, "":
+		return fmt.Sprintf("-[rels:RELATES%s]-", length), nil
+	default:
+		return "", fmt.Errorf("invalid direction: %q", direction)
+	}
+}
+
+func nodeParams(n *entity.Node) map[string]any {
+	props := map[string]any{
+		"id":                n.ID,
+		"document_id":       n.DocumentID,
+		"label":             n.Label,
+		"name":              n.Name,
+		"properties":        n.Properties.String(),
+		"source":            sourceOrDefault(n.Source),
+		"created_at":        n.CreatedAt,
+		"pagerank":          n.PageRank,
+		"degree_centrality": n.DegreeCentrality,
+		"betweenness":       n.Betweenness,
+	}
+	if n.CommunityID != nil {
+		props["community_id"] = *n.CommunityID
+	}
+	return props
+}
+
+// edgeParams returns an edge's endpoints alongside the properties stored on the relationship.
+func edgeParams(e *entity.Edge) map[string]any {
+	props := map[string]any{
+		"id":            e.ID,
+		"document_id":   e.DocumentID,
+		"relation_type": e.RelationType,
+		"properties":    e.Properties.String(),
+		"source":        sourceOrDefault(e.Source),
+		"created_at":    e.CreatedAt,
+	}
+	if e.ValidFrom != nil {
+		props["valid_from"] = neo4jDate(e.ValidFrom)
+	}
+	if e.ValidTo != nil {
+		props["valid_to"] = neo4jDate(e.ValidTo)
+	}
+	return map[string]any{
+		"id":             e.ID,
+		"source_node_id": e.SourceNodeID,
+		"target_node_id": e.TargetNodeID,
+		"props":          props,
+	}
+}
+
+// relValidAt is the condition that the relationship r is valid on $as_of, which matches
+// every relationship when null.
+const relValidAt = `($as_of IS NULL OR ((r.valid_from IS NULL OR r.valid_from <= $as_of)
+	AND (r.valid_to IS NULL OR r.valid_to >= $as_of)))`
+
+// neo4jDate converts a date parameter, keeping nil as null.
+func neo4jDate(d *entity.Date) any {
+	if d == nil {
+		return nil
+	}
+	return neo4j.DateOf(d.Time)
+}
+
+// dateFromNeo4j converts a stored date property, returning nil when unset.
+func dateFromNeo4j(v any) *entity.Date {
+	d, ok := v.(neo4j.Date)
+	if !ok {
+		return nil
+	}
+	date := entity.NewDate(d.Time())
+	return &date
+}
+
+func nodeFromProps(props map[string]any) *entity.Node {
+	n := &entity.Node{}
+	n.ID, _ = props["id"].(int64)
+	n.DocumentID, _ = props["document_id"].(int64)
+	n.Label, _ = props["label"].(string)
+	n.Name, _ = props["name"].(string)
+	n.Properties = decodeNeo4jProperties(props["properties"])
+	n.Source, _ = props["source"].(string)
+	n.Source = sourceOrDefault(n.Source)
+	n.CreatedAt, _ = props["created_at"].(time.Time)
+	n.PageRank, _ = props["pagerank"].(float64)
+	n.DegreeCentrality, _ = props["degree_centrality"].(float64)
+	n.Betweenness, _ = props["betweenness"].(float64)
+	if c, ok := props["community_id"].(int64); ok {
+		n.CommunityID = &c
+	}
+	return n
+}
+
+// decodeNeo4jProperties decodes the JSON object that holds a node's or edge's properties;
+// Neo4j properties cannot be maps.
+func decodeNeo4jProperties(v any) entity.Properties {
+	props := entity.Properties{}
+	if raw, ok := v.(string); ok {
+		_ = props.Scan(raw)
+	}
+	return props
+}
+
+func edgeFromProps(props map[string]any) *entity.Edge {
+	e := &entity.Edge{}
+	e.ID, _ = props["id"].(int64)
+	e.DocumentID, _ = props["document_id"].(int64)
+	e.RelationType, _ = props["relation_type"].(string)
+	e.Properties = decodeNeo4jProperties(props["properties"])
+	e.Source, _ = props["source"].(string)
+	e.Source = sourceOrDefault(e.Source)
+	e.CreatedAt, _ = props["created_at"].(time.Time)
+	e.ValidFrom = dateFromNeo4j(props["valid_from"])
+	e.ValidTo = dateFromNeo4j(props["valid_to"])
+	return e
+}
+
+func int64List(v any) []int64 {
+	items, _ := v.([]any)
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		if id, ok := item.(int64); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
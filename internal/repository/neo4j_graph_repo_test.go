@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+// connectNeo4j connects to a local Neo4j (docker compose up neo4j), skipping the test if
+// none is reachable.
+func connectNeo4j(ctx context.Context, t *testing.T) *Neo4jGraphRepository {
+	password := os.Getenv("NEO4J_PASSWORD")
+	if password == "" {
+		password = "graphweaver123"
+	}
+	repo, err := NewNeo4jGraphRepository(ctx, Neo4jConfig{
+		URI:      "neo4j://localhost:7687",
+		Username: "neo4j",
+		Password: password,
+	}, nil)
+	if err != nil {
+		t.Skipf("Skipping integration test: failed to connect to neo4j: %v", err)
+	}
+	return repo
+}
+
+func TestNeo4jGraphRepository_Integration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	repo := connectNeo4j(ctx, t)
+	defer repo.Close(ctx)
+
+	// A document ID no real document uses
+	docID := time.Now().UnixNano()
+	defer repo.DeleteByDocumentID(ctx, docID)
+
+	// 1. Save a chain a -> b -> c
+	nodes := []*entity.Node{
+		{ID: -1, Label: "Person", Name: "a", Properties: entity.Properties{"note": "say \"hi\"\nC:\\a", "age": 42.0}},
+		{ID: -2, Label: "Person", Name: "b"},
+		{ID: -3, Label: "Place", Name: "c"},
+	}
+	since := entity.NewDate(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	edges := []*entity.Edge{
+		{SourceNodeID: -1, TargetNodeID: -2, RelationType: "KNOWS"},
+		{SourceNodeID: -2, TargetNodeID: -3, RelationType: "LIVES_IN", ValidFrom: &since},
+	}
+	if err := repo.SaveGraph(ctx, docID, nodes, edges); err != nil {
+		t.Fatalf("SaveGraph failed: %v", err)
+	}
+	a, b, c := nodes[0].ID, nodes[1].ID, nodes[2].ID
+	if a <= 0 || edges[0].SourceNodeID != a || edges[1].TargetNodeID != c {
+		t.Fatalf("expected assigned IDs, got nodes %d %d %d and edges %+v %+v", a, b, c, edges[0], edges[1])
+	}
+
+	gotNodes, err := repo.GetNodesByDocumentID(ctx, docID)
+	if err != nil {
+		t.Fatalf("GetNodesByDocumentID failed: %v", err)
+	}
+	gotEdges, err := repo.GetEdgesByDocumentID(ctx, docID)
+	if err != nil {
+		t.Fatalf("GetEdgesByDocumentID failed: %v", err)
+	}
+	if len(gotNodes) != 3 || len(gotEdges) != 2 {
+		t.Fatalf("expected 3 nodes and 2 edges, got %d and %d", len(gotNodes), len(gotEdges))
+	}
+	if gotNodes[0].Properties["note"] != "say \"hi\"\nC:\\a" || gotNodes[0].Properties["age"] != 42.0 {
+		t.Errorf("expected properties to round-trip, got %v", gotNodes[0].Properties)
+	}
+	if gotNodes[1].Properties == nil || len(gotNodes[1].Properties) != 0 {
+		t.Errorf("expected missing properties to be stored as an empty object, got %v", gotNodes[1].Properties)
+	}
+
+	// 2. Traversals
+	neighbors, _, err := repo.GetNeighbors(ctx, a, NeighborQuery{Direction: DirectionOut, Depth: 1})
+	if err != nil {
+		t.Fatalf("GetNeighbors failed: %v", err)
+	}
+	if len(neighbors) != 2 {
+		t.Errorf("expected a and b within one hop, got %d nodes", len(neighbors))
+	}
+
+	filtered, _, err := repo.GetNeighbors(ctx, a, NeighborQuery{Direction: DirectionOut, Depth: 2, RelationTypes: []string{"KNOWS"}})
+	if err != nil {
+		t.Fatalf("GetNeighbors with relation filter failed: %v", err)
+	}
+	if len(filtered) != 2 {
+		t.Errorf("expected the LIVES_IN hop to be filtered out, got %d nodes", len(filtered))
+	}
+
+	pathNodes, pathEdges, err := repo.ShortestPath(ctx, c, a, DirectionBoth, 3, nil)
+	if err != nil {
+		t.Fatalf("ShortestPath failed: %v", err)
+	}
+	if len(pathNodes) != 3 || pathNodes[0].ID != c || pathNodes[2].ID != a || len(pathEdges) != 2 {
+		t.Errorf("expected path c-b-a, got %d nodes and %d edges", len(pathNodes), len(pathEdges))
+	}
+
+	noPath, _, err := repo.ShortestPath(ctx, c, a, DirectionOut, 3, nil)
+	if err != nil {
+		t.Fatalf("ShortestPath failed: %v", err)
+	}
+	if len(noPath) != 0 {
+		t.Errorf("expected no outgoing path from c to a, got %d nodes", len(noPath))
+	}
+
+	before := entity.NewDate(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	earlier, _, err := repo.ShortestPath(ctx, c, a, DirectionBoth, 3, &before)
+	if err != nil {
+		t.Fatalf("ShortestPath as of %s failed: %v", before, err)
+	}
+	if len(earlier) != 0 {
+		t.Errorf("expected no path before LIVES_IN became valid, got %d nodes", len(earlier))
+	}
+	if e, _ := repo.GetEdge(ctx, edges[1].ID); e == nil || e.ValidFrom == nil || *e.ValidFrom != since {
+		t.Errorf("expected valid_from to round-trip, got %+v", e)
+	}
+
+	// 3. Scores
+	if err := repo.UpdateNodeScores(ctx, []*entity.NodeScore{{NodeID: b, PageRank: 0.5, CommunityID: 7}}); err != nil {
+		t.Fatalf("UpdateNodeScores failed: %v", err)
+	}
+	node, err := repo.GetNode(ctx, b)
+	if err != nil || node == nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if node.PageRank != 0.5 || node.CommunityID == nil || *node.CommunityID != 7 {
+		t.Errorf("expected stored scores, got pagerank %v community %v", node.PageRank, node.CommunityID)
+	}
+
+	// 4. Curation: a human edit survives re-extraction, a merge rewires edges
+	node.Name = "b2"
+	node.Source = entity.SourceHuman
+	if err := repo.UpdateNode(ctx, node); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	reextracted := []*entity.Node{{ID: -1, Label: "Person", Name: "a"}, {ID: -2, Label: "Place", Name: "c"}}
+	if err := repo.SaveGraph(ctx, docID, reextracted, nil); err != nil {
+		t.Fatalf("SaveGraph failed: %v", err)
+	}
+	if reextracted[0].ID != a || reextracted[1].ID != c {
+		t.Errorf("expected re-extracted nodes to keep IDs %d and %d, got %d and %d", a, c, reextracted[0].ID, reextracted[1].ID)
+	}
+	if n, _ := repo.GetNode(ctx, b); n == nil || n.Name != "b2" || n.Source != entity.SourceHuman {
+		t.Fatalf("expected the curated node to be kept, got %+v", n)
+	}
+	edge := &entity.Edge{DocumentID: docID, SourceNodeID: c, TargetNodeID: a, RelationType: "NEAR", Source: entity.SourceHuman}
+	if err := repo.CreateEdge(ctx, edge); err != nil {
+		t.Fatalf("CreateEdge failed: %v", err)
+	}
+	if _, err := repo.MergeNodes(ctx, b, []int64{c}); err != nil {
+		t.Fatalf("MergeNodes failed: %v", err)
+	}
+	merged, err := repo.GetEdge(ctx, edge.ID)
+	if err != nil || merged == nil {
+		t.Fatalf("GetEdge failed: %v", err)
+	}
+	if merged.SourceNodeID != b || merged.TargetNodeID != a {
+		t.Errorf("expected the edge to move to %d -> %d, got %d -> %d", b, a, merged.SourceNodeID, merged.TargetNodeID)
+	}
+	if n, _ := repo.GetNode(ctx, c); n != nil {
+		t.Errorf("expected merged node %d to be deleted", c)
+	}
+
+	// 5. Delete
+	if err := repo.DeleteByDocumentID(ctx, docID); err != nil {
+		t.Fatalf("DeleteByDocumentID failed: %v", err)
+	}
+	if n, _ := repo.GetNode(ctx, a); n != nil {
+		t.Errorf("expected node %d to be deleted", a)
+	}
+}
+
+func TestNeo4jGraphRepository_DenseNeighbors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	repo := connectNeo4j(ctx, t)
+	defer repo.Close(ctx)
+
+	docID := time.Now().UnixNano()
+	defer repo.DeleteByDocumentID(ctx, docID)
+
+	// A clique of 30 nodes has far too many paths of length 4 to enumerate, plus one node
+	// hanging off it
+	var nodes []*entity.Node
+	var edges []*entity.Edge
+	for i := int64(1); i <= 31; i++ {
+		nodes = append(nodes, &entity.Node{ID: -i, Label: "Person", Name: fmt.Sprintf("n%d", i)})
+	}
+	for a := int64(1); a <= 30; a++ {
+		for b := a + 1; b <= 30; b++ {
+			edges = append(edges, &entity.Edge{SourceNodeID: -a, TargetNodeID: -b, RelationType: "KNOWS"})
+		}
+	}
+	edges = append(edges, &entity.Edge{SourceNodeID: -30, TargetNodeID: -31, RelationType: "KNOWS"})
+	if err := repo.SaveGraph(ctx, docID, nodes, edges); err != nil {
+		t.Fatalf("SaveGraph failed: %v", err)
+	}
+
+	neighbors, neighborEdges, err := repo.GetNeighbors(ctx, nodes[0].ID, NeighborQuery{Direction: DirectionBoth, Depth: 4})
+	if err != nil {
+		t.Fatalf("GetNeighbors failed: %v", err)
+	}
+	if len(neighbors) != 31 || len(neighborEdges) != len(edges) {
+		t.Errorf("expected 31 nodes and %d edges, got %d and %d", len(edges), len(neighbors), len(neighborEdges))
+	}
+
+	subNodes, _, err := repo.GetSubgraph(ctx, nodes[0].ID, 1, 10, nil)
+	if err != nil {
+		t.Fatalf("GetSubgraph failed: %v", err)
+	}
+	if len(subNodes) != 10 {
+		t.Errorf("expected the subgraph to be limited to 10 nodes, got %d", len(subNodes))
+	}
+}
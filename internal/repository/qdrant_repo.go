@@ -37,16 +37,25 @@ func (r *QdrantVectorRepository) Close() error {
 	return r.conn.Close()
 }
 
-// CreateCollection creates a new collection
-func (r *QdrantVectorRepository) CreateCollection(ctx context.Context, name string, vectorSize int) error {
-	// Check if exists first
+// CollectionExists reports whether a collection exists
+func (r *QdrantVectorRepository) CollectionExists(ctx context.Context, name string) (bool, error) {
 	exists, err := r.collectionsClient.CollectionExists(ctx, &pb.CollectionExistsRequest{
 		CollectionName: name,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to check collection existence: %w", err)
+		return false, fmt.Errorf("failed to check collection existence: %w", err)
 	}
-	if exists.Result != nil && exists.Result.Exists {
+	return exists.Result != nil && exists.Result.Exists, nil
+}
+
+// CreateCollection creates a new collection
+func (r *QdrantVectorRepository) CreateCollection(ctx context.Context, name string, vectorSize int) error {
+	// Check if exists first
+	exists, err := r.CollectionExists(ctx, name)
+	if err != nil {
+		return err
+	}
+	if exists {
 		return nil
 	}
 
@@ -64,6 +73,16 @@ func (r *QdrantVectorRepository) CreateCollection(ctx context.Context, name stri
 	if err != nil {
 		return fmt.Errorf("failed to create collection: %w", err)
 	}
+
+	// Searches and deletes filter by document
+	_, err = r.pointsClient.CreateFieldIndex(ctx, &pb.CreateFieldIndexCollection{
+		CollectionName: name,
+		FieldName:      "document_id",
+		FieldType:      pb.FieldType_FieldTypeInteger.Enum(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create document_id index: %w", err)
+	}
 	return nil
 }
 
@@ -82,23 +101,6 @@ func (r *QdrantVectorRepository) DeleteCollection(ctx context.Context, name stri
 func (r *QdrantVectorRepository) Upsert(ctx context.Context, collection string, points []*entity.VectorPoint) error {
 	qPoints := make([]*pb.PointStruct, len(points))
 	for i, p := range points {
-		// Convert payload map to struct
-		payload := make(map[string]*pb.Value)
-		for k, v := range p.Payload {
-			// Simple conversion for strings and numbers
-			// For complex types, more robust conversion needed
-			switch val := v.(type) {
-			case string:
-				payload[k] = &pb.Value{Kind: &pb.Value_StringValue{StringValue: val}}
-			case int:
-				payload[k] = &pb.Value{Kind: &pb.Value_IntegerValue{IntegerValue: int64(val)}}
-			case int64:
-				payload[k] = &pb.Value{Kind: &pb.Value_IntegerValue{IntegerValue: val}}
-			case float64:
-				payload[k] = &pb.Value{Kind: &pb.Value_DoubleValue{DoubleValue: val}}
-			}
-		}
-
 		qPoints[i] = &pb.PointStruct{
 			Id: &pb.PointId{
 				PointIdOptions: &pb.PointId_Uuid{Uuid: p.ID},
@@ -106,7 +108,7 @@ func (r *QdrantVectorRepository) Upsert(ctx context.Context, collection string,
 			Vectors: &pb.Vectors{
 				VectorsOptions: &pb.Vectors_Vector{Vector: &pb.Vector{Data: p.Vector}},
 			},
-			Payload: payload,
+			Payload: toQdrantPayload(p.Payload),
 		}
 	}
 
@@ -120,11 +122,22 @@ func (r *QdrantVectorRepository) Upsert(ctx context.Context, collection string,
 	return nil
 }
 
-// Search find nearest neighbors
-func (r *QdrantVectorRepository) Search(ctx context.Context, collection string, vector []float32, limit int, scoreThreshold float32) ([]SearchResult, error) {
+// Search find nearest neighbors, among the points of docIDs unless it is nil
+func (r *QdrantVectorRepository) Search(ctx context.Context, collection string, vector []float32, limit int, scoreThreshold float32, docIDs []int64) ([]SearchResult, error) {
+	var filter *pb.Filter
+	if docIDs != nil {
+		if len(docIDs) == 0 {
+			return []SearchResult{}, nil
+		}
+		filter = &pb.Filter{
+			Must: []*pb.Condition{pb.NewMatchInts("document_id", docIDs...)},
+		}
+	}
+
 	res, err := r.pointsClient.Search(ctx, &pb.SearchPoints{
 		CollectionName: collection,
 		Vector:         vector,
+		Filter:         filter,
 		Limit:          uint64(limit),
 		ScoreThreshold: &scoreThreshold,
 		WithPayload: &pb.WithPayloadSelector{
@@ -137,30 +150,10 @@ func (r *QdrantVectorRepository) Search(ctx context.Context, collection string,
 
 	results := make([]SearchResult, len(res.Result))
 	for i, hit := range res.Result {
-		// Convert payload back to map
-		payload := make(map[string]interface{})
-		for k, v := range hit.Payload {
-			switch knd := v.Kind.(type) {
-			case *pb.Value_StringValue:
-				payload[k] = knd.StringValue
-			case *pb.Value_IntegerValue:
-				payload[k] = knd.IntegerValue
-			case *pb.Value_DoubleValue:
-				payload[k] = knd.DoubleValue
-			}
-		}
-
-		id := ""
-		if hit.Id != nil {
-			if uuid := hit.Id.GetUuid(); uuid != "" {
-				id = uuid
-			}
-		}
-
 		results[i] = SearchResult{
-			ID:      id,
+			ID:      hit.Id.GetUuid(),
 			Score:   hit.Score,
-			Payload: payload,
+			Payload: fromQdrantPayload(hit.Payload),
 		}
 	}
 	return results, nil
@@ -188,3 +181,139 @@ func (r *QdrantVectorRepository) Delete(ctx context.Context, collection string,
 	}
 	return nil
 }
+
+// Get retrieves points, including their vectors, by ID. Missing IDs are skipped.
+func (r *QdrantVectorRepository) Get(ctx context.Context, collection string, ids []string) ([]*entity.VectorPoint, error) {
+	if len(ids) == 0 {
+		return []*entity.VectorPoint{}, nil
+	}
+
+	pointIDs := make([]*pb.PointId, len(ids))
+	for i, id := range ids {
+		pointIDs[i] = &pb.PointId{
+			PointIdOptions: &pb.PointId_Uuid{Uuid: id},
+		}
+	}
+
+	res, err := r.pointsClient.Get(ctx, &pb.GetPoints{
+		CollectionName: collection,
+		Ids:            pointIDs,
+		WithPayload: &pb.WithPayloadSelector{
+			SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: true},
+		},
+		WithVectors: &pb.WithVectorsSelector{
+			SelectorOptions: &pb.WithVectorsSelector_Enable{Enable: true},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get points: %w", err)
+	}
+
+	points := make([]*entity.VectorPoint, len(res.Result))
+	for i, p := range res.Result {
+		points[i] = &entity.VectorPoint{
+			ID:      p.Id.GetUuid(),
+			Vector:  vectorData(p.Vectors),
+			Payload: fromQdrantPayload(p.Payload),
+		}
+	}
+	return points, nil
+}
+
+// DeleteByDocumentID removes all points whose payload document_id matches docID.
+// A missing collection holds no points, so it is not an error.
+func (r *QdrantVectorRepository) DeleteByDocumentID(ctx context.Context, collection string, docID int64) error {
+	exists, err := r.CollectionExists(ctx, collection)
+	if err != nil || !exists {
+		return err
+	}
+
+	_, err = r.pointsClient.Delete(ctx, &pb.DeletePoints{
+		CollectionName: collection,
+		Points: &pb.PointsSelector{
+			PointsSelectorOneOf: &pb.PointsSelector_Filter{
+				Filter: &pb.Filter{
+					Must: []*pb.Condition{pb.NewMatchInt("document_id", docID)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete points by document: %w", err)
+	}
+	return nil
+}
+
+// Scroll pages through the points of a collection without their vectors.
+// Pass the returned offset to fetch the next page; an empty offset means the end was reached.
+func (r *QdrantVectorRepository) Scroll(ctx context.Context, collection string, offset string, limit int) ([]*entity.VectorPoint, string, error) {
+	lim := uint32(limit)
+	req := &pb.ScrollPoints{
+		CollectionName: collection,
+		Limit:          &lim,
+		WithPayload: &pb.WithPayloadSelector{
+			SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: true},
+		},
+	}
+	if offset != "" {
+		req.Offset = &pb.PointId{PointIdOptions: &pb.PointId_Uuid{Uuid: offset}}
+	}
+
+	res, err := r.pointsClient.Scroll(ctx, req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scroll points: %w", err)
+	}
+
+	points := make([]*entity.VectorPoint, len(res.Result))
+	for i, p := range res.Result {
+		points[i] = &entity.VectorPoint{
+			ID:      p.Id.GetUuid(),
+			Payload: fromQdrantPayload(p.Payload),
+		}
+	}
+	return points, res.NextPageOffset.GetUuid(), nil
+}
+
+// toQdrantPayload converts a payload map into Qdrant values.
+// Only strings and numbers are supported; other types are dropped.
+func toQdrantPayload(in map[string]interface{}) map[string]*pb.Value {
+	payload := make(map[string]*pb.Value, len(in))
+	for k, v := range in {
+		switch val := v.(type) {
+		case string:
+			payload[k] = &pb.Value{Kind: &pb.Value_StringValue{StringValue: val}}
+		case int:
+			payload[k] = &pb.Value{Kind: &pb.Value_IntegerValue{IntegerValue: int64(val)}}
+		case int64:
+			payload[k] = &pb.Value{Kind: &pb.Value_IntegerValue{IntegerValue: val}}
+		case float64:
+			payload[k] = &pb.Value{Kind: &pb.Value_DoubleValue{DoubleValue: val}}
+		}
+	}
+	return payload
+}
+
+// fromQdrantPayload converts Qdrant values back into a plain map.
+func fromQdrantPayload(in map[string]*pb.Value) map[string]interface{} {
+	payload := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		switch knd := v.Kind.(type) {
+		case *pb.Value_StringValue:
+			payload[k] = knd.StringValue
+		case *pb.Value_IntegerValue:
+			payload[k] = knd.IntegerValue
+		case *pb.Value_DoubleValue:
+			payload[k] = knd.DoubleValue
+		}
+	}
+	return payload
+}
+
+// vectorData extracts the default dense vector from a retrieved point.
+func vectorData(v *pb.VectorsOutput) []float32 {
+	vec := v.GetVector()
+	if dense := vec.GetDense(); dense != nil {
+		return dense.GetData()
+	}
+	return vec.GetData()
+}
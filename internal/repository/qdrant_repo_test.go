@@ -38,12 +38,12 @@ func TestQdrantVectorRepository_Integration(t *testing.T) {
 		{
 			ID:      uuid.New().String(),
 			Vector:  []float32{0.1, 0.2, 0.3, 0.4},
-			Payload: map[string]interface{}{"type": "test", "index": 1},
+			Payload: map[string]interface{}{"type": "test", "index": 1, "document_id": 1},
 		},
 		{
 			ID:      uuid.New().String(),
 			Vector:  []float32{0.9, 0.8, 0.7, 0.6},
-			Payload: map[string]interface{}{"type": "test", "index": 2},
+			Payload: map[string]interface{}{"type": "test", "index": 2, "document_id": 2},
 		},
 	}
 
@@ -58,7 +58,7 @@ func TestQdrantVectorRepository_Integration(t *testing.T) {
 	// 3. Search
 	// Query close to first point
 	query := []float32{0.1, 0.2, 0.3, 0.4}
-	results, err := repo.Search(ctx, collectionName, query, 5, 0.0)
+	results, err := repo.Search(ctx, collectionName, query, 5, 0.0, nil)
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -77,6 +77,15 @@ func TestQdrantVectorRepository_Integration(t *testing.T) {
 		t.Errorf("Expected payload 'type'='test', got %v", val)
 	}
 
+	// Filtered by document, the query only reaches the second point
+	results, err = repo.Search(ctx, collectionName, query, 5, 0.0, []int64{2})
+	if err != nil {
+		t.Fatalf("Filtered search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != points[1].ID {
+		t.Errorf("Expected only the point of document 2, got %v", results)
+	}
+
 	// 4. Delete
 	idsToDelete := []string{points[0].ID}
 	err = repo.Delete(ctx, collectionName, idsToDelete)
@@ -86,7 +95,7 @@ func TestQdrantVectorRepository_Integration(t *testing.T) {
 
 	// Verify deleted
 	time.Sleep(1 * time.Second)
-	results, err = repo.Search(ctx, collectionName, query, 5, 0.0)
+	results, err = repo.Search(ctx, collectionName, query, 5, 0.0, nil)
 	if err != nil {
 		t.Fatalf("Search after delete failed: %v", err)
 	}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+)
+
+// trigrams returns the set of trigrams of s the way pg_trgm extracts them: s is lowercased
+// and split into words of letters and digits, and each word is padded with two spaces in
+// front and one behind.
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// trigramSimilarity is pg_trgm's similarity: the shared trigrams of a and b over all their
+// trigrams.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	if total := len(ta) + len(tb) - shared; total > 0 {
+		return float64(shared) / float64(total)
+	}
+	return 0
+}
+
+// wordSimilarity approximates pg_trgm's word_similarity: the greatest similarity between
+// name and a run of consecutive words of text, trying runs up to one word longer than name.
+func wordSimilarity(name, text string) float64 {
+	words := strings.Fields(text)
+	maxRun := len(strings.Fields(name)) + 1
+	best := 0.0
+	for i := range words {
+		for j := i + 1; j <= min(len(words), i+maxRun); j++ {
+			best = max(best, trigramSimilarity(name, strings.Join(words[i:j], " ")))
+		}
+	}
+	return best
+}
@@ -0,0 +1,28 @@
+package repository
+
+import "testing"
+
+func TestTrigramSimilarity(t *testing.T) {
+	if got := trigramSimilarity("Ada Lovelace", "ada lovelace"); got != 1 {
+		t.Errorf("case-insensitive similarity = %v, want 1", got)
+	}
+	if got := trigramSimilarity("Ada", "Babbage"); got != 0 {
+		t.Errorf("disjoint similarity = %v, want 0", got)
+	}
+	if got := trigramSimilarity("", ""); got != 0 {
+		t.Errorf("empty similarity = %v, want 0", got)
+	}
+}
+
+func TestWordSimilarity(t *testing.T) {
+	question := "Who did Ada Lovelce work with on the Analytical Engine?"
+	if got := wordSimilarity("Ada Lovelace", question); got < 0.5 {
+		t.Errorf("misspelled name similarity = %v, want at least 0.5", got)
+	}
+	if got := wordSimilarity("Analytical Engine", question); got != 1 {
+		t.Errorf("exact name similarity = %v, want 1", got)
+	}
+	if got := wordSimilarity("Charles Babbage", question); got > 0.2 {
+		t.Errorf("absent name similarity = %v, want at most 0.2", got)
+	}
+}
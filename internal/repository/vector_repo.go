@@ -19,15 +19,28 @@ type VectorRepository interface {
 	// CreateCollection creates a new collection if it doesn't exist
 	CreateCollection(ctx context.Context, name string, vectorSize int) error
 
+	// CollectionExists reports whether a collection exists
+	CollectionExists(ctx context.Context, name string) (bool, error)
+
 	// DeleteCollection deletes a collection
 	DeleteCollection(ctx context.Context, name string) error
 
 	// Upsert stores or updates vectors in a collection
 	Upsert(ctx context.Context, collection string, points []*entity.VectorPoint) error
 
-	// Search finds the nearest neighbors for a query vector
-	Search(ctx context.Context, collection string, vector []float32, limit int, scoreThreshold float32) ([]SearchResult, error)
+	// Search finds the nearest neighbors for a query vector. A nil docIDs searches every
+	// point; otherwise only the points whose document_id is in docIDs are searched.
+	Search(ctx context.Context, collection string, vector []float32, limit int, scoreThreshold float32, docIDs []int64) ([]SearchResult, error)
 
 	// Delete removes points by ID
 	Delete(ctx context.Context, collection string, ids []string) error
+
+	// Get retrieves points with their vectors and payloads by ID
+	Get(ctx context.Context, collection string, ids []string) ([]*entity.VectorPoint, error)
+
+	// DeleteByDocumentID removes all points belonging to a document
+	DeleteByDocumentID(ctx context.Context, collection string, docID int64) error
+
+	// Scroll pages through point IDs and payloads; an empty next offset means no more pages
+	Scroll(ctx context.Context, collection string, offset string, limit int) (points []*entity.VectorPoint, next string, err error)
 }
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/pkg/llm"
+)
+
+const (
+	defaultAgentSteps = 5
+	// MaxAgentSteps bounds the tool calls of an agent answer.
+	MaxAgentSteps = 10
+
+	agentPassages        = 5
+	agentEntities        = 5
+	agentNeighbors       = 30
+	minAgentEntityLookup = 0.3
+)
+
+// Agent tools.
+const (
+	toolSearchText   = "search_text"
+	toolFindEntity   = "find_entity"
+	toolGetNeighbors = "get_neighbors"
+	toolFindPath     = "find_path"
+)
+
+// AgentStep is one tool call of an agent answer.
+type AgentStep struct {
+	Tool   string         `json:"tool"`
+	Args   map[string]any `json:"args"`
+	Result map[string]any `json:"result,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+var agentTools = []llm.Tool{
+	{
+		Name:        toolSearchText,
+		Description: "Search the documents for the passages most relevant to a query.",
+		Parameters: map[string]llm.Parameter{
+			"query": {Type: llm.TypeString, Description: "What to search for"},
+		},
+		Required: []string{"query"},
+	},
+	{
+		Name:        toolFindEntity,
+		Description: "Find entities of the knowledge graph by name, returning their IDs, labels and descriptions.",
+		Parameters: map[string]llm.Parameter{
+			"name": {Type: llm.TypeString, Description: "Name of the entity, as precise as possible"},
+		},
+		Required: []string{"name"},
+	},
+	{
+		Name:        toolGetNeighbors,
+		Description: "List the relationships of an entity and the entities at their other end.",
+		Parameters: map[string]llm.Parameter{
+			"entity_id":     {Type: llm.TypeInteger, Description: "ID of the entity, from find_entity or an earlier result"},
+			"relation_type": {Type: llm.TypeString, Description: "Only follow relationships of this type (optional)"},
+		},
+		Required: []string{"entity_id"},
+	},
+	{
+		Name:        toolFindPath,
+		Description: "Find the shortest chain of relationships connecting two entities.",
+		Parameters: map[string]llm.Parameter{
+			"source_id": {Type: llm.TypeInteger, Description: "ID of the first entity"},
+			"target_id": {Type: llm.TypeInteger, Description: "ID of the second entity"},
+		},
+		Required: []string{"source_id", "target_id"},
+	},
+}
+
+// agentChat lets the LLM answer by calling tools over the notebook's documents and graph,
+// one lookup building on the last, until it answers or runs out of steps. Every tool call
+// is returned in the response's steps.
+func (s *chatService) agentChat(ctx context.Context, notebookID int64, req ChatRequest) (*ChatResponse, error) {
+	maxSteps := req.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultAgentSteps
+	}
+	maxSteps = min(maxSteps, MaxAgentSteps)
+
+	prompt := fmt.Sprintf(`You are a helpful assistant for a Knowledge Graph application.
+Answer the User's Question about a collection of documents using the tools to look things up.
+Find entities with find_entity to get their IDs, then follow their relationships with get_neighbors and find_path. Use search_text for facts the graph may not hold.
+Break complex questions into several lookups, each building on the previous results.
+Base the answer only on tool results. If they do not contain the answer, say you don't know.
+%s
+User Question: %s`, agentAsOfNote(req.AsOf), req.Query)
+
+	resp := &ChatResponse{Mode: ChatModeAgent, Steps: []*AgentStep{}}
+	messages := []llm.Message{{Role: llm.RoleUser, Text: prompt}}
+	tools := agentTools
+	for {
+		turn, err := s.llmClient.GenerateWithTools(ctx, messages, tools)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run agent step: %w", err)
+		}
+		if len(turn.ToolCalls) == 0 || tools == nil {
+			resp.Answer = turn.Text
+			if req.Verify || req.Regenerate {
+				s.checkAnswer(ctx, req.Query, agentEvidence(resp.Steps), req.Regenerate, resp)
+			}
+			return resp, nil
+		}
+		messages = append(messages, llm.Message{Role: llm.RoleModel, Text: turn.Text, ToolCalls: turn.ToolCalls})
+
+		results := llm.Message{Role: llm.RoleTool}
+		for _, call := range turn.ToolCalls {
+			if len(resp.Steps) == maxSteps {
+				results.ToolResults = append(results.ToolResults, llm.ToolResult{
+					Name: call.Name, Result: map[string]any{"error": "step limit reached, tool not called"},
+				})
+				continue
+			}
+			step := &AgentStep{Tool: call.Name, Args: call.Args}
+			step.Result, err = s.callTool(ctx, notebookID, req.AsOf, call)
+			if err != nil {
+				step.Error = err.Error()
+				step.Result = nil
+			}
+			resp.Steps = append(resp.Steps, step)
+
+			result := step.Result
+			if step.Error != "" {
+				result = map[string]any{"error": step.Error}
+			}
+			results.ToolResults = append(results.ToolResults, llm.ToolResult{Name: call.Name, Result: result})
+		}
+		if len(resp.Steps) == maxSteps {
+			// Last turn: no more tools, answer from what was found
+			results.Text = "You have used all your lookups. Answer the question now from the results so far."
+			tools = nil
+		}
+		messages = append(messages, results)
+	}
+}
+
+// agentEvidence renders the successful tool results of an agent answer as the context to
+// verify it against.
+func agentEvidence(steps []*AgentStep) string {
+	var sb strings.Builder
+	for _, step := range steps {
+		if step.Result == nil {
+			continue
+		}
+		result, err := json.Marshal(step.Result)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s: %s\n", step.Tool, result)
+	}
+	return sb.String()
+}
+
+// agentAsOfNote restricts the agent's answer to a day, or returns "" without one.
+func agentAsOfNote(asOf *entity.Date) string {
+	if asOf == nil {
+		return ""
+	}
+	return fmt.Sprintf("Answer as of %s: relationship lookups only return the relationships that held on that date.\n", asOf)
+}
+
+// callTool runs one tool call. Errors are reported to the model, which may retry.
+func (s *chatService) callTool(ctx context.Context, notebookID int64, asOf *entity.Date, call llm.ToolCall) (map[string]any, error) {
+	switch call.Name {
+	case toolSearchText:
+		query, err := stringArg(call.Args, "query")
+		if err != nil {
+			return nil, err
+		}
+		chunks, err := s.retrieval.Retrieve(ctx, notebookID, RetrievalQuery{Query: query, TopK: agentPassages})
+		if err != nil {
+			return nil, err
+		}
+		passages := make([]any, len(chunks))
+		for i, c := range chunks {
+			passages[i] = map[string]any{"document_id": c.DocumentID, "text": c.Content}
+		}
+		return map[string]any{"passages": passages}, nil
+
+	case toolFindEntity:
+		name, err := stringArg(call.Args, "name")
+		if err != nil {
+			return nil, err
+		}
+		matches, err := s.graphRepo.MatchNodeNames(ctx, notebookID, name, minAgentEntityLookup, agentEntities)
+		if err != nil {
+			return nil, err
+		}
+		nodes := make([]*entity.Node, len(matches))
+		for i, m := range matches {
+			nodes[i] = &m.Node
+		}
+		if len(nodes) == 0 {
+			// No similar name: look for a synonym or description by meaning
+			similar, err := s.linker.SearchEntities(ctx, notebookID, name, agentEntities)
+			if err != nil {
+				fmt.Printf("Warning: agent entity search failed: %v\n", err)
+			}
+			for _, m := range similar {
+				nodes = append(nodes, m.Node)
+			}
+		}
+		entities := make([]any, len(nodes))
+		for i, n := range nodes {
+			entities[i] = agentEntity(n)
+		}
+		return map[string]any{"entities": entities}, nil
+
+	case toolGetNeighbors:
+		id, err := int64Arg(call.Args, "entity_id")
+		if err != nil {
+			return nil, err
+		}
+		if err := s.ensureNotebookNode(ctx, notebookID, id); err != nil {
+			return nil, err
+		}
+		q := repository.NeighborQuery{Direction: repository.DirectionBoth, Depth: 1, Limit: agentNeighbors, AsOf: asOf}
+		if relation, _ := call.Args["relation_type"].(string); relation != "" {
+			q.RelationTypes = []string{relation}
+		}
+		nodes, edges, err := s.graphRepo.GetNeighbors(ctx, id, q)
+		if err != nil {
+			return nil, err
+		}
+		entities := make([]any, 0, len(nodes))
+		for _, n := range nodes {
+			if n.ID != id {
+				entities = append(entities, agentEntity(n))
+			}
+		}
+		return map[string]any{"relationships": relationshipLines(nodes, edges), "entities": entities}, nil
+
+	case toolFindPath:
+		from, err := int64Arg(call.Args, "source_id")
+		if err != nil {
+			return nil, err
+		}
+		to, err := int64Arg(call.Args, "target_id")
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range []int64{from, to} {
+			if err := s.ensureNotebookNode(ctx, notebookID, id); err != nil {
+				return nil, err
+			}
+		}
+		nodes, edges, err := s.graphRepo.ShortestPath(ctx, from, to, repository.DirectionBoth, maxTraversalDepth, asOf)
+		if err != nil {
+			return nil, err
+		}
+		if len(edges) == 0 {
+			return map[string]any{"path": []any{}, "note": fmt.Sprintf("not connected within %d relationships", maxTraversalDepth)}, nil
+		}
+		return map[string]any{"path": relationshipLines(nodes, edges)}, nil
+	}
+	return nil, fmt.Errorf("unknown tool %q", call.Name)
+}
+
+// ensureNotebookNode checks that a node the model asked about belongs to the notebook.
+func (s *chatService) ensureNotebookNode(ctx context.Context, notebookID, id int64) error {
+	node, err := s.graphRepo.GetNode(ctx, id)
+	if err != nil {
+		return err
+	}
+	if node != nil {
+		doc, err := s.docRepo.GetByID(ctx, node.DocumentID)
+		if err != nil {
+			return err
+		}
+		if doc != nil && doc.NotebookID != nil && *doc.NotebookID == notebookID {
+			return nil
+		}
+	}
+	return fmt.Errorf("entity %d not found", id)
+}
+
+// agentEntity describes a node to the model.
+func agentEntity(n *entity.Node) map[string]any {
+	e := map[string]any{"id": n.ID, "name": n.Name, "label": n.Label}
+	if description := nodeDescription(n); description != "" {
+		e["description"] = description
+	}
+	return e
+}
+
+// relationshipLines renders edges as "Source --[TYPE]--> Target" lines. Results hold []any
+// rather than typed slices, as tool results must convert to protobuf structs.
+func relationshipLines(nodes []*entity.Node, edges []*entity.Edge) []any {
+	names := make(map[int64]string, len(nodes))
+	for _, n := range nodes {
+		names[n.ID] = n.Name
+	}
+	lines := make([]any, 0, len(edges))
+	for _, e := range edges {
+		source, ok1 := names[e.SourceNodeID]
+		target, ok2 := names[e.TargetNodeID]
+		if ok1 && ok2 {
+			lines = append(lines, fmt.Sprintf("%s --[%s]--> %s%s", source, e.RelationType, target, validityNote(e)))
+		}
+	}
+	return lines
+}
+
+func stringArg(args map[string]any, key string) (string, error) {
+	v, _ := args[key].(string)
+	if strings.TrimSpace(v) == "" {
+		return "", fmt.Errorf("%s is required", key)
+	}
+	return v, nil
+}
+
+// int64Arg reads an integer argument, which arrives as a JSON number.
+func int64Arg(args map[string]any, key string) (int64, error) {
+	switch v := args[key].(type) {
+	case float64:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("%s must be an integer", key)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/suyw-0123/graphweaver/pkg/llm"
+)
+
+// scriptedLLM calls an unknown tool until it is offered no tools, then answers.
+type scriptedLLM struct{ turns int }
+
+func (s *scriptedLLM) GenerateContent(ctx context.Context, prompt string) (string, error) {
+	return "", nil
+}
+
+func (s *scriptedLLM) GenerateWithTools(ctx context.Context, messages []llm.Message, tools []llm.Tool) (*llm.ToolResponse, error) {
+	s.turns++
+	if tools == nil {
+		return &llm.ToolResponse{Text: "done"}, nil
+	}
+	return &llm.ToolResponse{ToolCalls: []llm.ToolCall{
+		{Name: "lookup", Args: map[string]any{"n": float64(s.turns)}},
+		{Name: "lookup", Args: map[string]any{"n": float64(s.turns)}},
+	}}, nil
+}
+
+func (s *scriptedLLM) Close() error { return nil }
+
+func TestAgentChatStepLimit(t *testing.T) {
+	client := &scriptedLLM{}
+	svc := &chatService{llmClient: client}
+
+	resp, err := svc.agentChat(context.Background(), 1, ChatRequest{Query: "q", Mode: ChatModeAgent, MaxSteps: 3})
+	if err != nil {
+		t.Fatalf("agentChat failed: %v", err)
+	}
+	if resp.Answer != "done" || resp.Mode != ChatModeAgent {
+		t.Errorf("answer = %q in mode %q, want done in agent mode", resp.Answer, resp.Mode)
+	}
+	if len(resp.Steps) != 3 {
+		t.Fatalf("steps = %d, want 3", len(resp.Steps))
+	}
+	for _, step := range resp.Steps {
+		if step.Tool != "lookup" || step.Error == "" || step.Result != nil {
+			t.Errorf("step = %+v, want a failed lookup", step)
+		}
+	}
+	// Two turns with tools use the three steps, the third turn answers
+	if client.turns != 3 {
+		t.Errorf("turns = %d, want 3", client.turns)
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/pkg/embedding"
+)
+
+// Cache outcomes reported in ChatResponse.Cache.
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// ChatCacheConfig tunes the semantic chat cache.
+type ChatCacheConfig struct {
+	// TTL is how long an answer is reused (default 24h)
+	TTL time.Duration
+	// MinSimilarity is the cosine similarity between question embeddings above which a
+	// cached answer is reused (default 0.95)
+	MinSimilarity float64
+}
+
+type cachedChatService struct {
+	chat            ChatService
+	cache           repository.ChatCacheRepository
+	notebookRepo    repository.NotebookRepository
+	embeddingClient embedding.Client
+	cfg             ChatCacheConfig
+}
+
+// NewCachedChatService wraps chat with a cache of answers to similar questions. Answers are
+// keyed on the notebook's content version, so any change to its documents or graph invalidates
+// them.
+func NewCachedChatService(
+	chat ChatService,
+	cache repository.ChatCacheRepository,
+	notebookRepo repository.NotebookRepository,
+	embeddingClient embedding.Client,
+	cfg ChatCacheConfig,
+) ChatService {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.MinSimilarity <= 0 {
+		cfg.MinSimilarity = 0.95
+	}
+	return &cachedChatService{
+		chat:            chat,
+		cache:           cache,
+		notebookRepo:    notebookRepo,
+		embeddingClient: embeddingClient,
+		cfg:             cfg,
+	}
+}
+
+// Retrieve is not cached; retrieval is cheap next to answering.
+func (s *cachedChatService) Retrieve(ctx context.Context, notebookID int64, req ChatRequest, q RetrievalQuery) ([]*RetrievedChunk, []string, error) {
+	return s.chat.Retrieve(ctx, notebookID, req, q)
+}
+
+func (s *cachedChatService) Chat(ctx context.Context, notebookID int64, req ChatRequest) (*ChatResponse, error) {
+	notebook, err := s.notebookRepo.GetByID(ctx, notebookID)
+	if err != nil {
+		return s.chat.Chat(ctx, notebookID, req)
+	}
+	vector, err := s.embeddingClient.EmbedText(ctx, req.Query)
+	if err != nil {
+		fmt.Printf("Warning: failed to embed question for chat cache: %v\n", err)
+		return s.chat.Chat(ctx, notebookID, req)
+	}
+	key, err := requestKey(req)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to build chat cache key: %w", err)
+	}
+
+	entry, _, err := s.cache.Find(ctx, notebookID, notebook.ContentVersion, key, vector, s.cfg.MinSimilarity)
+	if err != nil {
+		fmt.Printf("Warning: chat cache lookup failed: %v\n", err)
+	} else if entry != nil {
+		var cached ChatResponse
+		if err := json.Unmarshal(entry.Response, &cached); err == nil {
+			cached.Cache = CacheHit
+			return &cached, nil
+		}
+		fmt.Printf("Warning: ignoring unreadable chat cache entry %d: %v\n", entry.ID, err)
+	}
+
+	resp, err := s.chat.Chat(ctx, notebookID, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Cache = CacheMiss
+
+	// The version read before answering is stored, so an answer racing a document change is
+	// never served for the new content
+	body, err := json.Marshal(resp)
+	if err == nil {
+		err = s.cache.Save(ctx, &repository.ChatCacheEntry{
+			NotebookID:     notebookID,
+			ContentVersion: notebook.ContentVersion,
+			RequestKey:     key,
+			Query:          req.Query,
+			Embedding:      vector,
+			Response:       body,
+			ExpiresAt:      time.Now().Add(s.cfg.TTL),
+		})
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to cache chat response: %v\n", err)
+	}
+	return resp, nil
+}
+
+// requestKey encodes the options of a request other than the question; answers are only
+// shared between requests with the same options.
+func requestKey(req ChatRequest) (string, error) {
+	req.Query = ""
+	if req.Mode == "" {
+		req.Mode = ChatModeLocal
+	}
+	if req.Expansion == ExpansionNone {
+		req.Expansion = ""
+	}
+	b, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+)
+
+type versionedNotebooks struct {
+	repository.NotebookRepository
+	version int64
+}
+
+func (r *versionedNotebooks) GetByID(ctx context.Context, id int64) (*entity.Notebook, error) {
+	return &entity.Notebook{ID: id, ContentVersion: r.version}, nil
+}
+
+// wordEmbedder embeds a text as the counts of a few fixed words.
+type wordEmbedder struct{}
+
+func (wordEmbedder) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	v := make([]float32, 3)
+	for i, w := range []string{"alice", "bob", "paris"} {
+		for _, t := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+			if t == w {
+				v[i]++
+			}
+		}
+	}
+	return v, nil
+}
+
+func (e wordEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = e.EmbedText(ctx, text)
+	}
+	return vectors, nil
+}
+
+func (wordEmbedder) Close() error { return nil }
+
+type countingChat struct {
+	ChatService
+	calls int
+}
+
+func (c *countingChat) Chat(ctx context.Context, notebookID int64, req ChatRequest) (*ChatResponse, error) {
+	c.calls++
+	return &ChatResponse{Answer: "answer to " + req.Query, Mode: ChatModeLocal}, nil
+}
+
+func TestCachedChatService(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingChat{}
+	notebooks := &versionedNotebooks{}
+	svc := NewCachedChatService(inner, repository.NewMemoryChatCacheRepository(0), notebooks, wordEmbedder{}, ChatCacheConfig{})
+
+	ask := func(query string, mode string) *ChatResponse {
+		t.Helper()
+		resp, err := svc.Chat(ctx, 1, ChatRequest{Query: query, Mode: mode})
+		if err != nil {
+			t.Fatalf("Chat(%q): %v", query, err)
+		}
+		return resp
+	}
+
+	if resp := ask("Where does Alice live?", ""); resp.Cache != CacheMiss {
+		t.Errorf("first question: cache = %q, want miss", resp.Cache)
+	}
+	resp := ask("where does alice live", ChatModeLocal)
+	if resp.Cache != CacheHit || resp.Answer != "answer to Where does Alice live?" {
+		t.Errorf("similar question: got %q (%s), want cached answer", resp.Answer, resp.Cache)
+	}
+	if resp := ask("Where does Bob live?", ""); resp.Cache != CacheMiss {
+		t.Errorf("different question: cache = %q, want miss", resp.Cache)
+	}
+	if resp := ask("Where does Alice live?", ChatModeGlobal); resp.Cache != CacheMiss {
+		t.Errorf("different mode: cache = %q, want miss", resp.Cache)
+	}
+
+	notebooks.version++
+	if resp := ask("Where does Alice live?", ""); resp.Cache != CacheMiss {
+		t.Errorf("after content change: cache = %q, want miss", resp.Cache)
+	}
+	if inner.calls != 4 {
+		t.Errorf("inner chat called %d times, want 4", inner.calls)
+	}
+}
@@ -2,146 +2,211 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/suyw-0123/graphweaver/internal/entity"
 	"github.com/suyw-0123/graphweaver/internal/repository"
-	"github.com/suyw-0123/graphweaver/pkg/embedding"
 	"github.com/suyw-0123/graphweaver/pkg/llm"
 )
 
+const (
+	// maxContextEntities caps the entities per document included in the chat context.
+	maxContextEntities = 100
+
+	// communitiesPerBatch is how many community summaries go into one map step of a global query.
+	communitiesPerBatch = 8
+	// maxReducePoints caps the key points passed from the map steps to the final answer.
+	maxReducePoints = 30
+)
+
+// Chat modes. Local answers from matched chunks and document graphs; global answers
+// corpus-wide questions by map-reducing over community summaries; agent lets the LLM look
+// things up step by step with tools over the documents and graph.
+const (
+	ChatModeLocal  = "local"
+	ChatModeGlobal = "global"
+	ChatModeAgent  = "agent"
+)
+
+// ChatRequest is a question asked against a notebook.
+type ChatRequest struct {
+	Query string `json:"query"`
+	Mode  string `json:"mode,omitempty"`
+	// CommunityLevel selects the community hierarchy level used in global mode (default 0, the coarsest)
+	CommunityLevel int `json:"community_level,omitempty"`
+	// AsOf answers from the graph as it stood on that day, leaving out relationships not valid
+	// then. Community summaries are not dated, so global mode ignores it.
+	AsOf *entity.Date `json:"as_of,omitempty"`
+	// VectorWeight and LexicalWeight weigh dense and full-text search when retrieving chunks;
+	// see RetrievalQuery.
+	VectorWeight  float64 `json:"vector_weight,omitempty"`
+	LexicalWeight float64 `json:"lexical_weight,omitempty"`
+	// Expansion rewrites the question before retrieval in local mode: none (the default),
+	// hyde or multi_query. SubQueries is the number of paraphrases in multi_query mode.
+	Expansion  string `json:"expansion,omitempty"`
+	SubQueries int    `json:"sub_queries,omitempty"`
+	// MaxSteps bounds the tool calls in agent mode (default 5)
+	MaxSteps int `json:"max_steps,omitempty"`
+	// Verify checks the claims of a local or agent answer against the context it was
+	// generated from. Regenerate also rewrites answers with unsupported claims, and implies
+	// Verify.
+	Verify     bool `json:"verify,omitempty"`
+	Regenerate bool `json:"regenerate,omitempty"`
+}
+
+// ChatResponse is the answer to a ChatRequest.
+type ChatResponse struct {
+	Answer string `json:"answer"`
+	Mode   string `json:"mode"`
+	// Fallback explains why the answer was given in another mode than requested, such as
+	// local mode for a global question about a notebook without communities
+	Fallback string `json:"fallback,omitempty"`
+	// CommunityIDs lists the communities that contributed to a global answer
+	CommunityIDs []int64 `json:"community_ids,omitempty"`
+	// Entities lists the graph nodes the question was linked to in local mode
+	Entities []*LinkedEntity `json:"entities,omitempty"`
+	// ExpandedQueries lists the hypothetical answer or sub-queries retrieval used
+	ExpandedQueries []string `json:"expanded_queries,omitempty"`
+	// Steps traces the tool calls of an agent answer
+	Steps []*AgentStep `json:"steps,omitempty"`
+	// Verification reports the support of the answer's claims when verification was requested
+	Verification *Verification `json:"verification,omitempty"`
+	// Cache is hit or miss when the chat cache is enabled
+	Cache string `json:"cache,omitempty"`
+}
+
 type ChatService interface {
-	Chat(ctx context.Context, notebookID int64, query string) (string, error)
+	Chat(ctx context.Context, notebookID int64, req ChatRequest) (*ChatResponse, error)
+	// Retrieve retrieves chunks for req.Query with the request's query expansion, as local
+	// chat does, and returns the queries the expansion generated alongside.
+	Retrieve(ctx context.Context, notebookID int64, req ChatRequest, q RetrievalQuery) ([]*RetrievedChunk, []string, error)
 }
 
 type chatService struct {
-	docRepo         repository.DocumentRepository
-	graphRepo       repository.GraphRepository
-	vectorRepo      repository.VectorRepository
-	llmClient       llm.Client
-	embeddingClient embedding.Client
+	docRepo       repository.DocumentRepository
+	communityRepo repository.CommunityRepository
+	graphRepo     repository.GraphRepository
+	retrieval     RetrievalService
+	linker        EntityLinkingService
+	llmClient     llm.Client
 }
 
 func NewChatService(
 	docRepo repository.DocumentRepository,
+	communityRepo repository.CommunityRepository,
 	graphRepo repository.GraphRepository,
-	vectorRepo repository.VectorRepository,
+	retrieval RetrievalService,
+	linker EntityLinkingService,
 	llmClient llm.Client,
-	embeddingClient embedding.Client,
 ) ChatService {
 	return &chatService{
-		docRepo:         docRepo,
-		graphRepo:       graphRepo,
-		vectorRepo:      vectorRepo,
-		llmClient:       llmClient,
-		embeddingClient: embeddingClient,
+		docRepo:       docRepo,
+		communityRepo: communityRepo,
+		graphRepo:     graphRepo,
+		retrieval:     retrieval,
+		linker:        linker,
+		llmClient:     llmClient,
 	}
 }
 
-func (s *chatService) Chat(ctx context.Context, notebookID int64, query string) (string, error) {
-	// 0. Hybrid Retrieval Setup
-	var relevantDocIDs []int64
-	var textContextBuilder strings.Builder
+func (s *chatService) Chat(ctx context.Context, notebookID int64, req ChatRequest) (*ChatResponse, error) {
+	if req.Mode == ChatModeAgent {
+		return s.agentChat(ctx, notebookID, req)
+	}
+	if req.Mode == ChatModeGlobal {
+		resp, err := s.globalChat(ctx, notebookID, req)
+		if err != nil || resp != nil {
+			return resp, err
+		}
+		// No community summaries yet, answer locally instead
+		resp, err = s.localChat(ctx, notebookID, req)
+		if resp != nil {
+			resp.Fallback = fmt.Sprintf("no communities have been built at level %d, so the question was answered in local mode", req.CommunityLevel)
+		}
+		return resp, err
+	}
 
-	useVectorSearch := s.embeddingClient != nil && s.vectorRepo != nil
+	return s.localChat(ctx, notebookID, req)
+}
 
-	if useVectorSearch {
-		// A. Generate Query Embedding
-		queryVector, err := s.embeddingClient.EmbedText(ctx, query)
-		if err != nil {
-			fmt.Printf("Warning: Failed to embed query, falling back to full scan: %v\n", err)
-			useVectorSearch = false
-		} else {
-			// B. Vector Search
-			// We search in "documents" collection. In real world, filter by notebookID if possible (payload filter).
-			// Currently our VectorRepo Search doesn't support filters, so we filtered by payload manually or accept global search?
-			// The proposal didn't specify adding filtering support to Repo yet.
-			// Ideally we should filter by notebookID.
-			// For now, we search globally and filter results in code (inefficient but works for MVP).
-			results, err := s.vectorRepo.Search(ctx, "documents", queryVector, 20, 0.6)
-			if err != nil {
-				fmt.Printf("Warning: Vector search failed: %v\n", err)
-				useVectorSearch = false
-			} else {
-				// C. Process Results
-				docIDMap := make(map[int64]bool)
-				count := 0
-
-				textContextBuilder.WriteString("Relevant Text Segments:\n")
-
-				for _, res := range results {
-					if count >= 5 {
-						break
-					} // Limit to top 5 valid checks
-
-					// Extract fields
-					docIDVal, ok1 := res.Payload["document_id"]
-					contentVal, ok2 := res.Payload["content"]
-
-					if !ok1 || !ok2 {
-						continue
-					}
-
-					docID := int64(0)
-					switch v := docIDVal.(type) {
-					case int64:
-						docID = v
-					case int:
-						docID = int64(v)
-					case float64:
-						docID = int64(v) // JSON often parses numbers as floats
-					default:
-						continue
-					}
-
-					// Verify this doc belongs to the notebook?
-					// We need to fetch the doc to check notebookID.
-					// Optimization: Cache or trust?
-					// Or just check only docs belonging to current notebook.
-					// The Chat function signature has notebookID.
-
-					// We'll filter in memory: verify doc belongs to notebook
-					doc, err := s.docRepo.GetByID(ctx, docID)
-					if err != nil || doc.NotebookID == nil || *doc.NotebookID != notebookID {
-						continue
-					}
-
-					if !docIDMap[docID] {
-						docIDMap[docID] = true
-						relevantDocIDs = append(relevantDocIDs, docID)
-					}
-
-					textContextBuilder.WriteString(fmt.Sprintf("- ...%s...\n", contentVal))
-					count++
-				}
-
-				if len(relevantDocIDs) == 0 {
-					fmt.Println("No relevant chunks found via vector search for this notebook. Falling back to all documents.")
-					useVectorSearch = false
-				}
+func (s *chatService) Retrieve(ctx context.Context, notebookID int64, req ChatRequest, q RetrievalQuery) ([]*RetrievedChunk, []string, error) {
+	q.Query = req.Query
+	return s.expandedRetrieve(ctx, notebookID, req, q)
+}
+
+func (s *chatService) localChat(ctx context.Context, notebookID int64, req ChatRequest) (*ChatResponse, error) {
+	query, asOf := req.Query, req.AsOf
+	resp := &ChatResponse{Mode: ChatModeLocal}
+
+	// 1. Link the entities named in the question to graph nodes
+	linked, err := s.linker.LinkEntities(ctx, notebookID, query)
+	if err != nil {
+		fmt.Printf("Warning: Entity linking failed, retrieving without seeds: %v\n", err)
+	}
+	resp.Entities = linked
+
+	// 2. Hybrid retrieval of the most relevant chunks, seeded with the linked entities and
+	// with the question expanded as requested
+	var relevantDocIDs []int64
+	var textContextBuilder strings.Builder
+	docIDMap := make(map[int64]bool)
+
+	chunks, expanded, err := s.expandedRetrieve(ctx, notebookID, req, RetrievalQuery{
+		Query:         query,
+		TopK:          defaultRetrievalTopK,
+		VectorWeight:  req.VectorWeight,
+		LexicalWeight: req.LexicalWeight,
+		Seeds:         linkedNodes(linked),
+	})
+	resp.ExpandedQueries = expanded
+	if err != nil {
+		fmt.Printf("Warning: Retrieval failed, falling back to all documents: %v\n", err)
+	}
+	if len(chunks) > 0 {
+		textContextBuilder.WriteString("Relevant Text Segments:\n")
+		for _, c := range chunks {
+			if !docIDMap[c.DocumentID] {
+				docIDMap[c.DocumentID] = true
+				relevantDocIDs = append(relevantDocIDs, c.DocumentID)
 			}
+			textContextBuilder.WriteString(fmt.Sprintf("- ...%s...\n", c.Content))
+		}
+	}
+	for _, l := range linked {
+		if !docIDMap[l.Node.DocumentID] {
+			docIDMap[l.Node.DocumentID] = true
+			relevantDocIDs = append(relevantDocIDs, l.Node.DocumentID)
 		}
 	}
 
 	// Fallback or Basic Retrieval
-	if !useVectorSearch || len(relevantDocIDs) == 0 {
-		// 1. Get all documents for the notebook
+	if len(relevantDocIDs) == 0 {
+		// Get all documents for the notebook
 		docs, err := s.docRepo.List(ctx, 100, 0, &notebookID)
 		if err != nil {
-			return "", fmt.Errorf("failed to list documents: %w", err)
+			return nil, fmt.Errorf("failed to list documents: %w", err)
 		}
 		if len(docs) == 0 {
-			return "This notebook has no documents. Please upload some documents first.", nil
+			resp.Answer = "This notebook has no documents. Please upload some documents first."
+			return resp, nil
 		}
 		for _, d := range docs {
 			relevantDocIDs = append(relevantDocIDs, d.ID)
 		}
 	}
 
-	// 2. Collect context from Graph (for relevant documents)
+	// 3. Collect context from Graph (for linked entities and relevant documents)
 	var contextBuilder strings.Builder
 	contextBuilder.WriteString("Context information is below.\n---------------------\n")
 
+	if len(linked) > 0 {
+		contextBuilder.WriteString(s.linkedEntityContext(ctx, linked, asOf))
+		contextBuilder.WriteString("\n")
+	}
+
 	if textContextBuilder.Len() > 0 {
 		contextBuilder.WriteString(textContextBuilder.String())
 		contextBuilder.WriteString("\n")
@@ -153,7 +218,7 @@ func (s *chatService) Chat(ctx context.Context, notebookID int64, query string)
 		// Get Doc info for Source name
 		doc, err := s.docRepo.GetByID(ctx, docID)
 		filename := "Unknown"
-		if err == nil {
+		if err == nil && doc != nil {
 			filename = doc.Filename
 		}
 
@@ -165,11 +230,18 @@ func (s *chatService) Chat(ctx context.Context, notebookID int64, query string)
 		if err != nil {
 			continue
 		}
+		edges = edgesValidAt(edges, asOf)
 
 		if len(nodes) == 0 {
 			continue
 		}
 
+		// Most central entities first, so large graphs keep the important ones
+		rankNodes(nodes)
+		if len(nodes) > maxContextEntities {
+			nodes = nodes[:maxContextEntities]
+		}
+
 		// Build Node Map for Edge resolution
 		nodeMap := make(map[int64]string)
 		for _, node := range nodes {
@@ -186,17 +258,20 @@ func (s *chatService) Chat(ctx context.Context, notebookID int64, query string)
 			sourceName, ok1 := nodeMap[edge.SourceNodeID]
 			targetName, ok2 := nodeMap[edge.TargetNodeID]
 			if ok1 && ok2 {
-				contextBuilder.WriteString(fmt.Sprintf("- %s --[%s]--> %s\n", sourceName, edge.RelationType, targetName))
+				contextBuilder.WriteString(fmt.Sprintf("- %s --[%s]--> %s%s\n", sourceName, edge.RelationType, targetName, validityNote(edge)))
 			}
 		}
 	}
 
 	contextBuilder.WriteString("---------------------\n")
+	if asOf != nil {
+		fmt.Fprintf(&contextBuilder, "Answer as of %s: the relationships above are those that held on that date.\n", asOf)
+	}
 
-	// 3. Construct Prompt
+	// 4. Construct Prompt
 	prompt := fmt.Sprintf(`You are a helpful assistant for a Knowledge Graph application.
 Use the following Context to answer the User's Question.
-The Context consists of Text Segments and Graph Entities/Relationships from documents.
+The Context consists of the Entities the Question refers to, Text Segments and Graph Entities/Relationships from documents.
 If the answer is not in the context, say you don't know.
 
 Context:
@@ -206,6 +281,168 @@ User Question: %s
 
 Answer:`, contextBuilder.String(), query)
 
-	// 4. Call LLM
-	return s.llmClient.GenerateContent(ctx, prompt)
+	// 5. Call LLM
+	answer, err := s.llmClient.GenerateContent(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	resp.Answer = answer
+
+	// 6. Optionally verify the answer against the context
+	if req.Verify || req.Regenerate {
+		s.checkAnswer(ctx, query, contextBuilder.String(), req.Regenerate, resp)
+	}
+	return resp, nil
+}
+
+// linkedEntityContext describes the entities a question refers to and their direct
+// relationships, valid as of asOf when set.
+func (s *chatService) linkedEntityContext(ctx context.Context, linked []*LinkedEntity, asOf *entity.Date) string {
+	var sb strings.Builder
+	sb.WriteString("Entities in the Question:\n")
+	for _, l := range linked {
+		n := l.Node
+		fmt.Fprintf(&sb, "- %s (%s)", n.Name, n.Label)
+		if description := nodeDescription(n); description != "" {
+			fmt.Fprintf(&sb, ": %s", description)
+		}
+		sb.WriteString("\n")
+
+		neighbors, edges, err := s.graphRepo.GetNeighbors(ctx, n.ID, repository.NeighborQuery{
+			Direction: repository.DirectionBoth, Depth: 1, Limit: maxContextEntities, AsOf: asOf,
+		})
+		if err != nil {
+			fmt.Printf("Warning: failed to get relationships of entity %d: %v\n", n.ID, err)
+			continue
+		}
+		names := map[int64]string{n.ID: n.Name}
+		for _, nb := range neighbors {
+			names[nb.ID] = nb.Name
+		}
+		for _, e := range edges {
+			source, ok1 := names[e.SourceNodeID]
+			target, ok2 := names[e.TargetNodeID]
+			if ok1 && ok2 {
+				fmt.Fprintf(&sb, "  - %s --[%s]--> %s%s\n", source, e.RelationType, target, validityNote(e))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// validityNote describes when a relationship held, or returns "" if that is not known.
+func validityNote(e *entity.Edge) string {
+	switch {
+	case e.ValidFrom != nil && e.ValidTo != nil:
+		return fmt.Sprintf(" (from %s to %s)", e.ValidFrom, e.ValidTo)
+	case e.ValidFrom != nil:
+		return fmt.Sprintf(" (since %s)", e.ValidFrom)
+	case e.ValidTo != nil:
+		return fmt.Sprintf(" (until %s)", e.ValidTo)
+	}
+	return ""
+}
+
+// globalChat answers from community summaries: each batch of summaries yields rated key points
+// (map), and the best points are combined into the final answer (reduce). It returns nil if the
+// notebook has no communities at the requested level.
+func (s *chatService) globalChat(ctx context.Context, notebookID int64, req ChatRequest) (*ChatResponse, error) {
+	communities, err := s.communityRepo.ListByNotebook(ctx, notebookID, &req.CommunityLevel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list communities: %w", err)
+	}
+	if len(communities) == 0 {
+		return nil, nil
+	}
+
+	type keyPoint struct {
+		Description string `json:"description"`
+		Score       int    `json:"score"`
+	}
+	var points []keyPoint
+	resp := &ChatResponse{Mode: ChatModeGlobal}
+
+	for start := 0; start < len(communities); start += communitiesPerBatch {
+		batch := communities[start:min(start+communitiesPerBatch, len(communities))]
+
+		var sb strings.Builder
+		for _, c := range batch {
+			fmt.Fprintf(&sb, "## %s\n%s\n\n", c.Title, c.Summary)
+		}
+
+		prompt := fmt.Sprintf(`You are a helpful assistant answering a question about a collection of documents.
+Below are summaries of communities of related entities found in the documents.
+Extract the key points from these summaries that help answer the User's Question.
+Rate each point from 0 to 100 by how important it is for the answer.
+Return ONLY a valid JSON object with the following structure:
+{
+  "points": [
+    {"description": "Key point", "score": 80}
+  ]
+}
+If the summaries contain nothing relevant, return {"points": []}.
+
+Community Summaries:
+%s
+User Question: %s`, sb.String(), req.Query)
+
+		response, err := s.llmClient.GenerateContent(ctx, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to map community summaries: %w", err)
+		}
+
+		var result struct {
+			Points []keyPoint `json:"points"`
+		}
+		if err := json.Unmarshal([]byte(cleanJSONResponse(response)), &result); err != nil {
+			fmt.Printf("Warning: failed to parse community key points: %v\n", err)
+			continue
+		}
+
+		contributed := false
+		for _, p := range result.Points {
+			if p.Score > 0 && p.Description != "" {
+				points = append(points, p)
+				contributed = true
+			}
+		}
+		if contributed {
+			for _, c := range batch {
+				resp.CommunityIDs = append(resp.CommunityIDs, c.ID)
+			}
+		}
+	}
+
+	if len(points) == 0 {
+		resp.Answer = "I don't know. The community summaries of this notebook contain nothing relevant to the question."
+		return resp, nil
+	}
+
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Score > points[j].Score })
+	if len(points) > maxReducePoints {
+		points = points[:maxReducePoints]
+	}
+
+	var sb strings.Builder
+	for _, p := range points {
+		fmt.Fprintf(&sb, "- (importance %d) %s\n", p.Score, p.Description)
+	}
+
+	prompt := fmt.Sprintf(`You are a helpful assistant for a Knowledge Graph application.
+Use the following Key Points, gathered from summaries of the whole document collection, to answer the User's Question.
+Combine them into a coherent answer, favoring the most important points.
+If the answer is not in the key points, say you don't know.
+
+Key Points:
+%s
+User Question: %s
+
+Answer:`, sb.String(), req.Query)
+
+	answer, err := s.llmClient.GenerateContent(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reduce key points: %w", err)
+	}
+	resp.Answer = answer
+	return resp, nil
 }
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/pkg/storage"
+)
+
+// orphanGracePeriod protects files of uploads whose document row is not written yet.
+const orphanGracePeriod = time.Hour
+
+var (
+	// uploadKeyPattern matches the keys storeFile writes uploads to, <hash[:2]>/<hash><ext>.
+	uploadKeyPattern = regexp.MustCompile(`^[0-9a-f]{2}/[0-9a-f]{64}[^/]*$`)
+	// legacyUploadPattern finds such a key at the end of a path inside the upload directory.
+	legacyUploadPattern = regexp.MustCompile(`(?:^|/)([0-9a-f]{2}/[0-9a-f]{64}[^/]*)$`)
+)
+
+// OrphanReport summarizes what RemoveOrphans found and removed.
+type OrphanReport struct {
+	Files     []string `json:"files"`
+	Documents []int64  `json:"documents"` // document IDs that only exist in the vector store
+}
+
+// CleanupService removes document data across Postgres, Qdrant and blob storage.
+type CleanupService interface {
+	// PurgeDocument permanently removes a document, its vectors and, if unshared, its file.
+	PurgeDocument(ctx context.Context, doc *entity.Document) error
+	// PurgeDeleted purges documents soft-deleted more than retention ago.
+	PurgeDeleted(ctx context.Context, retention time.Duration) (int, error)
+	// RemoveOrphans deletes stored files and vectors that no document references.
+	RemoveOrphans(ctx context.Context) (*OrphanReport, error)
+	// Run purges and removes orphans every interval until ctx is cancelled.
+	Run(ctx context.Context, interval, retention time.Duration)
+}
+
+type cleanupService struct {
+	docRepo    repository.DocumentRepository
+	graphRepo  repository.GraphRepository
+	vectorRepo repository.VectorRepository
+	blobStore  storage.BlobStore
+}
+
+// NewCleanupService creates a new CleanupService.
+func NewCleanupService(docRepo repository.DocumentRepository, graphRepo repository.GraphRepository, vectorRepo repository.VectorRepository, blobStore storage.BlobStore) CleanupService {
+	return &cleanupService{
+		docRepo:    docRepo,
+		graphRepo:  graphRepo,
+		vectorRepo: vectorRepo,
+		blobStore:  blobStore,
+	}
+}
+
+func (s *cleanupService) PurgeDocument(ctx context.Context, doc *entity.Document) error {
+	// Vectors first: if this fails the row survives and the purge is retried later
+	if s.vectorRepo != nil {
+		for _, collection := range []string{chunkCollection, nodeCollection} {
+			if err := s.vectorRepo.DeleteByDocumentID(ctx, collection, doc.ID); err != nil {
+				return fmt.Errorf("failed to delete vectors of document %d: %w", doc.ID, err)
+			}
+		}
+	}
+
+	// Postgres cascades the graph with the document row, but other graph backends do not
+	if err := s.graphRepo.DeleteByDocumentID(ctx, doc.ID); err != nil {
+		return fmt.Errorf("failed to delete graph of document %d: %w", doc.ID, err)
+	}
+
+	if err := s.docRepo.Delete(ctx, doc.ID); err != nil {
+		return err
+	}
+
+	// Files are content-addressed and may be shared by other documents
+	if doc.FilePath == "" {
+		return nil
+	}
+	refs, err := s.docRepo.CountByFilePath(ctx, doc.FilePath)
+	if err != nil {
+		return err
+	}
+	if refs == 0 {
+		if err := s.blobStore.Delete(ctx, doc.FilePath); err != nil {
+			// The orphan sweep picks this up later
+			log.Printf("Warning: failed to delete file %s: %v", doc.FilePath, err)
+		}
+	}
+	return nil
+}
+
+func (s *cleanupService) PurgeDeleted(ctx context.Context, retention time.Duration) (int, error) {
+	docs, err := s.docRepo.ListDeleted(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, doc := range docs {
+		if err := s.PurgeDocument(ctx, doc); err != nil {
+			log.Printf("Warning: failed to purge document %d: %v", doc.ID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (s *cleanupService) RemoveOrphans(ctx context.Context) (*OrphanReport, error) {
+	report := &OrphanReport{Files: []string{}, Documents: []int64{}}
+
+	// 1. Stored files without a document
+	paths, err := s.docRepo.ListFilePaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		referenced[fileKey(p)] = true
+	}
+
+	objects, err := s.blobStore.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	cutoff := time.Now().Add(-orphanGracePeriod)
+	for _, obj := range objects {
+		// Only uploads are swept; other files predate content-addressed storage or were not
+		// written by GraphWeaver
+		if !uploadKeyPattern.MatchString(obj.Key) {
+			continue
+		}
+		if referenced[obj.Key] || obj.LastModified.After(cutoff) {
+			continue
+		}
+		if err := s.blobStore.Delete(ctx, obj.Key); err != nil {
+			log.Printf("Warning: failed to delete orphaned file %s: %v", obj.Key, err)
+			continue
+		}
+		report.Files = append(report.Files, obj.Key)
+	}
+
+	// 2. Vectors of documents that no longer exist
+	if s.vectorRepo == nil {
+		return report, nil
+	}
+	docIDs, err := s.vectorDocumentIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.docRepo.ExistingIDs(ctx, docIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range docIDs {
+		if existing[id] {
+			continue
+		}
+		deleted := true
+		for _, collection := range []string{chunkCollection, nodeCollection} {
+			if err := s.vectorRepo.DeleteByDocumentID(ctx, collection, id); err != nil {
+				log.Printf("Warning: failed to delete orphaned %s vectors of document %d: %v", collection, id, err)
+				deleted = false
+			}
+		}
+		if deleted {
+			report.Documents = append(report.Documents, id)
+		}
+	}
+
+	return report, nil
+}
+
+// vectorDocumentIDs collects the distinct document IDs present in the chunk and node
+// collections.
+func (s *cleanupService) vectorDocumentIDs(ctx context.Context) ([]int64, error) {
+	seen := make(map[int64]bool)
+	ids := []int64{}
+
+	for _, collection := range []string{chunkCollection, nodeCollection} {
+		exists, err := s.vectorRepo.CollectionExists(ctx, collection)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+
+		offset := ""
+		for {
+			points, next, err := s.vectorRepo.Scroll(ctx, collection, offset, 256)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range points {
+				id, ok := payloadInt64(p.Payload["document_id"])
+				if ok && !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+			if next == "" {
+				break
+			}
+			offset = next
+		}
+	}
+	return ids, nil
+}
+
+func (s *cleanupService) Run(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.PurgeDeleted(ctx, retention)
+			if err != nil {
+				log.Printf("Cleanup: purge failed: %v", err)
+			} else if purged > 0 {
+				log.Printf("Cleanup: purged %d deleted documents", purged)
+			}
+
+			report, err := s.RemoveOrphans(ctx)
+			if err != nil {
+				log.Printf("Cleanup: orphan sweep failed: %v", err)
+			} else if len(report.Files) > 0 || len(report.Documents) > 0 {
+				log.Printf("Cleanup: removed %d orphaned files and vectors of %d missing documents", len(report.Files), len(report.Documents))
+			}
+		}
+	}
+}
+
+// fileKey returns the store key of a document file path. Rows written before blob storage
+// hold paths inside the upload directory, "<dir>/<timestamp>_<name>" or "<dir>/ab/<hash><ext>",
+// until migration 000017 rewrites them.
+func fileKey(p string) string {
+	if m := legacyUploadPattern.FindStringSubmatch(p); m != nil {
+		return m[1]
+	}
+	return path.Base(p)
+}
+
+// payloadInt64 converts a numeric payload value to int64.
+func payloadInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case float64:
+		return int64(n), true // JSON often parses numbers as floats
+	default:
+		return 0, false
+	}
+}
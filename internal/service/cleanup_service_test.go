@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/pkg/storage"
+)
+
+type filePathDocs struct {
+	repository.DocumentRepository
+	paths []string
+}
+
+func (r *filePathDocs) ListFilePaths(ctx context.Context) ([]string, error) {
+	return r.paths, nil
+}
+
+func (r *filePathDocs) ExistingIDs(ctx context.Context, ids []int64) (map[int64]bool, error) {
+	existing := make(map[int64]bool)
+	for _, id := range ids {
+		existing[id] = id == 1
+	}
+	return existing, nil
+}
+
+// memoryVectors keeps collections of points in memory, scrolling two points per page.
+type memoryVectors struct {
+	repository.VectorRepository
+	points map[string][]*entity.VectorPoint
+}
+
+func (v *memoryVectors) CollectionExists(ctx context.Context, name string) (bool, error) {
+	_, ok := v.points[name]
+	return ok, nil
+}
+
+func (v *memoryVectors) Scroll(ctx context.Context, collection string, offset string, limit int) ([]*entity.VectorPoint, string, error) {
+	start, _ := strconv.Atoi(offset)
+	points := v.points[collection]
+	end := min(start+2, len(points))
+	next := ""
+	if end < len(points) {
+		next = strconv.Itoa(end)
+	}
+	return points[start:end], next, nil
+}
+
+func (v *memoryVectors) CreateCollection(ctx context.Context, name string, vectorSize int) error {
+	if _, ok := v.points[name]; !ok {
+		v.points[name] = nil
+	}
+	return nil
+}
+
+func (v *memoryVectors) Upsert(ctx context.Context, collection string, points []*entity.VectorPoint) error {
+	for _, p := range points {
+		v.points[collection] = slices.DeleteFunc(v.points[collection], func(q *entity.VectorPoint) bool { return q.ID == p.ID })
+		v.points[collection] = append(v.points[collection], p)
+	}
+	return nil
+}
+
+func (v *memoryVectors) Delete(ctx context.Context, collection string, ids []string) error {
+	v.points[collection] = slices.DeleteFunc(v.points[collection], func(p *entity.VectorPoint) bool { return slices.Contains(ids, p.ID) })
+	return nil
+}
+
+func (v *memoryVectors) DeleteByDocumentID(ctx context.Context, collection string, docID int64) error {
+	v.points[collection] = slices.DeleteFunc(v.points[collection], func(p *entity.VectorPoint) bool {
+		id, _ := payloadInt64(p.Payload["document_id"])
+		return id == docID
+	})
+	return nil
+}
+
+// ids lists the point IDs of a collection.
+func (v *memoryVectors) ids(collection string) []string {
+	ids := []string{}
+	for _, p := range v.points[collection] {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func vectorPoint(id string, docID int64) *entity.VectorPoint {
+	return &entity.VectorPoint{ID: id, Payload: map[string]interface{}{"document_id": docID}}
+}
+
+func TestRemoveOrphansKeepsLegacyFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := storage.NewLocalStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashKey := "ab/ab" + strings.Repeat("0", 62) + ".pdf"
+	orphanKey := "cd/cd" + strings.Repeat("1", 62) + ".pdf"
+	keys := []string{
+		"1700000000_report.pdf", // uploaded before content hashing
+		hashKey,                 // uploaded before blob storage
+		orphanKey,
+		"1700000001_notes.txt", // unreferenced, but not an upload key
+	}
+	old := time.Now().Add(-2 * orphanGracePeriod)
+	for _, key := range keys {
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(filepath.Join(dir, filepath.FromSlash(key)), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	docs := &filePathDocs{paths: []string{"uploads/1700000000_report.pdf", "uploads/" + hashKey}}
+	svc := NewCleanupService(docs, nil, nil, store)
+	report, err := svc.RemoveOrphans(ctx)
+	if err != nil {
+		t.Fatalf("RemoveOrphans: %v", err)
+	}
+
+	if len(report.Files) != 1 || report.Files[0] != orphanKey {
+		t.Errorf("expected only %s removed, got %v", orphanKey, report.Files)
+	}
+	for _, key := range []string{keys[0], keys[1], keys[3]} {
+		if _, err := store.Stat(ctx, key); err != nil {
+			t.Errorf("expected %s to be kept: %v", key, err)
+		}
+	}
+}
+
+func TestRemoveOrphansCleansNodeVectors(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Document 1 exists; document 2 only left chunks and document 3 only left entities
+	vectors := &memoryVectors{points: map[string][]*entity.VectorPoint{
+		chunkCollection: {vectorPoint("c1", 1), vectorPoint("c2", 2), vectorPoint("c3", 2)},
+		nodeCollection:  {vectorPoint("n1", 1), vectorPoint("n2", 2), vectorPoint("n3", 3)},
+	}}
+
+	svc := NewCleanupService(&filePathDocs{}, nil, vectors, store)
+	report, err := svc.RemoveOrphans(ctx)
+	if err != nil {
+		t.Fatalf("RemoveOrphans: %v", err)
+	}
+
+	if !slices.Equal(report.Documents, []int64{2, 3}) {
+		t.Errorf("expected documents [2 3] removed, got %v", report.Documents)
+	}
+	if got := vectors.ids(chunkCollection); !slices.Equal(got, []string{"c1"}) {
+		t.Errorf("expected chunk vectors [c1] kept, got %v", got)
+	}
+	if got := vectors.ids(nodeCollection); !slices.Equal(got, []string{"n1"}) {
+		t.Errorf("expected entity vectors [n1] kept, got %v", got)
+	}
+}
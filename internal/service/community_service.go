@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/pkg/analytics"
+	"github.com/suyw-0123/graphweaver/pkg/llm"
+)
+
+const (
+	// maxCommunityLevels caps how many of the coarsest hierarchy levels get summaries.
+	maxCommunityLevels = 3
+	// minCommunitySize skips communities too small to be worth a summary.
+	minCommunitySize = 3
+	// Entities and relations included in a community summary prompt.
+	maxSummaryEntities  = 50
+	maxSummaryRelations = 100
+)
+
+var (
+	// ErrCommunityBuildRunning is returned when a notebook's communities are already being built.
+	ErrCommunityBuildRunning = errors.New("service: communities are already being built")
+	// ErrCommunityBuildNotFound is returned when a notebook's communities were never built.
+	ErrCommunityBuildNotFound = errors.New("service: communities have not been built")
+)
+
+// CommunityService builds and serves the community hierarchy of notebook graphs.
+type CommunityService interface {
+	BuildCommunities(ctx context.Context, notebookID int64) ([]*entity.Community, error)
+	// StartBuild builds the notebook's communities in the background; GetBuild reports how
+	// the latest build went.
+	StartBuild(ctx context.Context, notebookID int64) (*entity.CommunityBuild, error)
+	GetBuild(ctx context.Context, notebookID int64) (*entity.CommunityBuild, error)
+	ListCommunities(ctx context.Context, notebookID int64, level *int) ([]*entity.Community, error)
+}
+
+type communityService struct {
+	communityRepo repository.CommunityRepository
+	graphRepo     repository.GraphRepository
+	notebookRepo  repository.NotebookRepository
+	llmClient     llm.Client
+}
+
+// NewCommunityService creates a new CommunityService.
+func NewCommunityService(
+	communityRepo repository.CommunityRepository,
+	graphRepo repository.GraphRepository,
+	notebookRepo repository.NotebookRepository,
+	llmClient llm.Client,
+) CommunityService {
+	return &communityService{
+		communityRepo: communityRepo,
+		graphRepo:     graphRepo,
+		notebookRepo:  notebookRepo,
+		llmClient:     llmClient,
+	}
+}
+
+// BuildCommunities detects hierarchical communities in the notebook graph with Louvain,
+// summarizes each with the LLM and replaces the notebook's stored communities.
+func (s *communityService) BuildCommunities(ctx context.Context, notebookID int64) ([]*entity.Community, error) {
+	if err := s.checkBuildable(ctx, notebookID); err != nil {
+		return nil, err
+	}
+	return s.build(ctx, notebookID)
+}
+
+func (s *communityService) StartBuild(ctx context.Context, notebookID int64) (*entity.CommunityBuild, error) {
+	if err := s.checkBuildable(ctx, notebookID); err != nil {
+		return nil, err
+	}
+	build, err := s.communityRepo.StartBuild(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to start community build: %w", err)
+	}
+	if build == nil {
+		return nil, ErrCommunityBuildRunning
+	}
+	go s.buildAsync(notebookID)
+	return build, nil
+}
+
+func (s *communityService) GetBuild(ctx context.Context, notebookID int64) (*entity.CommunityBuild, error) {
+	if err := s.checkNotebook(ctx, notebookID); err != nil {
+		return nil, err
+	}
+	build, err := s.communityRepo.GetBuild(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get community build: %w", err)
+	}
+	if build == nil {
+		return nil, ErrCommunityBuildNotFound
+	}
+	return build, nil
+}
+
+// buildAsync builds the communities of a notebook and records the outcome of the build.
+func (s *communityService) buildAsync(notebookID int64) {
+	ctx := context.Background()
+
+	var errMsg *string
+	communities, err := s.build(ctx, notebookID)
+	if err != nil {
+		msg := err.Error()
+		errMsg = &msg
+	}
+	if err := s.communityRepo.FinishBuild(ctx, notebookID, len(communities), errMsg); err != nil {
+		fmt.Printf("Warning: failed to record community build of notebook %d: %v\n", notebookID, err)
+	}
+}
+
+func (s *communityService) checkNotebook(ctx context.Context, notebookID int64) error {
+	if _, err := s.notebookRepo.GetByID(ctx, notebookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotebookNotFound
+		}
+		return fmt.Errorf("service: failed to get notebook: %w", err)
+	}
+	return nil
+}
+
+func (s *communityService) checkBuildable(ctx context.Context, notebookID int64) error {
+	if err := s.checkNotebook(ctx, notebookID); err != nil {
+		return err
+	}
+	if s.llmClient == nil {
+		return fmt.Errorf("service: llm client not initialized")
+	}
+	return nil
+}
+
+// build detects and summarizes the communities of a notebook graph.
+func (s *communityService) build(ctx context.Context, notebookID int64) ([]*entity.Community, error) {
+	nodes, err := s.graphRepo.GetNodesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get nodes: %w", err)
+	}
+	edges, err := s.graphRepo.GetEdgesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get edges: %w", err)
+	}
+
+	nodeByID := make(map[int64]*entity.Node, len(nodes))
+	ids := make([]int64, len(nodes))
+	for i, n := range nodes {
+		nodeByID[n.ID] = n
+		ids[i] = n.ID
+	}
+	g := analytics.NewGraph(ids)
+	var internal []*entity.Edge
+	for _, e := range edges {
+		if nodeByID[e.SourceNodeID] != nil && nodeByID[e.TargetNodeID] != nil {
+			g.AddEdge(e.SourceNodeID, e.TargetNodeID)
+			internal = append(internal, e)
+		}
+	}
+	pageRank := analytics.PageRank(g, pageRankDamping, pageRankMaxIter, pageRankTolerance)
+
+	// Louvain returns the finest level first; communities are stored coarsest first
+	levels := analytics.Louvain(g)
+	for i, j := 0, len(levels)-1; i < j; i, j = i+1, j-1 {
+		levels[i], levels[j] = levels[j], levels[i]
+	}
+	if len(levels) > maxCommunityLevels {
+		levels = levels[:maxCommunityLevels]
+	}
+
+	var communities []*entity.Community
+	var parentOf map[int]int64 // community number in the previous level -> placeholder ID
+	for level, partition := range levels {
+		members := make(map[int][]int64)
+		for _, id := range ids {
+			members[partition[id]] = append(members[partition[id]], id)
+		}
+		numbers := make([]int, 0, len(members))
+		for number := range members {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+
+		placeholders := make(map[int]int64)
+		for _, number := range numbers {
+			nodeIDs := members[number]
+			if len(nodeIDs) < minCommunitySize {
+				continue
+			}
+
+			community := &entity.Community{
+				ID:      -int64(len(communities) + 1),
+				Level:   level,
+				NodeIDs: nodeIDs,
+			}
+			if level > 0 {
+				// Members of a community share their parent, so the first one decides
+				parentID, ok := parentOf[levels[level-1][nodeIDs[0]]]
+				if !ok {
+					continue
+				}
+				community.ParentID = &parentID
+			}
+			for _, id := range nodeIDs {
+				community.Rank += pageRank[id]
+			}
+
+			title, summary, err := s.summarize(ctx, nodeIDs, nodeByID, internal, pageRank)
+			if err != nil {
+				return nil, err
+			}
+			community.Title = title
+			community.Summary = summary
+
+			communities = append(communities, community)
+			placeholders[number] = community.ID
+		}
+		parentOf = placeholders
+	}
+
+	if err := s.communityRepo.ReplaceForNotebook(ctx, notebookID, communities); err != nil {
+		return nil, fmt.Errorf("service: failed to save communities: %w", err)
+	}
+	return communities, nil
+}
+
+// ListCommunities returns a notebook's communities, optionally restricted to one level.
+func (s *communityService) ListCommunities(ctx context.Context, notebookID int64, level *int) ([]*entity.Community, error) {
+	communities, err := s.communityRepo.ListByNotebook(ctx, notebookID, level)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list communities: %w", err)
+	}
+	return communities, nil
+}
+
+// summarize asks the LLM for a title and summary of a community from its most central
+// entities and the relations between them.
+func (s *communityService) summarize(ctx context.Context, nodeIDs []int64, nodeByID map[int64]*entity.Node, edges []*entity.Edge, pageRank map[int64]float64) (string, string, error) {
+	ranked := make([]int64, len(nodeIDs))
+	copy(ranked, nodeIDs)
+	sort.SliceStable(ranked, func(i, j int) bool { return pageRank[ranked[i]] > pageRank[ranked[j]] })
+	if len(ranked) > maxSummaryEntities {
+		ranked = ranked[:maxSummaryEntities]
+	}
+	included := make(map[int64]bool, len(ranked))
+	for _, id := range ranked {
+		included[id] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Entities:\n")
+	for _, id := range ranked {
+		n := nodeByID[id]
+		if desc := nodeDescription(n); desc != "" {
+			fmt.Fprintf(&sb, "- %s (%s): %s\n", n.Name, n.Label, desc)
+		} else {
+			fmt.Fprintf(&sb, "- %s (%s)\n", n.Name, n.Label)
+		}
+	}
+	sb.WriteString("Relationships:\n")
+	relations := 0
+	for _, e := range edges {
+		if relations >= maxSummaryRelations {
+			break
+		}
+		if included[e.SourceNodeID] && included[e.TargetNodeID] {
+			fmt.Fprintf(&sb, "- %s --[%s]--> %s\n", nodeByID[e.SourceNodeID].Name, e.RelationType, nodeByID[e.TargetNodeID].Name)
+			relations++
+		}
+	}
+
+	prompt := fmt.Sprintf(`
+You are analyzing a community of closely related entities from a knowledge graph.
+Write a short title and a summary describing what this community is about: its key entities,
+how they relate and the main themes they represent.
+Return ONLY a valid JSON object with the following structure:
+{
+  "title": "Short descriptive title",
+  "summary": "Summary of the community (max 150 words)"
+}
+
+%s`, sb.String())
+
+	response, err := s.llmClient.GenerateContent(ctx, prompt)
+	if err != nil {
+		return "", "", fmt.Errorf("service: failed to summarize community: %w", err)
+	}
+
+	var result struct {
+		Title   string `json:"title"`
+		Summary string `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(cleanJSONResponse(response)), &result); err != nil {
+		return "", "", fmt.Errorf("service: failed to parse community summary: %w", err)
+	}
+	return result.Title, result.Summary, nil
+}
+
+// nodeDescription returns the description stored in a node's properties, if any.
+func nodeDescription(n *entity.Node) string {
+	description, _ := n.Properties["description"].(string)
+	return description
+}
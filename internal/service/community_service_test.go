@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+)
+
+type buildNotebooks struct {
+	repository.NotebookRepository
+}
+
+func (buildNotebooks) GetByID(ctx context.Context, id int64) (*entity.Notebook, error) {
+	return &entity.Notebook{ID: id}, nil
+}
+
+// trackedBuilds records one build at a time.
+type trackedBuilds struct {
+	repository.CommunityRepository
+	mu       sync.Mutex
+	running  bool
+	finished chan *string
+}
+
+func (r *trackedBuilds) StartBuild(ctx context.Context, notebookID int64) (*entity.CommunityBuild, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return nil, nil
+	}
+	r.running = true
+	return &entity.CommunityBuild{NotebookID: notebookID, Status: entity.CommunityBuildRunning}, nil
+}
+
+func (r *trackedBuilds) FinishBuild(ctx context.Context, notebookID int64, communities int, errorMessage *string) error {
+	r.mu.Lock()
+	r.running = false
+	r.mu.Unlock()
+	r.finished <- errorMessage
+	return nil
+}
+
+// triangleGraph is a graph of three related nodes, returned once release is closed.
+type triangleGraph struct {
+	repository.GraphRepository
+	release chan struct{}
+}
+
+func (g *triangleGraph) GetNodesByNotebookID(ctx context.Context, notebookID int64) ([]*entity.Node, error) {
+	<-g.release
+	return []*entity.Node{{ID: 1, Name: "A"}, {ID: 2, Name: "B"}, {ID: 3, Name: "C"}}, nil
+}
+
+func (g *triangleGraph) GetEdgesByNotebookID(ctx context.Context, notebookID int64) ([]*entity.Edge, error) {
+	return []*entity.Edge{
+		{SourceNodeID: 1, TargetNodeID: 2, RelationType: "KNOWS"},
+		{SourceNodeID: 2, TargetNodeID: 3, RelationType: "KNOWS"},
+		{SourceNodeID: 3, TargetNodeID: 1, RelationType: "KNOWS"},
+	}, nil
+}
+
+func TestStartBuildRunsInBackground(t *testing.T) {
+	ctx := context.Background()
+	builds := &trackedBuilds{finished: make(chan *string, 1)}
+	graph := &triangleGraph{release: make(chan struct{})}
+	// The LLM has no summary to give, so the build fails
+	svc := NewCommunityService(builds, graph, buildNotebooks{}, &queuedLLM{})
+
+	build, err := svc.StartBuild(ctx, 1)
+	if err != nil {
+		t.Fatalf("StartBuild: %v", err)
+	}
+	if build.Status != entity.CommunityBuildRunning {
+		t.Errorf("build status = %q, want running", build.Status)
+	}
+	if _, err := svc.StartBuild(ctx, 1); !errors.Is(err, ErrCommunityBuildRunning) {
+		t.Errorf("second StartBuild: got %v, want ErrCommunityBuildRunning", err)
+	}
+
+	close(graph.release)
+	if errMsg := <-builds.finished; errMsg == nil {
+		t.Error("failed build was recorded as completed")
+	}
+}
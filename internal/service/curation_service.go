@@ -0,0 +1,703 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/pkg/embedding"
+)
+
+// Audited entity types and edit actions.
+const (
+	EditEntityNode = "node"
+	EditEntityEdge = "edge"
+
+	EditCreate = "create"
+	EditUpdate = "update"
+	EditDelete = "delete"
+	EditMerge  = "merge"
+	EditSplit  = "split"
+
+	defaultEditLimit = 100
+	maxEditLimit     = 1000
+)
+
+var (
+	// ErrEdgeNotFound is returned when a graph edge does not exist.
+	ErrEdgeNotFound = errors.New("service: edge not found")
+	// ErrInvalidGraphEdit is returned for a malformed node or edge edit.
+	ErrInvalidGraphEdit = errors.New("service: invalid graph edit")
+)
+
+// NodeInput holds the fields of a node to create or update. On update, nil fields are kept.
+type NodeInput struct {
+	DocumentID *int64             `json:"document_id,omitempty"` // create only
+	Label      *string            `json:"label,omitempty"`
+	Name       *string            `json:"name,omitempty"`
+	Properties *entity.Properties `json:"properties,omitempty"` // replaces all properties
+}
+
+// EdgeInput holds the fields of an edge to create or update. On update, only the relation
+// type, properties and validity dates can change; nil fields are kept, and an empty string
+// clears a validity date.
+type EdgeInput struct {
+	DocumentID   *int64             `json:"document_id,omitempty"` // create only, defaults to the source node's document
+	SourceNodeID int64              `json:"source_node_id,omitempty"`
+	TargetNodeID int64              `json:"target_node_id,omitempty"`
+	RelationType *string            `json:"relation_type,omitempty"`
+	Properties   *entity.Properties `json:"properties,omitempty"`
+	ValidFrom    *DateEdit          `json:"valid_from,omitempty"`
+	ValidTo      *DateEdit          `json:"valid_to,omitempty"`
+}
+
+// DateEdit is a date set by an edit, encoded in JSON as YYYY-MM-DD, or "" to clear it.
+type DateEdit struct {
+	Date *entity.Date
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *DateEdit) UnmarshalJSON(data []byte) error {
+	if string(data) == `""` {
+		d.Date = nil
+		return nil
+	}
+	var date entity.Date
+	if err := json.Unmarshal(data, &date); err != nil {
+		return err
+	}
+	d.Date = &date
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d DateEdit) MarshalJSON() ([]byte, error) {
+	if d.Date == nil {
+		return []byte(`""`), nil
+	}
+	return json.Marshal(d.Date)
+}
+
+// SplitPart describes a node split off another one and the edges it takes over.
+type SplitPart struct {
+	Label      string            `json:"label"`
+	Name       string            `json:"name"`
+	Properties entity.Properties `json:"properties,omitempty"`
+	EdgeIDs    []int64           `json:"edge_ids"`
+}
+
+// CurationService applies manual corrections to the knowledge graph. Every change marks the
+// affected entities as human-sourced and is recorded in the edit audit trail, so that
+// re-extracting a document keeps the corrections.
+type CurationService interface {
+	GetNode(ctx context.Context, id int64) (*entity.Node, error)
+	CreateNode(ctx context.Context, in NodeInput) (*entity.Node, error)
+	UpdateNode(ctx context.Context, id int64, in NodeInput) (*entity.Node, error)
+	DeleteNode(ctx context.Context, id int64) error
+	GetEdge(ctx context.Context, id int64) (*entity.Edge, error)
+	CreateEdge(ctx context.Context, in EdgeInput) (*entity.Edge, error)
+	UpdateEdge(ctx context.Context, id int64, in EdgeInput) (*entity.Edge, error)
+	DeleteEdge(ctx context.Context, id int64) error
+	// MergeNodes folds the source nodes into the target: their edges move to the target, their
+	// properties fill in missing ones and their names are kept as aliases.
+	MergeNodes(ctx context.Context, targetID int64, sourceIDs []int64) (*entity.Node, error)
+	// SplitNode creates new nodes from a node, each taking over some of its edges.
+	SplitNode(ctx context.Context, id int64, parts []SplitPart) ([]*entity.Node, error)
+	ListEdits(ctx context.Context, entityType string, id int64) ([]*entity.GraphEdit, error)
+	ListNotebookEdits(ctx context.Context, notebookID int64, limit int) ([]*entity.GraphEdit, error)
+}
+
+type curationService struct {
+	graphRepo       repository.GraphRepository
+	editRepo        repository.GraphEditRepository
+	mentionRepo     repository.MentionRepository
+	docRepo         repository.DocumentRepository
+	vectorRepo      repository.VectorRepository
+	embeddingClient embedding.Client
+}
+
+// NewCurationService creates a new CurationService.
+func NewCurationService(
+	graphRepo repository.GraphRepository,
+	editRepo repository.GraphEditRepository,
+	mentionRepo repository.MentionRepository,
+	docRepo repository.DocumentRepository,
+	vectorRepo repository.VectorRepository,
+	embeddingClient embedding.Client,
+) CurationService {
+	return &curationService{
+		graphRepo:       graphRepo,
+		editRepo:        editRepo,
+		mentionRepo:     mentionRepo,
+		docRepo:         docRepo,
+		vectorRepo:      vectorRepo,
+		embeddingClient: embeddingClient,
+	}
+}
+
+func (s *curationService) GetNode(ctx context.Context, id int64) (*entity.Node, error) {
+	node, err := s.graphRepo.GetNode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get node: %w", err)
+	}
+	if node == nil {
+		return nil, ErrNodeNotFound
+	}
+	return node, nil
+}
+
+func (s *curationService) CreateNode(ctx context.Context, in NodeInput) (*entity.Node, error) {
+	if in.DocumentID == nil {
+		return nil, fmt.Errorf("%w: document_id is required", ErrInvalidGraphEdit)
+	}
+	if in.Name == nil || strings.TrimSpace(*in.Name) == "" || in.Label == nil || strings.TrimSpace(*in.Label) == "" {
+		return nil, fmt.Errorf("%w: name and label are required", ErrInvalidGraphEdit)
+	}
+	doc, err := s.docRepo.GetByID(ctx, *in.DocumentID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get document: %w", err)
+	}
+	if doc == nil || doc.IsDeleted {
+		return nil, ErrDocumentNotFound
+	}
+
+	node := &entity.Node{
+		DocumentID: doc.ID,
+		Label:      strings.TrimSpace(*in.Label),
+		Name:       strings.TrimSpace(*in.Name),
+		Properties: entity.Properties{},
+		Source:     entity.SourceHuman,
+	}
+	if in.Properties != nil {
+		node.Properties = *in.Properties
+	}
+	if err := s.graphRepo.CreateNode(ctx, node); err != nil {
+		return nil, fmt.Errorf("service: failed to create node: %w", err)
+	}
+	s.syncNodeVectors(ctx, []*entity.Node{node}, nil)
+	if err := s.record(ctx, node.DocumentID, EditEntityNode, node.ID, EditCreate, nil, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (s *curationService) UpdateNode(ctx context.Context, id int64, in NodeInput) (*entity.Node, error) {
+	node, err := s.GetNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *node
+
+	if in.Label != nil {
+		if node.Label = strings.TrimSpace(*in.Label); node.Label == "" {
+			return nil, fmt.Errorf("%w: label cannot be empty", ErrInvalidGraphEdit)
+		}
+	}
+	if in.Name != nil {
+		if node.Name = strings.TrimSpace(*in.Name); node.Name == "" {
+			return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidGraphEdit)
+		}
+	}
+	if in.Properties != nil {
+		node.Properties = *in.Properties
+	}
+	node.Source = entity.SourceHuman
+
+	if err := s.graphRepo.UpdateNode(ctx, node); err != nil {
+		return nil, fmt.Errorf("service: failed to update node: %w", err)
+	}
+	s.syncNodeVectors(ctx, []*entity.Node{node}, nil)
+	if err := s.record(ctx, node.DocumentID, EditEntityNode, node.ID, EditUpdate, &before, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (s *curationService) DeleteNode(ctx context.Context, id int64) error {
+	node, err := s.GetNode(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.graphRepo.DeleteNode(ctx, id); err != nil {
+		return fmt.Errorf("service: failed to delete node: %w", err)
+	}
+	s.syncNodeVectors(ctx, nil, []int64{id})
+	return s.record(ctx, node.DocumentID, EditEntityNode, node.ID, EditDelete, node, nil)
+}
+
+func (s *curationService) GetEdge(ctx context.Context, id int64) (*entity.Edge, error) {
+	edge, err := s.graphRepo.GetEdge(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get edge: %w", err)
+	}
+	if edge == nil {
+		return nil, ErrEdgeNotFound
+	}
+	return edge, nil
+}
+
+func (s *curationService) CreateEdge(ctx context.Context, in EdgeInput) (*entity.Edge, error) {
+	if in.RelationType == nil || strings.TrimSpace(*in.RelationType) == "" {
+		return nil, fmt.Errorf("%w: relation_type is required", ErrInvalidGraphEdit)
+	}
+	source, err := s.GetNode(ctx, in.SourceNodeID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.GetNode(ctx, in.TargetNodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	edge := &entity.Edge{
+		DocumentID:   source.DocumentID,
+		SourceNodeID: source.ID,
+		TargetNodeID: target.ID,
+		RelationType: strings.TrimSpace(*in.RelationType),
+		Properties:   entity.Properties{},
+		Source:       entity.SourceHuman,
+	}
+	if in.DocumentID != nil {
+		doc, err := s.docRepo.GetByID(ctx, *in.DocumentID)
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to get document: %w", err)
+		}
+		if doc == nil || doc.IsDeleted {
+			return nil, ErrDocumentNotFound
+		}
+		edge.DocumentID = doc.ID
+	}
+	if in.Properties != nil {
+		edge.Properties = *in.Properties
+	}
+	if err := setValidity(edge, in); err != nil {
+		return nil, err
+	}
+	if err := s.graphRepo.CreateEdge(ctx, edge); err != nil {
+		return nil, fmt.Errorf("service: failed to create edge: %w", err)
+	}
+	if err := s.record(ctx, edge.DocumentID, EditEntityEdge, edge.ID, EditCreate, nil, s.edgeSnapshot(ctx, edge)); err != nil {
+		return nil, err
+	}
+	return edge, nil
+}
+
+func (s *curationService) UpdateEdge(ctx context.Context, id int64, in EdgeInput) (*entity.Edge, error) {
+	edge, err := s.GetEdge(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if (in.SourceNodeID != 0 && in.SourceNodeID != edge.SourceNodeID) || (in.TargetNodeID != 0 && in.TargetNodeID != edge.TargetNodeID) {
+		return nil, fmt.Errorf("%w: edge endpoints cannot change; delete the edge and create a new one", ErrInvalidGraphEdit)
+	}
+	before := s.edgeSnapshot(ctx, edge)
+
+	if in.RelationType != nil {
+		if edge.RelationType = strings.TrimSpace(*in.RelationType); edge.RelationType == "" {
+			return nil, fmt.Errorf("%w: relation_type cannot be empty", ErrInvalidGraphEdit)
+		}
+	}
+	if in.Properties != nil {
+		edge.Properties = *in.Properties
+	}
+	if err := setValidity(edge, in); err != nil {
+		return nil, err
+	}
+	edge.Source = entity.SourceHuman
+
+	if err := s.graphRepo.UpdateEdge(ctx, edge); err != nil {
+		return nil, fmt.Errorf("service: failed to update edge: %w", err)
+	}
+	if err := s.record(ctx, edge.DocumentID, EditEntityEdge, edge.ID, EditUpdate, before, s.edgeSnapshot(ctx, edge)); err != nil {
+		return nil, err
+	}
+	return edge, nil
+}
+
+// setValidity applies the validity dates given in an edit. Dates that are not given are kept.
+func setValidity(edge *entity.Edge, in EdgeInput) error {
+	if in.ValidFrom != nil {
+		edge.ValidFrom = in.ValidFrom.Date
+	}
+	if in.ValidTo != nil {
+		edge.ValidTo = in.ValidTo.Date
+	}
+	if edge.ValidFrom != nil && edge.ValidTo != nil && edge.ValidTo.Before(edge.ValidFrom.Time) {
+		return fmt.Errorf("%w: valid_to is before valid_from", ErrInvalidGraphEdit)
+	}
+	return nil
+}
+
+func (s *curationService) DeleteEdge(ctx context.Context, id int64) error {
+	edge, err := s.GetEdge(ctx, id)
+	if err != nil {
+		return err
+	}
+	before := s.edgeSnapshot(ctx, edge)
+	if err := s.graphRepo.DeleteEdge(ctx, id); err != nil {
+		return fmt.Errorf("service: failed to delete edge: %w", err)
+	}
+	return s.record(ctx, edge.DocumentID, EditEntityEdge, edge.ID, EditDelete, before, nil)
+}
+
+func (s *curationService) MergeNodes(ctx context.Context, targetID int64, sourceIDs []int64) (*entity.Node, error) {
+	target, err := s.GetNode(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	var sources []*entity.Node
+	seen := map[int64]bool{targetID: true}
+	for _, id := range sourceIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		node, err := s.GetNode(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, node)
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("%w: at least one other node is required", ErrInvalidGraphEdit)
+	}
+	before := *target
+
+	// The target's properties win; merged names are kept as aliases
+	props := entity.Properties{}
+	maps.Copy(props, target.Properties)
+	aliases := stringList(props["aliases"])
+	ids := make([]int64, len(sources))
+	for i, src := range sources {
+		ids[i] = src.ID
+		for k, v := range src.Properties {
+			if k == "aliases" {
+				aliases = append(aliases, stringList(v)...)
+			} else if _, ok := props[k]; !ok {
+				props[k] = v
+			}
+		}
+		aliases = append(aliases, src.Name)
+	}
+	if aliases = uniqueAliases(aliases, target.Name); len(aliases) > 0 {
+		props["aliases"] = aliases
+	}
+	target.Properties = props
+	target.Source = entity.SourceHuman
+
+	dropped, err := s.graphRepo.MergeNodes(ctx, targetID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to merge nodes: %w", err)
+	}
+	// The merged nodes' evidence now supports the target
+	if err := s.mentionRepo.ReassignNodes(ctx, ids, targetID); err != nil {
+		fmt.Printf("Warning: failed to move evidence of merged nodes: %v\n", err)
+	}
+	if err := s.graphRepo.UpdateNode(ctx, target); err != nil {
+		return nil, fmt.Errorf("service: failed to update merged node: %w", err)
+	}
+	s.syncNodeVectors(ctx, []*entity.Node{target}, ids)
+
+	for _, src := range sources {
+		if err := s.record(ctx, src.DocumentID, EditEntityNode, src.ID, EditMerge, src, target); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.record(ctx, target.DocumentID, EditEntityNode, target.ID, EditUpdate, &before, target); err != nil {
+		return nil, err
+	}
+	// Edges that became loops or duplicates are gone with the merge
+	for _, e := range dropped {
+		if err := s.record(ctx, e.DocumentID, EditEntityEdge, e.ID, EditDelete, s.edgeSnapshot(ctx, e), nil); err != nil {
+			return nil, err
+		}
+	}
+	return target, nil
+}
+
+func (s *curationService) SplitNode(ctx context.Context, id int64, parts []SplitPart) ([]*entity.Node, error) {
+	original, err := s.GetNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("%w: at least one part is required", ErrInvalidGraphEdit)
+	}
+
+	claimed := make(map[int64]bool)
+	splits := make([]repository.NodeSplit, len(parts))
+	for i, p := range parts {
+		name, label := strings.TrimSpace(p.Name), strings.TrimSpace(p.Label)
+		if name == "" {
+			return nil, fmt.Errorf("%w: part %d has no name", ErrInvalidGraphEdit, i)
+		}
+		if label == "" {
+			label = original.Label
+		}
+		for _, edgeID := range p.EdgeIDs {
+			if claimed[edgeID] {
+				return nil, fmt.Errorf("%w: edge %d is assigned to more than one part", ErrInvalidGraphEdit, edgeID)
+			}
+			claimed[edgeID] = true
+			edge, err := s.GetEdge(ctx, edgeID)
+			if err != nil {
+				return nil, err
+			}
+			if edge.SourceNodeID != id && edge.TargetNodeID != id {
+				return nil, fmt.Errorf("%w: edge %d is not attached to node %d", ErrInvalidGraphEdit, edgeID, id)
+			}
+		}
+		props := p.Properties
+		if props == nil {
+			props = entity.Properties{}
+		}
+		splits[i] = repository.NodeSplit{
+			Node:    &entity.Node{Label: label, Name: name, Properties: props, Source: entity.SourceHuman},
+			EdgeIDs: p.EdgeIDs,
+		}
+	}
+
+	if err := s.graphRepo.SplitNode(ctx, id, splits); err != nil {
+		return nil, fmt.Errorf("service: failed to split node: %w", err)
+	}
+
+	// The remaining node is now curated as well
+	before := *original
+	original.Source = entity.SourceHuman
+	if err := s.graphRepo.UpdateNode(ctx, original); err != nil {
+		return nil, fmt.Errorf("service: failed to update split node: %w", err)
+	}
+
+	nodes := make([]*entity.Node, len(splits))
+	for i, sp := range splits {
+		nodes[i] = sp.Node
+	}
+	s.syncNodeVectors(ctx, nodes, nil)
+	for _, sp := range splits {
+		if err := s.record(ctx, sp.Node.DocumentID, EditEntityNode, sp.Node.ID, EditCreate, nil, sp.Node); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.record(ctx, original.DocumentID, EditEntityNode, original.ID, EditSplit, &before, original); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func (s *curationService) ListEdits(ctx context.Context, entityType string, id int64) ([]*entity.GraphEdit, error) {
+	edits, err := s.editRepo.ListByEntity(ctx, entityType, id)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list edits: %w", err)
+	}
+	return edits, nil
+}
+
+func (s *curationService) ListNotebookEdits(ctx context.Context, notebookID int64, limit int) ([]*entity.GraphEdit, error) {
+	if limit <= 0 {
+		limit = defaultEditLimit
+	}
+	edits, err := s.editRepo.ListByNotebook(ctx, notebookID, min(limit, maxEditLimit))
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list edits: %w", err)
+	}
+	return edits, nil
+}
+
+// syncNodeVectors re-embeds changed nodes and removes the vectors of deleted ones, so entity
+// search finds nodes under their current names. Entity search is auxiliary, so failures are
+// logged and ignored.
+func (s *curationService) syncNodeVectors(ctx context.Context, changed []*entity.Node, deleted []int64) {
+	if s.vectorRepo == nil {
+		return
+	}
+	if len(deleted) > 0 {
+		ids := make([]string, len(deleted))
+		for i, id := range deleted {
+			ids[i] = nodePointID(id)
+		}
+		if err := s.vectorRepo.Delete(ctx, nodeCollection, ids); err != nil {
+			fmt.Printf("Warning: failed to delete node vectors: %v\n", err)
+		}
+	}
+	if len(changed) > 0 && s.embeddingClient != nil {
+		if err := upsertNodeVectors(ctx, s.vectorRepo, s.embeddingClient, changed); err != nil {
+			fmt.Printf("Warning: failed to embed curated nodes: %v\n", err)
+		}
+	}
+}
+
+// record appends an edit to the audit trail. The change itself has already been applied, so
+// a failure is reported as such: without the record, re-extraction would not keep the change.
+func (s *curationService) record(ctx context.Context, docID int64, entityType string, entityID int64, action string, before, after any) error {
+	edit := &entity.GraphEdit{
+		DocumentID: docID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Before:     snapshot(before),
+		After:      snapshot(after),
+	}
+	if err := s.editRepo.Create(ctx, edit); err != nil {
+		return fmt.Errorf("service: %s of %s %d was applied but could not be recorded: %w", action, entityType, entityID, err)
+	}
+	return nil
+}
+
+// edgeSnapshotData is the audited form of an edge. Endpoint names let later extractions
+// recognize the relation, as node IDs change when a document is processed again.
+type edgeSnapshotData struct {
+	*entity.Edge
+	SourceName string `json:"source_name"`
+	TargetName string `json:"target_name"`
+}
+
+func (s *curationService) edgeSnapshot(ctx context.Context, e *entity.Edge) *edgeSnapshotData {
+	copied := *e
+	data := &edgeSnapshotData{Edge: &copied}
+	if n, err := s.graphRepo.GetNode(ctx, e.SourceNodeID); err == nil && n != nil {
+		data.SourceName = n.Name
+	}
+	if n, err := s.graphRepo.GetNode(ctx, e.TargetNodeID); err == nil && n != nil {
+		data.TargetName = n.Name
+	}
+	return data
+}
+
+func snapshot(v any) *string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case *entity.Node:
+		if v == nil {
+			return nil
+		}
+	case *edgeSnapshotData:
+		if v == nil {
+			return nil
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	s := string(b)
+	return &s
+}
+
+func stringList(v any) []string {
+	items, _ := v.([]any)
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// uniqueAliases removes duplicates and the node's own name, ignoring case.
+func uniqueAliases(aliases []string, name string) []string {
+	seen := map[string]bool{strings.ToLower(name): true}
+	var out []string
+	for _, a := range aliases {
+		if key := strings.ToLower(a); !seen[key] {
+			seen[key] = true
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// correctExtraction applies the recorded edits of a document to a freshly extracted graph, so
+// processing a document again does not undo human corrections. Extracted entities that were
+// renamed, merged or match a curated node are replaced by that node, deleted entities are
+// dropped together with their relations, and deleted or retyped relations are dropped.
+// exists reports whether a node still exists; edges of the result may reference such nodes.
+func correctExtraction(edits []*entity.GraphEdit, curated []*entity.Node, nodes []*entity.Node, edges []*entity.Edge, exists func(int64) bool) ([]*entity.Node, []*entity.Edge) {
+	type nodeKey [2]string // name, label
+
+	redirects := make(map[nodeKey]int64) // 0 drops the entity
+	suppressed := make(map[[3]string]bool)
+
+	for _, e := range edits {
+		switch e.EntityType {
+		case EditEntityNode:
+			var before, after entity.Node
+			if e.Before == nil || json.Unmarshal([]byte(*e.Before), &before) != nil {
+				continue
+			}
+			key := nodeKey{before.Name, before.Label}
+			switch e.Action {
+			case EditDelete:
+				redirects[key] = 0
+			case EditUpdate, EditMerge:
+				if e.After == nil || json.Unmarshal([]byte(*e.After), &after) != nil {
+					continue
+				}
+				if e.Action == EditMerge || after.Name != before.Name || after.Label != before.Label {
+					redirects[key] = after.ID
+				}
+			}
+		case EditEntityEdge:
+			var before, after edgeSnapshotData
+			if e.Before == nil || json.Unmarshal([]byte(*e.Before), &before) != nil || before.Edge == nil {
+				continue
+			}
+			key := [3]string{before.SourceName, before.RelationType, before.TargetName}
+			switch e.Action {
+			case EditDelete:
+				suppressed[key] = true
+			case EditUpdate:
+				if e.After != nil && json.Unmarshal([]byte(*e.After), &after) == nil && after.Edge != nil &&
+					after.RelationType != before.RelationType {
+					suppressed[key] = true
+				}
+			}
+		}
+	}
+	// Curated nodes in their current state take precedence
+	for _, n := range curated {
+		if n.Source == entity.SourceHuman {
+			redirects[nodeKey{n.Name, n.Label}] = n.ID
+		}
+	}
+
+	resolved := make(map[int64]int64, len(nodes)) // placeholder -> placeholder, existing ID or 0
+	names := make(map[int64]string, len(nodes))
+	var kept []*entity.Node
+	for _, n := range nodes {
+		names[n.ID] = n.Name
+		target, ok := redirects[nodeKey{n.Name, n.Label}]
+		switch {
+		case !ok:
+			resolved[n.ID] = n.ID
+			kept = append(kept, n)
+		case target != 0 && exists(target):
+			resolved[n.ID] = target
+		default:
+			resolved[n.ID] = 0
+		}
+	}
+
+	var keptEdges []*entity.Edge
+	for _, e := range edges {
+		source, target := e.SourceNodeID, e.TargetNodeID
+		if id, ok := resolved[source]; ok {
+			source = id
+		}
+		if id, ok := resolved[target]; ok {
+			target = id
+		}
+		if source == 0 || target == 0 || suppressed[[3]string{names[e.SourceNodeID], e.RelationType, names[e.TargetNodeID]}] {
+			continue
+		}
+		e.SourceNodeID, e.TargetNodeID = source, target
+		keptEdges = append(keptEdges, e)
+	}
+	return kept, keptEdges
+}
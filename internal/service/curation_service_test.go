@@ -0,0 +1,35 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+func TestSetValidity(t *testing.T) {
+	from, _ := entity.ParseDate("2019-03-01")
+	to, _ := entity.ParseDate("2020-01-31")
+	edge := &entity.Edge{ValidFrom: &from, ValidTo: &to}
+
+	var in EdgeInput
+	if err := json.Unmarshal([]byte(`{"valid_from": "", "valid_to": null}`), &in); err != nil {
+		t.Fatal(err)
+	}
+	if err := setValidity(edge, in); err != nil {
+		t.Fatal(err)
+	}
+	if edge.ValidFrom != nil {
+		t.Errorf("expected an empty valid_from to clear the date, got %s", edge.ValidFrom)
+	}
+	if edge.ValidTo == nil || !edge.ValidTo.Equal(to.Time) {
+		t.Errorf("expected a null valid_to to keep %s, got %v", to, edge.ValidTo)
+	}
+
+	if err := json.Unmarshal([]byte(`{"valid_from": "2021-01-01"}`), &in); err != nil {
+		t.Fatal(err)
+	}
+	if err := setValidity(edge, in); err == nil {
+		t.Error("expected an error for valid_from after valid_to")
+	}
+}
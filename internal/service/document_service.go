@@ -2,12 +2,26 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/suyw-0123/graphweaver/internal/entity"
 	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/pkg/storage"
 )
 
+// ErrDocumentNotFound is returned when a document does not exist or was deleted.
+var ErrDocumentNotFound = errors.New("service: document not found")
+
+// ErrFileNotFound is returned when a document has no stored file, or the file is missing.
+var ErrFileNotFound = errors.New("service: document file not found")
+
+// presignExpiry is how long presigned download URLs stay valid.
+const presignExpiry = 15 * time.Minute
+
 type GraphData struct {
 	Nodes []*entity.Node `json:"nodes"`
 	Edges []*entity.Edge `json:"edges"`
@@ -18,18 +32,30 @@ type DocumentService interface {
 	UploadDocument(ctx context.Context, filename, filePath, mimeType string, fileSize int64) (*entity.Document, error)
 	GetDocument(ctx context.Context, id int64) (*entity.Document, error)
 	ListDocuments(ctx context.Context, page, pageSize int, notebookID *int64) ([]*entity.Document, error)
-	GetGraph(ctx context.Context, docID int64) (*GraphData, error)
+	GetGraph(ctx context.Context, docID int64, asOf *entity.Date) (*GraphData, error)
+	GetFile(ctx context.Context, docID int64) (*DocumentFile, error)
+	DeleteDocument(ctx context.Context, id int64) error
+}
+
+// DocumentFile is the stored file of a document. Exactly one of URL or Content is set:
+// URL when the storage backend can issue presigned URLs, Content otherwise.
+type DocumentFile struct {
+	Document *entity.Document
+	URL      string
+	Content  io.ReadCloser // must be closed by the caller
+	Size     int64
 }
 
 // documentService implements DocumentService.
 type documentService struct {
 	repo      repository.DocumentRepository
 	graphRepo repository.GraphRepository
+	blobStore storage.BlobStore
 }
 
 // NewDocumentService creates a new DocumentService.
-func NewDocumentService(repo repository.DocumentRepository, graphRepo repository.GraphRepository) DocumentService {
-	return &documentService{repo: repo, graphRepo: graphRepo}
+func NewDocumentService(repo repository.DocumentRepository, graphRepo repository.GraphRepository, blobStore storage.BlobStore) DocumentService {
+	return &documentService{repo: repo, graphRepo: graphRepo, blobStore: blobStore}
 }
 
 // UploadDocument handles the metadata creation for a new document.
@@ -57,13 +83,14 @@ func (s *documentService) GetDocument(ctx context.Context, id int64) (*entity.Do
 		return nil, fmt.Errorf("service: failed to get document: %w", err)
 	}
 	if doc == nil {
-		return nil, fmt.Errorf("service: document not found")
+		return nil, ErrDocumentNotFound
 	}
 	return doc, nil
 }
 
-// GetGraph retrieves the graph data for a document.
-func (s *documentService) GetGraph(ctx context.Context, docID int64) (*GraphData, error) {
+// GetGraph retrieves the graph data for a document, keeping only the edges valid on asOf
+// when it is set.
+func (s *documentService) GetGraph(ctx context.Context, docID int64, asOf *entity.Date) (*GraphData, error) {
 	nodes, err := s.graphRepo.GetNodesByDocumentID(ctx, docID)
 	if err != nil {
 		return nil, fmt.Errorf("service: failed to get nodes: %w", err)
@@ -72,7 +99,56 @@ func (s *documentService) GetGraph(ctx context.Context, docID int64) (*GraphData
 	if err != nil {
 		return nil, fmt.Errorf("service: failed to get edges: %w", err)
 	}
-	return &GraphData{Nodes: nodes, Edges: edges}, nil
+	return &GraphData{Nodes: nodes, Edges: edgesValidAt(edges, asOf)}, nil
+}
+
+// GetFile returns a presigned URL for the document's file, or a stream when presigning is not supported.
+func (s *documentService) GetFile(ctx context.Context, docID int64) (*DocumentFile, error) {
+	doc, err := s.GetDocument(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	if doc.FilePath == "" {
+		// Imported graphs have no source file
+		return nil, ErrFileNotFound
+	}
+
+	// Presigning does not check the object, so look it up first to report a missing file
+	info, err := s.blobStore.Stat(ctx, doc.FilePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("service: failed to stat file: %w", err)
+	}
+
+	url, err := s.blobStore.PresignedURL(ctx, doc.FilePath, doc.Filename, presignExpiry)
+	if err == nil {
+		return &DocumentFile{Document: doc, URL: url, Size: info.Size}, nil
+	}
+	if !errors.Is(err, storage.ErrPresignNotSupported) {
+		return nil, fmt.Errorf("service: failed to presign file: %w", err)
+	}
+
+	content, err := s.blobStore.Get(ctx, doc.FilePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("service: failed to open file: %w", err)
+	}
+	return &DocumentFile{Document: doc, Content: content, Size: info.Size}, nil
+}
+
+// DeleteDocument soft-deletes a document. Its chunks, vectors, graph and file are purged later.
+func (s *documentService) DeleteDocument(ctx context.Context, id int64) error {
+	if err := s.repo.SoftDelete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrDocumentNotFound
+		}
+		return fmt.Errorf("service: failed to delete document: %w", err)
+	}
+	return nil
 }
 
 // ListDocuments retrieves a paginated list of documents.
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/pkg/embedding"
+	"github.com/suyw-0123/graphweaver/pkg/llm"
+)
+
+const (
+	// maxLinkedEntities bounds the nodes a question is linked to. Nodes are per document, so
+	// one entity named in several documents links to several nodes.
+	maxLinkedEntities = 10
+	// minLinkSimilarity is the trigram word similarity a node name needs to a mention to be
+	// linked, pg_trgm's default word similarity threshold.
+	minLinkSimilarity = 0.6
+	// maxQuestionMentions bounds the entity mentions the LLM may extract from a question.
+	maxQuestionMentions = 5
+	// minSemanticLinkScore is the cosine similarity a node needs to a mention that matches
+	// no name to be linked by its embedding.
+	minSemanticLinkScore = 0.8
+
+	defaultEntitySearchLimit = 10
+	maxEntitySearchLimit     = 50
+	// minEntitySearchScore drops nodes barely related to an entity search.
+	minEntitySearchScore = 0.5
+)
+
+// LinkedEntity is a graph node that a question refers to.
+type LinkedEntity struct {
+	Node *entity.Node `json:"node"`
+	// Mention is the part of the question linked to the node.
+	Mention string `json:"mention"`
+	// Similarity is 1 for a name occurring verbatim in the question, otherwise the trigram
+	// word similarity of the name to the mention.
+	Similarity float64 `json:"similarity"`
+}
+
+// EntityMatch is a node found by semantic entity search.
+type EntityMatch struct {
+	Node *entity.Node `json:"node"`
+	// Score is the cosine similarity of the node's embedding to the query.
+	Score float64 `json:"score"`
+}
+
+// EntityLinkingService links the entities named in a question to nodes of a notebook graph.
+type EntityLinkingService interface {
+	LinkEntities(ctx context.Context, notebookID int64, question string) ([]*LinkedEntity, error)
+	// SearchEntities returns the notebook's nodes whose name and description are semantically
+	// closest to the query, most similar first.
+	SearchEntities(ctx context.Context, notebookID int64, query string, limit int) ([]*EntityMatch, error)
+}
+
+type entityLinkingService struct {
+	graphRepo       repository.GraphRepository
+	notebookRepo    repository.NotebookRepository
+	vectorRepo      repository.VectorRepository
+	embeddingClient embedding.Client
+	llmClient       llm.Client
+}
+
+// NewEntityLinkingService creates a new EntityLinkingService. Without an LLM client, node
+// names are matched against the whole question; without a vector store, entity search
+// fails and mentions are only linked by name.
+func NewEntityLinkingService(
+	graphRepo repository.GraphRepository,
+	notebookRepo repository.NotebookRepository,
+	vectorRepo repository.VectorRepository,
+	embeddingClient embedding.Client,
+	llmClient llm.Client,
+) EntityLinkingService {
+	return &entityLinkingService{
+		graphRepo:       graphRepo,
+		notebookRepo:    notebookRepo,
+		vectorRepo:      vectorRepo,
+		embeddingClient: embeddingClient,
+		llmClient:       llmClient,
+	}
+}
+
+// LinkEntities links the node names occurring verbatim in the question, then the entity
+// mentions the LLM extracts from it by fuzzy name matching, which catches misspellings and
+// inflections. A mention no name matches is linked to the node whose embedding is closest,
+// which catches synonyms and paraphrases. Results are ordered by similarity, most central
+// first.
+func (s *entityLinkingService) LinkEntities(ctx context.Context, notebookID int64, question string) ([]*LinkedEntity, error) {
+	nodes, err := s.graphRepo.GetNodesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get nodes: %w", err)
+	}
+	linked := make(map[int64]*LinkedEntity)
+	for _, n := range namedEntities(question, nodes, maxLinkedEntities) {
+		linked[n.ID] = &LinkedEntity{Node: n, Mention: n.Name, Similarity: 1}
+	}
+
+	mentions := []string{question}
+	if s.llmClient != nil {
+		extracted, err := s.extractMentions(ctx, question)
+		if err != nil {
+			fmt.Printf("Warning: failed to extract entities from question, matching it whole: %v\n", err)
+		} else {
+			mentions = extracted
+		}
+	}
+	for _, mention := range mentions {
+		matches, err := s.graphRepo.MatchNodeNames(ctx, notebookID, mention, minLinkSimilarity, maxLinkedEntities)
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to match entity names: %w", err)
+		}
+		for _, m := range matches {
+			if l, ok := linked[m.ID]; ok && l.Similarity >= m.Similarity {
+				continue
+			}
+			node := m.Node
+			linked[m.ID] = &LinkedEntity{Node: &node, Mention: mention, Similarity: m.Similarity}
+		}
+		if len(matches) > 0 || mention == question || s.vectorRepo == nil || s.embeddingClient == nil {
+			continue
+		}
+
+		similar, err := s.similarNodes(ctx, notebookID, mention, minSemanticLinkScore, 1)
+		if err != nil {
+			fmt.Printf("Warning: failed to link %q by embedding: %v\n", mention, err)
+			continue
+		}
+		for _, m := range similar {
+			if _, ok := linked[m.Node.ID]; !ok {
+				linked[m.Node.ID] = &LinkedEntity{Node: m.Node, Mention: mention, Similarity: m.Score}
+			}
+		}
+	}
+
+	entities := make([]*LinkedEntity, 0, len(linked))
+	for _, l := range linked {
+		entities = append(entities, l)
+	}
+	sortLinkedEntities(entities)
+	return entities[:min(len(entities), maxLinkedEntities)], nil
+}
+
+func (s *entityLinkingService) SearchEntities(ctx context.Context, notebookID int64, query string, limit int) ([]*EntityMatch, error) {
+	if _, err := s.notebookRepo.GetByID(ctx, notebookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotebookNotFound
+		}
+		return nil, fmt.Errorf("service: failed to get notebook: %w", err)
+	}
+	if s.vectorRepo == nil || s.embeddingClient == nil {
+		return nil, fmt.Errorf("service: entity search is not configured")
+	}
+	if limit <= 0 {
+		limit = defaultEntitySearchLimit
+	}
+	return s.similarNodes(ctx, notebookID, query, minEntitySearchScore, min(limit, maxEntitySearchLimit))
+}
+
+// similarNodes searches the node collection for the text among the points of the notebook's
+// documents. Points of nodes deleted or merged away since they were embedded are skipped.
+func (s *entityLinkingService) similarNodes(ctx context.Context, notebookID int64, text string, threshold float64, limit int) ([]*EntityMatch, error) {
+	vector, err := s.embeddingClient.EmbedText(ctx, text)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to embed entity query: %w", err)
+	}
+	exists, err := s.vectorRepo.CollectionExists(ctx, nodeCollection)
+	if err != nil || !exists {
+		return []*EntityMatch{}, err
+	}
+	nodes, err := s.graphRepo.GetNodesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get nodes: %w", err)
+	}
+	nodesByID := make(map[int64]*entity.Node, len(nodes))
+	docIDs := []int64{}
+	seenDocs := make(map[int64]bool)
+	for _, n := range nodes {
+		nodesByID[n.ID] = n
+		if !seenDocs[n.DocumentID] {
+			seenDocs[n.DocumentID] = true
+			docIDs = append(docIDs, n.DocumentID)
+		}
+	}
+
+	results, err := s.vectorRepo.Search(ctx, nodeCollection, vector, limit, float32(threshold), docIDs)
+	if err != nil {
+		return nil, fmt.Errorf("service: entity vector search failed: %w", err)
+	}
+
+	matches := []*EntityMatch{}
+	for _, res := range results {
+		id, ok := payloadInt64(res.Payload["node_id"])
+		if !ok || nodesByID[id] == nil {
+			continue
+		}
+		matches = append(matches, &EntityMatch{Node: nodesByID[id], Score: float64(res.Score)})
+	}
+	return matches, nil
+}
+
+// extractMentions asks the LLM for the entities a question names.
+func (s *entityLinkingService) extractMentions(ctx context.Context, question string) ([]string, error) {
+	prompt := fmt.Sprintf(`Extract the named entities (people, organizations, places, products, concepts) that the following question asks about.
+Copy each entity as it is written in the question. Do not add entities that are not in the question.
+Return ONLY a valid JSON object with the following structure:
+{"entities": ["Entity Name"]}
+If the question names no entity, return {"entities": []}.
+
+Question: %s`, question)
+
+	response, err := s.llmClient.GenerateContent(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Entities []string `json:"entities"`
+	}
+	if err := json.Unmarshal([]byte(cleanJSONResponse(response)), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse entities: %w", err)
+	}
+
+	var mentions []string
+	seen := make(map[string]bool)
+	for _, e := range result.Entities {
+		e = strings.TrimSpace(e)
+		if utf8.RuneCountInString(e) < 2 || seen[strings.ToLower(e)] {
+			continue
+		}
+		seen[strings.ToLower(e)] = true
+		mentions = append(mentions, e)
+	}
+	return mentions[:min(len(mentions), maxQuestionMentions)], nil
+}
+
+// sortLinkedEntities orders entities by similarity, then PageRank, then ID.
+func sortLinkedEntities(entities []*LinkedEntity) {
+	sort.Slice(entities, func(i, j int) bool {
+		a, b := entities[i], entities[j]
+		if a.Similarity != b.Similarity {
+			return a.Similarity > b.Similarity
+		}
+		if a.Node.PageRank != b.Node.PageRank {
+			return a.Node.PageRank > b.Node.PageRank
+		}
+		return a.Node.ID < b.Node.ID
+	})
+}
+
+// linkedNodes returns the nodes of linked entities.
+func linkedNodes(entities []*LinkedEntity) []*entity.Node {
+	nodes := make([]*entity.Node, len(entities))
+	for i, l := range entities {
+		nodes[i] = l.Node
+	}
+	return nodes
+}
+
+// namedEntities returns the nodes whose name occurs as a whole word in text, longest names
+// first so that "New York City" wins over "York".
+func namedEntities(text string, nodes []*entity.Node, limit int) []*entity.Node {
+	t := newChunkText(&entity.Chunk{Content: text})
+	var named []*entity.Node
+	for _, n := range nodes {
+		if utf8.RuneCountInString(n.Name) < 2 {
+			continue
+		}
+		if _, _, ok := t.indexWord(n.Name); ok {
+			named = append(named, n)
+		}
+	}
+	sort.SliceStable(named, func(i, j int) bool { return len(named[i].Name) > len(named[j].Name) })
+	return named[:min(len(named), limit)]
+}
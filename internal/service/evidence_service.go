@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+)
+
+const (
+	// maxNodeMentions bounds the mentions recorded per node; frequent entities would
+	// otherwise link to every chunk.
+	maxNodeMentions = 10
+	// maxQuoteLength bounds quotes built around a name, in bytes.
+	maxQuoteLength = 500
+)
+
+// EvidenceService returns the source text supporting graph entities.
+type EvidenceService interface {
+	GetNodeEvidence(ctx context.Context, nodeID int64) ([]*entity.Mention, error)
+	GetEdgeEvidence(ctx context.Context, edgeID int64) ([]*entity.Mention, error)
+}
+
+type evidenceService struct {
+	mentionRepo repository.MentionRepository
+	graphRepo   repository.GraphRepository
+}
+
+// NewEvidenceService creates a new EvidenceService.
+func NewEvidenceService(mentionRepo repository.MentionRepository, graphRepo repository.GraphRepository) EvidenceService {
+	return &evidenceService{mentionRepo: mentionRepo, graphRepo: graphRepo}
+}
+
+func (s *evidenceService) GetNodeEvidence(ctx context.Context, nodeID int64) ([]*entity.Mention, error) {
+	node, err := s.graphRepo.GetNode(ctx, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get node: %w", err)
+	}
+	if node == nil {
+		return nil, ErrNodeNotFound
+	}
+	mentions, err := s.mentionRepo.ListByNode(ctx, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get evidence: %w", err)
+	}
+	return mentions, nil
+}
+
+func (s *evidenceService) GetEdgeEvidence(ctx context.Context, edgeID int64) ([]*entity.Mention, error) {
+	edge, err := s.graphRepo.GetEdge(ctx, edgeID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get edge: %w", err)
+	}
+	if edge == nil {
+		return nil, ErrEdgeNotFound
+	}
+	mentions, err := s.mentionRepo.ListByEdge(ctx, edgeID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get evidence: %w", err)
+	}
+	return mentions, nil
+}
+
+// extractionQuotes holds the supporting quotes the LLM returned, keyed by entity
+// (name, label) and by relation (source name, type, target name).
+type extractionQuotes struct {
+	nodes map[[2]string]string
+	edges map[[3]string]string
+}
+
+// findMentions locates the evidence for a document's nodes and edges in its chunks. A quote
+// from the LLM is used when it occurs in a chunk. Nodes are also linked to the sentences
+// naming them, and edges without a quote to the first sentence span naming both endpoints.
+func findMentions(chunks []*entity.Chunk, nodes []*entity.Node, edges []*entity.Edge, quotes extractionQuotes) []*entity.Mention {
+	texts := make([]*chunkText, len(chunks))
+	for i, c := range chunks {
+		texts[i] = newChunkText(c)
+	}
+
+	var mentions []*entity.Mention
+	names := make(map[int64]string, len(nodes))
+	for _, n := range nodes {
+		names[n.ID] = n.Name
+		id := n.ID
+		linked := make(map[string]bool)
+		if m := locateQuote(texts, quotes.nodes[[2]string{n.Name, n.Label}]); m != nil {
+			m.NodeID = &id
+			linked[m.ChunkID] = true
+			mentions = append(mentions, m)
+		}
+		for _, t := range texts {
+			if len(linked) >= maxNodeMentions {
+				break
+			}
+			if linked[t.ID] {
+				continue
+			}
+			if start, end, ok := t.indexWord(n.Name); ok {
+				m := t.sentenceMention(start, end)
+				m.NodeID = &id
+				linked[t.ID] = true
+				mentions = append(mentions, m)
+			}
+		}
+	}
+
+	for _, e := range edges {
+		source, target := names[e.SourceNodeID], names[e.TargetNodeID]
+		if source == "" || target == "" {
+			continue
+		}
+		id := e.ID
+		m := locateQuote(texts, quotes.edges[[3]string{source, e.RelationType, target}])
+		if m == nil {
+			m = locatePair(texts, source, target)
+		}
+		if m != nil {
+			m.EdgeID = &id
+			mentions = append(mentions, m)
+		}
+	}
+	return mentions
+}
+
+// locateQuote finds the first chunk containing quote, ignoring case and whitespace
+// differences. Chunk content has its whitespace collapsed to single spaces.
+func locateQuote(texts []*chunkText, quote string) *entity.Mention {
+	quote = strings.Join(strings.Fields(quote), " ")
+	if quote == "" {
+		return nil
+	}
+	for _, t := range texts {
+		if start, end, ok := t.index(quote, 0); ok {
+			return t.mention(start, end)
+		}
+	}
+	return nil
+}
+
+// locatePair finds the first chunk naming both endpoints within one quote-sized span.
+func locatePair(texts []*chunkText, source, target string) *entity.Mention {
+	for _, t := range texts {
+		s1, e1, ok1 := t.indexWord(source)
+		s2, e2, ok2 := t.indexWord(target)
+		if !ok1 || !ok2 {
+			continue
+		}
+		start, end := min(s1, s2), max(e1, e2)
+		if end-start > maxQuoteLength {
+			continue
+		}
+		return t.sentenceMention(start, end)
+	}
+	return nil
+}
+
+// chunkText supports case-insensitive search in a chunk's content.
+type chunkText struct {
+	*entity.Chunk
+	lower string // lower-cased content, empty when lower-casing changes byte offsets
+}
+
+func newChunkText(c *entity.Chunk) *chunkText {
+	t := &chunkText{Chunk: c}
+	if lower := strings.ToLower(c.Content); len(lower) == len(c.Content) {
+		t.lower = lower
+	}
+	return t
+}
+
+// index returns the byte range of the first case-insensitive occurrence of substr at or
+// after byte offset from.
+func (t *chunkText) index(substr string, from int) (int, int, bool) {
+	if substr == "" || from >= len(t.Content) {
+		return 0, 0, false
+	}
+	if t.lower != "" {
+		lowered := strings.ToLower(substr)
+		if i := strings.Index(t.lower[from:], lowered); i >= 0 {
+			start, end := from+i, from+i+len(lowered)
+			if end <= len(t.Content) && utf8.RuneStart(t.Content[start]) && (end == len(t.Content) || utf8.RuneStart(t.Content[end])) {
+				return start, end, true
+			}
+		} else {
+			return 0, 0, false
+		}
+	}
+	for i := range t.Content[from:] {
+		if n, ok := hasPrefixFold(t.Content[from+i:], substr); ok {
+			return from + i, from + i + n, true
+		}
+	}
+	return 0, 0, false
+}
+
+// indexWord is like index but only matches whole words.
+func (t *chunkText) indexWord(word string) (int, int, bool) {
+	for from := 0; ; {
+		start, end, ok := t.index(word, from)
+		if !ok {
+			return 0, 0, false
+		}
+		before, _ := utf8.DecodeLastRuneInString(t.Content[:start])
+		after, _ := utf8.DecodeRuneInString(t.Content[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return start, end, true
+		}
+		_, size := utf8.DecodeRuneInString(t.Content[start:])
+		from = start + size
+	}
+}
+
+// sentenceMention quotes the sentences around content[start:end].
+func (t *chunkText) sentenceMention(start, end int) *entity.Mention {
+	text := t.Content
+	from := max(0, start-maxQuoteLength/2)
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	if i := strings.LastIndexAny(text[from:start], ".!?"); i >= 0 {
+		from += i + 1
+	}
+	for from < start && text[from] == ' ' {
+		from++
+	}
+	to := min(len(text), end+maxQuoteLength/2)
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+	if i := strings.IndexAny(text[end:to], ".!?"); i >= 0 {
+		to = end + i + 1
+	}
+	return t.mention(from, to)
+}
+
+// mention quotes content[start:end], converting byte offsets to characters.
+func (t *chunkText) mention(start, end int) *entity.Mention {
+	return &entity.Mention{
+		DocumentID:  t.DocumentID,
+		ChunkID:     t.ID,
+		StartOffset: utf8.RuneCountInString(t.Content[:start]),
+		EndOffset:   utf8.RuneCountInString(t.Content[:end]),
+		Quote:       t.Content[start:end],
+	}
+}
+
+// hasPrefixFold reports whether s starts with prefix, ignoring case, and the byte length of
+// the matched part of s.
+func hasPrefixFold(s, prefix string) (int, bool) {
+	n := 0
+	for _, pr := range prefix {
+		sr, size := utf8.DecodeRuneInString(s[n:])
+		if size == 0 || unicode.ToLower(sr) != unicode.ToLower(pr) {
+			return 0, false
+		}
+		n += size
+	}
+	return n, true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+func TestFindMentions(t *testing.T) {
+	chunks := []*entity.Chunk{
+		{ID: "c1", DocumentID: 1, Content: "Alice founded Acme in 1998. Alison joined later."},
+		{ID: "c2", DocumentID: 1, Content: "Zoë works at Acme. She met ALICE in Taipei."},
+	}
+	nodes := []*entity.Node{
+		{ID: 10, Name: "Alice", Label: "Person"},
+		{ID: 11, Name: "Acme", Label: "Organization"},
+		{ID: 12, Name: "Zoë", Label: "Person"},
+	}
+	edges := []*entity.Edge{
+		{ID: 20, SourceNodeID: 10, TargetNodeID: 11, RelationType: "FOUNDED"},
+		{ID: 21, SourceNodeID: 12, TargetNodeID: 11, RelationType: "WORKS_AT"},
+	}
+	quotes := extractionQuotes{
+		nodes: map[[2]string]string{},
+		edges: map[[3]string]string{{"Alice", "FOUNDED", "Acme"}: "alice  founded\nAcme in 1998"},
+	}
+
+	byEntity := make(map[int64][]*entity.Mention)
+	for _, m := range findMentions(chunks, nodes, edges, quotes) {
+		switch {
+		case m.NodeID != nil:
+			byEntity[*m.NodeID] = append(byEntity[*m.NodeID], m)
+		case m.EdgeID != nil:
+			byEntity[*m.EdgeID] = append(byEntity[*m.EdgeID], m)
+		}
+	}
+
+	alice := byEntity[10]
+	if len(alice) != 2 {
+		t.Fatalf("expected Alice to be mentioned in both chunks, got %d mentions", len(alice))
+	}
+	if alice[0].Quote != "Alice founded Acme in 1998." {
+		t.Errorf("expected the first sentence, got %q", alice[0].Quote)
+	}
+	if alice[1].ChunkID != "c2" || alice[1].Quote != "She met ALICE in Taipei." {
+		t.Errorf("expected a case-insensitive match in c2, got %q in %s", alice[1].Quote, alice[1].ChunkID)
+	}
+
+	founded := byEntity[20]
+	if len(founded) != 1 || founded[0].Quote != "Alice founded Acme in 1998" || founded[0].StartOffset != 0 {
+		t.Fatalf("expected the LLM quote for FOUNDED, got %+v", founded)
+	}
+
+	// Offsets count characters, not bytes
+	worksAt := byEntity[21]
+	if len(worksAt) != 1 || worksAt[0].Quote != "Zoë works at Acme." || worksAt[0].EndOffset != 18 {
+		t.Fatalf("expected the sentence naming both endpoints, got %+v", worksAt)
+	}
+}
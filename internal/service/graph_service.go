@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/pkg/analytics"
+)
+
+const (
+	maxTraversalDepth = 5
+	defaultNodeLimit  = 200
+	maxNodeLimit      = 2000
+
+	defaultKeyEntityLimit = 20
+	maxKeyEntityLimit     = 200
+
+	pageRankDamping   = 0.85
+	pageRankMaxIter   = 100
+	pageRankTolerance = 1e-6
+
+	// communityIDsPerScope spaces the community IDs of analytics runs over different
+	// documents or notebooks: community i of scope s gets ID s*communityIDsPerScope + i.
+	communityIDsPerScope = 1_000_000
+)
+
+var (
+	// ErrNodeNotFound is returned when a graph node does not exist.
+	ErrNodeNotFound = errors.New("service: node not found")
+	// ErrNotebookNotFound is returned when a notebook does not exist.
+	ErrNotebookNotFound = errors.New("service: notebook not found")
+)
+
+// GraphAnalytics summarizes an analytics run over a document or notebook graph.
+type GraphAnalytics struct {
+	NodeCount      int            `json:"node_count"`
+	EdgeCount      int            `json:"edge_count"`
+	CommunityCount int            `json:"community_count"`
+	KeyEntities    []*entity.Node `json:"key_entities"`
+}
+
+// GraphService exposes incremental exploration of the knowledge graph.
+type GraphService interface {
+	GetNeighbors(ctx context.Context, nodeID int64, q repository.NeighborQuery) (*GraphData, error)
+	FindPath(ctx context.Context, fromID, toID int64, direction string, maxDepth int, asOf *entity.Date) (*GraphData, error)
+	GetSubgraph(ctx context.Context, nodeID int64, depth, limit int, asOf *entity.Date) (*GraphData, error)
+	ComputeDocumentAnalytics(ctx context.Context, docID int64) (*GraphAnalytics, error)
+	ComputeNotebookAnalytics(ctx context.Context, notebookID int64) (*GraphAnalytics, error)
+	GetDocumentKeyEntities(ctx context.Context, docID int64, limit int) ([]*entity.Node, error)
+	GetNotebookKeyEntities(ctx context.Context, notebookID int64, limit int) ([]*entity.Node, error)
+	// GetDocumentGraph and GetNotebookGraph return the complete graph; a non-nil asOf keeps
+	// only the edges valid on that day.
+	GetDocumentGraph(ctx context.Context, docID int64, asOf *entity.Date) (*GraphData, error)
+	GetNotebookGraph(ctx context.Context, notebookID int64, asOf *entity.Date) (*GraphData, error)
+	// FindNodes returns the notebook's nodes with the filter's label and property values.
+	FindNodes(ctx context.Context, notebookID int64, f repository.PropertyFilter) ([]*entity.Node, error)
+	// FindEdges returns the notebook's edges with the filter's relation type and property values.
+	FindEdges(ctx context.Context, notebookID int64, f repository.PropertyFilter) ([]*entity.Edge, error)
+}
+
+type graphService struct {
+	graphRepo    repository.GraphRepository
+	docRepo      repository.DocumentRepository
+	notebookRepo repository.NotebookRepository
+}
+
+// NewGraphService creates a new GraphService.
+func NewGraphService(graphRepo repository.GraphRepository, docRepo repository.DocumentRepository, notebookRepo repository.NotebookRepository) GraphService {
+	return &graphService{graphRepo: graphRepo, docRepo: docRepo, notebookRepo: notebookRepo}
+}
+
+// GetNeighbors returns the neighborhood of a node, including the node itself.
+func (s *graphService) GetNeighbors(ctx context.Context, nodeID int64, q repository.NeighborQuery) (*GraphData, error) {
+	if err := s.ensureNode(ctx, nodeID); err != nil {
+		return nil, err
+	}
+	if q.Direction == "" {
+		q.Direction = repository.DirectionBoth
+	}
+	q.Depth = clamp(q.Depth, 1, maxTraversalDepth)
+	q.Limit = clampLimit(q.Limit)
+
+	nodes, edges, err := s.graphRepo.GetNeighbors(ctx, nodeID, q)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get neighbors: %w", err)
+	}
+	return &GraphData{Nodes: nodes, Edges: edges}, nil
+}
+
+// FindPath returns a shortest path between two nodes, or empty graph data if none exists.
+func (s *graphService) FindPath(ctx context.Context, fromID, toID int64, direction string, maxDepth int, asOf *entity.Date) (*GraphData, error) {
+	if err := s.ensureNode(ctx, fromID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureNode(ctx, toID); err != nil {
+		return nil, err
+	}
+	if direction == "" {
+		direction = repository.DirectionBoth
+	}
+
+	nodes, edges, err := s.graphRepo.ShortestPath(ctx, fromID, toID, direction, clamp(maxDepth, 1, maxTraversalDepth), asOf)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to find path: %w", err)
+	}
+	return &GraphData{Nodes: nodes, Edges: edges}, nil
+}
+
+// GetSubgraph returns the ego graph of a node: its neighborhood and all edges within it.
+func (s *graphService) GetSubgraph(ctx context.Context, nodeID int64, depth, limit int, asOf *entity.Date) (*GraphData, error) {
+	if err := s.ensureNode(ctx, nodeID); err != nil {
+		return nil, err
+	}
+
+	nodes, edges, err := s.graphRepo.GetSubgraph(ctx, nodeID, clamp(depth, 1, maxTraversalDepth), clampLimit(limit), asOf)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get subgraph: %w", err)
+	}
+	return &GraphData{Nodes: nodes, Edges: edges}, nil
+}
+
+// ComputeDocumentAnalytics scores the nodes of a single document's graph.
+func (s *graphService) ComputeDocumentAnalytics(ctx context.Context, docID int64) (*GraphAnalytics, error) {
+	if err := s.ensureDocument(ctx, docID); err != nil {
+		return nil, err
+	}
+	nodes, err := s.graphRepo.GetNodesByDocumentID(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get nodes: %w", err)
+	}
+	edges, err := s.graphRepo.GetEdgesByDocumentID(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get edges: %w", err)
+	}
+	scores := computeNodeScores(docID, nodes, edges)
+	if err := s.graphRepo.UpdateNodeScores(ctx, scores); err != nil {
+		return nil, fmt.Errorf("service: failed to store node scores: %w", err)
+	}
+	return graphAnalytics(nodes, edges, scores), nil
+}
+
+// ComputeNotebookAnalytics scores the nodes of all documents in a notebook as one graph,
+// so entities linked across documents rank accordingly. The scores are stored apart from
+// the per-document scores.
+func (s *graphService) ComputeNotebookAnalytics(ctx context.Context, notebookID int64) (*GraphAnalytics, error) {
+	if err := s.ensureNotebook(ctx, notebookID); err != nil {
+		return nil, err
+	}
+	nodes, edges, scores, err := scoreNotebook(ctx, s.graphRepo, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	return graphAnalytics(nodes, edges, scores), nil
+}
+
+// GetDocumentKeyEntities returns a document's nodes ranked by their stored scores.
+func (s *graphService) GetDocumentKeyEntities(ctx context.Context, docID int64, limit int) ([]*entity.Node, error) {
+	if err := s.ensureDocument(ctx, docID); err != nil {
+		return nil, err
+	}
+	nodes, err := s.graphRepo.GetNodesByDocumentID(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get nodes: %w", err)
+	}
+	return topEntities(nodes, clampKeyEntityLimit(limit)), nil
+}
+
+// GetNotebookKeyEntities returns a notebook's nodes ranked by their notebook-wide scores,
+// which the returned nodes carry instead of their per-document scores.
+func (s *graphService) GetNotebookKeyEntities(ctx context.Context, notebookID int64, limit int) ([]*entity.Node, error) {
+	if err := s.ensureNotebook(ctx, notebookID); err != nil {
+		return nil, err
+	}
+	nodes, err := s.graphRepo.GetNodesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get nodes: %w", err)
+	}
+	scores, err := s.graphRepo.GetNotebookNodeScores(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get node scores: %w", err)
+	}
+	// Nodes added since the notebook was last scored have no notebook-wide scores
+	for _, n := range nodes {
+		n.PageRank, n.DegreeCentrality, n.Betweenness, n.CommunityID = 0, 0, 0, nil
+	}
+	applyNodeScores(nodes, scores)
+	return topEntities(nodes, clampKeyEntityLimit(limit)), nil
+}
+
+// GetDocumentGraph returns the complete graph of a document.
+func (s *graphService) GetDocumentGraph(ctx context.Context, docID int64, asOf *entity.Date) (*GraphData, error) {
+	if err := s.ensureDocument(ctx, docID); err != nil {
+		return nil, err
+	}
+	nodes, err := s.graphRepo.GetNodesByDocumentID(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get nodes: %w", err)
+	}
+	edges, err := s.graphRepo.GetEdgesByDocumentID(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get edges: %w", err)
+	}
+	return &GraphData{Nodes: nodes, Edges: edgesValidAt(edges, asOf)}, nil
+}
+
+// GetNotebookGraph returns the combined graph of all documents in a notebook.
+func (s *graphService) GetNotebookGraph(ctx context.Context, notebookID int64, asOf *entity.Date) (*GraphData, error) {
+	if err := s.ensureNotebook(ctx, notebookID); err != nil {
+		return nil, err
+	}
+	nodes, err := s.graphRepo.GetNodesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get nodes: %w", err)
+	}
+	edges, err := s.graphRepo.GetEdgesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get edges: %w", err)
+	}
+	return &GraphData{Nodes: nodes, Edges: edgesValidAt(edges, asOf)}, nil
+}
+
+func (s *graphService) FindNodes(ctx context.Context, notebookID int64, f repository.PropertyFilter) ([]*entity.Node, error) {
+	if err := s.ensureNotebook(ctx, notebookID); err != nil {
+		return nil, err
+	}
+	f.Limit = clampLimit(f.Limit)
+	nodes, err := s.graphRepo.FindNodes(ctx, notebookID, f)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to find nodes: %w", err)
+	}
+	return nodes, nil
+}
+
+func (s *graphService) FindEdges(ctx context.Context, notebookID int64, f repository.PropertyFilter) ([]*entity.Edge, error) {
+	if err := s.ensureNotebook(ctx, notebookID); err != nil {
+		return nil, err
+	}
+	f.Limit = clampLimit(f.Limit)
+	edges, err := s.graphRepo.FindEdges(ctx, notebookID, f)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to find edges: %w", err)
+	}
+	return edges, nil
+}
+
+// scoreNotebook computes and stores the notebook-wide scores of a notebook's nodes.
+func scoreNotebook(ctx context.Context, graphRepo repository.GraphRepository, notebookID int64) ([]*entity.Node, []*entity.Edge, []*entity.NodeScore, error) {
+	nodes, err := graphRepo.GetNodesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("service: failed to get nodes: %w", err)
+	}
+	edges, err := graphRepo.GetEdgesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("service: failed to get edges: %w", err)
+	}
+	scores := computeNodeScores(notebookID, nodes, edges)
+	if err := graphRepo.ReplaceNotebookNodeScores(ctx, notebookID, scores); err != nil {
+		return nil, nil, nil, fmt.Errorf("service: failed to store node scores: %w", err)
+	}
+	return nodes, edges, scores, nil
+}
+
+// graphAnalytics summarizes the scores of an analytics run, applying them to the nodes.
+func graphAnalytics(nodes []*entity.Node, edges []*entity.Edge, scores []*entity.NodeScore) *GraphAnalytics {
+	applyNodeScores(nodes, scores)
+
+	communities := make(map[int64]bool)
+	for _, sc := range scores {
+		communities[sc.CommunityID] = true
+	}
+
+	return &GraphAnalytics{
+		NodeCount:      len(nodes),
+		EdgeCount:      len(edges),
+		CommunityCount: len(communities),
+		KeyEntities:    topEntities(nodes, defaultKeyEntityLimit),
+	}
+}
+
+// computeNodeScores runs centrality and community detection over the given nodes of a
+// document or notebook, the scope. Edges with an endpoint outside the node set are ignored.
+// Communities come from the coarsest Louvain level and are numbered within the scope.
+func computeNodeScores(scope int64, nodes []*entity.Node, edges []*entity.Edge) []*entity.NodeScore {
+	ids := make([]int64, len(nodes))
+	known := make(map[int64]bool, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+		known[n.ID] = true
+	}
+
+	g := analytics.NewGraph(ids)
+	for _, e := range edges {
+		if known[e.SourceNodeID] && known[e.TargetNodeID] {
+			g.AddEdge(e.SourceNodeID, e.TargetNodeID)
+		}
+	}
+
+	pageRank := analytics.PageRank(g, pageRankDamping, pageRankMaxIter, pageRankTolerance)
+	degree := analytics.DegreeCentrality(g)
+	betweenness := analytics.BetweennessCentrality(g)
+	var community map[int64]int
+	if levels := analytics.Louvain(g); len(levels) > 0 {
+		community = levels[len(levels)-1]
+	}
+
+	scores := make([]*entity.NodeScore, len(ids))
+	for i, id := range ids {
+		scores[i] = &entity.NodeScore{
+			NodeID:           id,
+			PageRank:         pageRank[id],
+			DegreeCentrality: degree[id],
+			Betweenness:      betweenness[id],
+			CommunityID:      scope*communityIDsPerScope + int64(community[id]),
+		}
+	}
+	return scores
+}
+
+func applyNodeScores(nodes []*entity.Node, scores []*entity.NodeScore) {
+	byID := make(map[int64]*entity.NodeScore, len(scores))
+	for _, sc := range scores {
+		byID[sc.NodeID] = sc
+	}
+	for _, n := range nodes {
+		if sc, ok := byID[n.ID]; ok {
+			communityID := sc.CommunityID
+			n.PageRank = sc.PageRank
+			n.DegreeCentrality = sc.DegreeCentrality
+			n.Betweenness = sc.Betweenness
+			n.CommunityID = &communityID
+		}
+	}
+}
+
+// rankNodes sorts nodes by PageRank, then degree centrality, most central first.
+func rankNodes(nodes []*entity.Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].PageRank != nodes[j].PageRank {
+			return nodes[i].PageRank > nodes[j].PageRank
+		}
+		return nodes[i].DegreeCentrality > nodes[j].DegreeCentrality
+	})
+}
+
+func topEntities(nodes []*entity.Node, limit int) []*entity.Node {
+	ranked := make([]*entity.Node, len(nodes))
+	copy(ranked, nodes)
+	rankNodes(ranked)
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+func clampKeyEntityLimit(limit int) int {
+	if limit <= 0 {
+		return defaultKeyEntityLimit
+	}
+	return min(limit, maxKeyEntityLimit)
+}
+
+func (s *graphService) ensureDocument(ctx context.Context, id int64) error {
+	doc, err := s.docRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service: failed to get document: %w", err)
+	}
+	if doc == nil {
+		return ErrDocumentNotFound
+	}
+	return nil
+}
+
+func (s *graphService) ensureNotebook(ctx context.Context, id int64) error {
+	if _, err := s.notebookRepo.GetByID(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotebookNotFound
+		}
+		return fmt.Errorf("service: failed to get notebook: %w", err)
+	}
+	return nil
+}
+
+func (s *graphService) ensureNode(ctx context.Context, id int64) error {
+	node, err := s.graphRepo.GetNode(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service: failed to get node: %w", err)
+	}
+	if node == nil {
+		return ErrNodeNotFound
+	}
+	return nil
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
+
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return defaultNodeLimit
+	}
+	return min(limit, maxNodeLimit)
+}
+
+// edgesValidAt returns the edges valid on asOf, or all edges when asOf is nil.
+func edgesValidAt(edges []*entity.Edge, asOf *entity.Date) []*entity.Edge {
+	if asOf == nil {
+		return edges
+	}
+	valid := make([]*entity.Edge, 0, len(edges))
+	for _, e := range edges {
+		if e.ValidAt(*asOf) {
+			valid = append(valid, e)
+		}
+	}
+	return valid
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/importer"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+)
+
+// importMimeType marks the synthetic documents that hold imported graphs. They have no
+// stored file and do not count towards the one uploaded document per notebook.
+const importMimeType = "application/vnd.graphweaver.import"
+
+// Entity matching modes for imports.
+const (
+	MatchNone      = "none"       // always create new entities
+	MatchName      = "name"       // reuse an existing entity with the same name
+	MatchNameLabel = "name_label" // reuse an existing entity with the same name and label
+)
+
+const (
+	defaultImportLabel    = "Entity"
+	defaultImportRelation = "RELATED_TO"
+)
+
+// ErrEmptyImport is returned when an imported graph has no entities.
+var ErrEmptyImport = errors.New("service: imported graph has no entities")
+
+// ImportResult describes the outcome of a graph import.
+type ImportResult struct {
+	Document     *entity.Document `json:"document"`
+	NodesCreated int              `json:"nodes_created"`
+	NodesMatched int              `json:"nodes_matched"`
+	EdgesCreated int              `json:"edges_created"`
+	EdgesSkipped int              `json:"edges_skipped"` // edges referencing unknown entities
+}
+
+// ImportService imports graphs from external sources into notebooks.
+type ImportService interface {
+	// ImportGraph stores the graph in the notebook as a synthetic document named filename.
+	// Entities matching an existing notebook entity, as decided by match, are not created
+	// again; imported relations attach to the existing entity instead.
+	ImportGraph(ctx context.Context, notebookID int64, filename string, graph *importer.Graph, match string) (*ImportResult, error)
+}
+
+type importService struct {
+	docRepo      repository.DocumentRepository
+	graphRepo    repository.GraphRepository
+	notebookRepo repository.NotebookRepository
+}
+
+// NewImportService creates a new ImportService.
+func NewImportService(
+	docRepo repository.DocumentRepository,
+	graphRepo repository.GraphRepository,
+	notebookRepo repository.NotebookRepository,
+) ImportService {
+	return &importService{
+		docRepo:      docRepo,
+		graphRepo:    graphRepo,
+		notebookRepo: notebookRepo,
+	}
+}
+
+func (s *importService) ImportGraph(ctx context.Context, notebookID int64, filename string, graph *importer.Graph, match string) (*ImportResult, error) {
+	if _, err := s.notebookRepo.GetByID(ctx, notebookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotebookNotFound
+		}
+		return nil, fmt.Errorf("service: failed to get notebook: %w", err)
+	}
+	if len(graph.Nodes) == 0 {
+		return nil, ErrEmptyImport
+	}
+
+	// Index the entities already in the notebook
+	existing := make(map[string]int64)
+	if match != MatchNone {
+		nodes, err := s.graphRepo.GetNodesByNotebookID(ctx, notebookID)
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to get notebook nodes: %w", err)
+		}
+		for _, n := range nodes {
+			key := matchKey(match, n.Name, n.Label)
+			if _, ok := existing[key]; !ok {
+				existing[key] = n.ID
+			}
+		}
+	}
+
+	result := &ImportResult{}
+	ids := make(map[string]int64, len(graph.Nodes)) // source key -> placeholder or existing ID
+	var nodes []*entity.Node
+	for _, n := range graph.Nodes {
+		name := strings.TrimSpace(n.Name)
+		if name == "" {
+			name = n.Key
+		}
+		label := strings.TrimSpace(n.Label)
+		if label == "" {
+			label = defaultImportLabel
+		}
+
+		if match != MatchNone {
+			if id, ok := existing[matchKey(match, name, label)]; ok {
+				ids[n.Key] = id
+				result.NodesMatched++
+				continue
+			}
+		}
+		node := &entity.Node{
+			ID:         -int64(len(nodes) + 1),
+			Label:      label,
+			Name:       name,
+			Properties: entity.Properties(n.Properties),
+			Source:     entity.SourceImport,
+		}
+		ids[n.Key] = node.ID
+		nodes = append(nodes, node)
+	}
+
+	var edges []*entity.Edge
+	for _, e := range graph.Edges {
+		source, ok1 := ids[e.Source]
+		target, ok2 := ids[e.Target]
+		if !ok1 || !ok2 {
+			result.EdgesSkipped++
+			continue
+		}
+		relType := strings.TrimSpace(e.RelationType)
+		if relType == "" {
+			relType = defaultImportRelation
+		}
+		edges = append(edges, &entity.Edge{
+			SourceNodeID: source,
+			TargetNodeID: target,
+			RelationType: relType,
+			Properties:   entity.Properties(e.Properties),
+			Source:       entity.SourceImport,
+		})
+	}
+
+	doc := &entity.Document{
+		Filename:   filename,
+		MimeType:   importMimeType,
+		Status:     "processing",
+		NotebookID: &notebookID,
+	}
+	if err := s.docRepo.Create(ctx, doc); err != nil {
+		return nil, fmt.Errorf("service: failed to create import document: %w", err)
+	}
+
+	if err := s.graphRepo.SaveGraph(ctx, doc.ID, nodes, edges); err != nil {
+		errMsg := err.Error()
+		_ = s.docRepo.UpdateStatus(ctx, doc.ID, "failed", &errMsg)
+		return nil, fmt.Errorf("service: failed to save imported graph: %w", err)
+	}
+	if len(nodes) > 0 {
+		if err := s.graphRepo.UpdateNodeScores(ctx, computeNodeScores(doc.ID, nodes, edges)); err != nil {
+			fmt.Printf("Warning: failed to compute graph analytics: %v\n", err)
+		}
+	}
+	if _, _, _, err := scoreNotebook(ctx, s.graphRepo, notebookID); err != nil {
+		fmt.Printf("Warning: failed to compute notebook graph analytics: %v\n", err)
+	}
+
+	summary := fmt.Sprintf("Imported %d entities and %d relations.", len(nodes), len(edges))
+	if result.NodesMatched > 0 {
+		summary += fmt.Sprintf(" %d entities matched existing ones.", result.NodesMatched)
+	}
+	if err := s.docRepo.UpdateSummary(ctx, doc.ID, summary); err != nil {
+		fmt.Printf("Warning: failed to save import summary: %v\n", err)
+	}
+	if err := s.docRepo.UpdateStatus(ctx, doc.ID, "completed", nil); err != nil {
+		return nil, fmt.Errorf("service: failed to update import document: %w", err)
+	}
+	doc.Summary = &summary
+	doc.Status = "completed"
+
+	result.Document = doc
+	result.NodesCreated = len(nodes)
+	result.EdgesCreated = len(edges)
+	return result, nil
+}
+
+// matchKey normalizes the fields compared by an entity matching mode.
+func matchKey(match, name, label string) string {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if match == MatchNameLabel {
+		key += "\x00" + strings.ToLower(strings.TrimSpace(label))
+	}
+	return key
+}
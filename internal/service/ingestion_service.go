@@ -2,14 +2,20 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"mime/multipart"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/suyw-0123/graphweaver/internal/entity"
@@ -17,97 +23,566 @@ import (
 	"github.com/suyw-0123/graphweaver/pkg/embedding"
 	"github.com/suyw-0123/graphweaver/pkg/llm"
 	"github.com/suyw-0123/graphweaver/pkg/parser"
+	"github.com/suyw-0123/graphweaver/pkg/storage"
+)
+
+const (
+	// chunkCollection is the Qdrant collection holding chunk embeddings.
+	chunkCollection = "documents"
+	// nodeCollection is the Qdrant collection holding embeddings of node names and descriptions.
+	nodeCollection = "entities"
+	// embeddingDim is the vector size of text-embedding-004.
+	embeddingDim = 768
 )
 
 // IngestionService defines the logic for processing uploaded files.
 type IngestionService interface {
-	ProcessUpload(ctx context.Context, file multipart.File, header *multipart.FileHeader, notebookID *int64) (*entity.Document, error)
+	// ProcessUpload stores the file and starts processing it. When the same content was already
+	// uploaded to the notebook, the existing document is returned and created is false.
+	ProcessUpload(ctx context.Context, file multipart.File, header *multipart.FileHeader, notebookID *int64) (doc *entity.Document, created bool, err error)
+	// Reprocess extracts a document again from its stored file. Curated nodes and edges, and
+	// the corrections recorded in the edit trail, are kept.
+	Reprocess(ctx context.Context, docID int64) (*entity.Document, error)
 }
 
+// ErrNotReprocessable is returned when a document cannot be processed again.
+var ErrNotReprocessable = errors.New("service: document cannot be reprocessed")
+
 type ingestionService struct {
 	docRepo         repository.DocumentRepository
+	notebookRepo    repository.NotebookRepository
 	graphRepo       repository.GraphRepository
+	editRepo        repository.GraphEditRepository
+	mentionRepo     repository.MentionRepository
 	chunkRepo       repository.ChunkRepository
 	vectorRepo      repository.VectorRepository
 	llmClient       llm.Client
 	embeddingClient embedding.Client
-	uploadDir       string
+	blobStore       storage.BlobStore
 }
 
 // NewIngestionService creates a new IngestionService.
 func NewIngestionService(
 	docRepo repository.DocumentRepository,
+	notebookRepo repository.NotebookRepository,
 	graphRepo repository.GraphRepository,
+	editRepo repository.GraphEditRepository,
+	mentionRepo repository.MentionRepository,
 	chunkRepo repository.ChunkRepository,
 	vectorRepo repository.VectorRepository,
 	llmClient llm.Client,
 	embeddingClient embedding.Client,
-	uploadDir string,
+	blobStore storage.BlobStore,
 ) IngestionService {
-	// Ensure upload directory exists
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		// In a real app, we might want to handle this error more gracefully or panic at startup
-		fmt.Printf("Warning: failed to create upload dir: %v\n", err)
-	}
 	return &ingestionService{
 		docRepo:         docRepo,
+		notebookRepo:    notebookRepo,
 		graphRepo:       graphRepo,
+		editRepo:        editRepo,
+		mentionRepo:     mentionRepo,
 		chunkRepo:       chunkRepo,
 		vectorRepo:      vectorRepo,
 		llmClient:       llmClient,
 		embeddingClient: embeddingClient,
-		uploadDir:       uploadDir,
+		blobStore:       blobStore,
 	}
 }
 
-func (s *ingestionService) ProcessUpload(ctx context.Context, file multipart.File, header *multipart.FileHeader, notebookID *int64) (*entity.Document, error) {
-	// 0. Check if notebook already has a document
+func (s *ingestionService) ProcessUpload(ctx context.Context, file multipart.File, header *multipart.FileHeader, notebookID *int64) (*entity.Document, bool, error) {
 	if notebookID != nil {
-		existingDocs, err := s.docRepo.List(ctx, 1, 0, notebookID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check existing documents: %w", err)
-		}
-		if len(existingDocs) > 0 {
-			return nil, fmt.Errorf("notebook already contains a document. Only one document per notebook is allowed.")
+		if _, err := s.notebookRepo.GetByID(ctx, *notebookID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, false, ErrNotebookNotFound
+			}
+			return nil, false, fmt.Errorf("failed to get notebook: %w", err)
 		}
 	}
 
-	// 1. Save file to local storage
-	filename := fmt.Sprintf("%d_%s", time.Now().Unix(), header.Filename)
-	filePath := filepath.Join(s.uploadDir, filename)
+	// 1. Save file to content-addressed blob storage
+	mimeType := header.Header.Get("Content-Type")
+	contentHash, fileKey, stored, err := s.storeFile(ctx, file, header.Filename, mimeType)
+	if err != nil {
+		return nil, false, err
+	}
+	// A file stored by this upload is removed again if the upload is rejected
+	discard := func() {
+		if stored {
+			s.discardFile(ctx, fileKey)
+		}
+	}
 
-	dst, err := os.Create(filePath)
+	// 2. Return the existing document if this content was already uploaded to the notebook
+	existing, err := s.docRepo.FindByContentHash(ctx, contentHash, notebookID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create destination file: %w", err)
+		discard()
+		return nil, false, fmt.Errorf("failed to check for duplicate upload: %w", err)
+	}
+	if existing != nil {
+		return existing, false, nil
 	}
-	defer dst.Close()
 
-	if _, err := io.Copy(dst, file); err != nil {
-		return nil, fmt.Errorf("failed to save file: %w", err)
+	// 3. Check if notebook already has a document; imported graphs don't count
+	if notebookID != nil {
+		existingDocs, err := s.docRepo.ListAllByNotebook(ctx, *notebookID)
+		if err != nil {
+			discard()
+			return nil, false, fmt.Errorf("failed to check existing documents: %w", err)
+		}
+		for _, d := range existingDocs {
+			if !d.IsDeleted && d.MimeType != importMimeType {
+				discard()
+				return nil, false, fmt.Errorf("notebook already contains a document. Only one document per notebook is allowed.")
+			}
+		}
 	}
 
-	// 2. Create Document Metadata
+	// 4. Create Document Metadata
 	doc := &entity.Document{
-		Filename:   header.Filename,
-		FilePath:   filePath,
-		MimeType:   header.Header.Get("Content-Type"),
-		FileSize:   header.Size,
-		Status:     "processing",
-		NotebookID: notebookID,
+		Filename:    header.Filename,
+		FilePath:    fileKey,
+		MimeType:    mimeType,
+		FileSize:    header.Size,
+		ContentHash: &contentHash,
+		Status:      "processing",
+		NotebookID:  notebookID,
 	}
 
 	if err := s.docRepo.Create(ctx, doc); err != nil {
-		return nil, fmt.Errorf("failed to create document record: %w", err)
+		discard()
+		return nil, false, fmt.Errorf("failed to create document record: %w", err)
 	}
 
-	// 3. Trigger Async Processing (Goroutine)
+	// 5. Trigger Async Processing (Goroutine)
 	// In a production system, this should be a job queue (e.g., Redis/Kafka)
-	go s.processDocumentAsync(doc.ID, filePath)
+	// Content that was already processed elsewhere is copied instead of re-running the LLM.
+	source, err := s.docRepo.FindCompletedByContentHash(ctx, contentHash)
+	if err != nil {
+		fmt.Printf("Warning: failed to look up processed copy of %s: %v\n", contentHash, err)
+	}
+	if source != nil && source.ID != doc.ID {
+		go s.cloneDocumentAsync(source, doc.ID)
+	} else {
+		go s.processDocumentAsync(doc.ID, fileKey)
+	}
+
+	return doc, true, nil
+}
 
+func (s *ingestionService) Reprocess(ctx context.Context, docID int64) (*entity.Document, error) {
+	doc, err := s.docRepo.GetByID(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get document: %w", err)
+	}
+	if doc == nil || doc.IsDeleted {
+		return nil, ErrDocumentNotFound
+	}
+	if doc.FilePath == "" || doc.MimeType == importMimeType {
+		return nil, fmt.Errorf("%w: it has no stored file", ErrNotReprocessable)
+	}
+	if doc.Status == "processing" || doc.Status == "embedding" {
+		return nil, fmt.Errorf("%w: it is still being processed", ErrNotReprocessable)
+	}
+
+	if err := s.docRepo.UpdateStatus(ctx, docID, "processing", nil); err != nil {
+		return nil, fmt.Errorf("service: failed to update document status: %w", err)
+	}
+	doc.Status = "processing"
+	doc.ErrorMessage = nil
+	go s.processDocumentAsync(doc.ID, doc.FilePath)
 	return doc, nil
 }
 
-func (s *ingestionService) processDocumentAsync(docID int64, filePath string) {
+// storeFile stores the upload under the key <hash[:2]>/<hash><ext> and returns the hex SHA-256 and key,
+// and whether the file was stored now rather than already present. The extension is kept because
+// parsing dispatches on it.
+func (s *ingestionService) storeFile(ctx context.Context, file io.Reader, originalName, mimeType string) (string, string, bool, error) {
+	// Spool to a temporary file first: the key is only known once the whole content is hashed
+	tmp, err := os.CreateTemp("", "graphweaver-upload-*")
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), file)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to save file: %w", err)
+	}
+
+	contentHash := hex.EncodeToString(hasher.Sum(nil))
+	key := path.Join(contentHash[:2], contentHash+strings.ToLower(filepath.Ext(originalName)))
+
+	if _, err := s.blobStore.Stat(ctx, key); err == nil {
+		// Identical content is already stored
+		return contentHash, key, false, nil
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return "", "", false, fmt.Errorf("failed to check storage: %w", err)
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", "", false, fmt.Errorf("failed to rewind temporary file: %w", err)
+	}
+	if err := s.blobStore.Put(ctx, key, tmp, size, mimeType); err != nil {
+		return "", "", false, fmt.Errorf("failed to store file: %w", err)
+	}
+	return contentHash, key, true, nil
+}
+
+// discardFile deletes a stored file that no document references.
+func (s *ingestionService) discardFile(ctx context.Context, key string) {
+	refs, err := s.docRepo.CountByFilePath(ctx, key)
+	if err != nil {
+		fmt.Printf("Warning: failed to check references to %s, keeping it: %v\n", key, err)
+		return
+	}
+	if refs > 0 {
+		return
+	}
+	if err := s.blobStore.Delete(ctx, key); err != nil {
+		fmt.Printf("Warning: failed to delete unused file %s: %v\n", key, err)
+	}
+}
+
+// cloneDocumentAsync copies the summary, chunks, vectors and graph of an already processed
+// document with identical content into docID.
+func (s *ingestionService) cloneDocumentAsync(source *entity.Document, docID int64) {
+	ctx := context.Background()
+
+	if err := s.cloneDocument(ctx, source, docID); err != nil {
+		errMsg := fmt.Sprintf("failed to copy processed document %d: %v", source.ID, err)
+		_ = s.docRepo.UpdateStatus(ctx, docID, "failed", &errMsg)
+		return
+	}
+
+	_ = s.docRepo.UpdateStatus(ctx, docID, "completed", nil)
+}
+
+func (s *ingestionService) cloneDocument(ctx context.Context, source *entity.Document, docID int64) error {
+	if source.Summary != nil {
+		if err := s.docRepo.UpdateSummary(ctx, docID, *source.Summary); err != nil {
+			return err
+		}
+	}
+
+	var chunkIDs map[string]string
+	if s.chunkRepo != nil {
+		var err error
+		if chunkIDs, err = s.cloneChunks(ctx, source.ID, docID); err != nil {
+			return err
+		}
+	}
+
+	// Copy graph, remapping node IDs for the edges
+	nodes, err := s.graphRepo.GetNodesByDocumentID(ctx, source.ID)
+	if err != nil {
+		return err
+	}
+	edges, err := s.graphRepo.GetEdgesByDocumentID(ctx, source.ID)
+	if err != nil {
+		return err
+	}
+
+	// Source IDs become placeholders so SaveGraph can remap the edges
+	copies := make([]*entity.Node, len(nodes))
+	copied := make(map[int64]bool, len(nodes))
+	for i, n := range nodes {
+		copied[n.ID] = true
+		copies[i] = &entity.Node{
+			ID:         -n.ID,
+			DocumentID: docID,
+			Label:      n.Label,
+			Name:       n.Name,
+			Properties: n.Properties,
+			Source:     n.Source,
+		}
+	}
+	edgeCopies := make([]*entity.Edge, len(edges))
+	for i, e := range edges {
+		edgeCopies[i] = &entity.Edge{
+			DocumentID:   docID,
+			SourceNodeID: placeholderFor(copied, e.SourceNodeID),
+			TargetNodeID: placeholderFor(copied, e.TargetNodeID),
+			RelationType: e.RelationType,
+			Properties:   e.Properties,
+			Source:       e.Source,
+			ValidFrom:    e.ValidFrom,
+			ValidTo:      e.ValidTo,
+		}
+	}
+
+	if err := s.graphRepo.SaveGraph(ctx, docID, copies, edgeCopies); err != nil {
+		return err
+	}
+	s.scoreGraph(ctx, docID, copies, edgeCopies)
+	s.embedNodes(ctx, docID)
+
+	nodeIDs := make(map[int64]int64, len(nodes))
+	for i, n := range nodes {
+		nodeIDs[n.ID] = copies[i].ID
+	}
+	edgeIDs := make(map[int64]int64, len(edges))
+	for i, e := range edges {
+		edgeIDs[e.ID] = edgeCopies[i].ID
+	}
+	return s.cloneMentions(ctx, source.ID, docID, chunkIDs, nodeIDs, edgeIDs)
+}
+
+// cloneMentions copies the evidence of a document, mapping chunk, node and edge IDs to
+// those of the copy. Mentions of entities outside the mapping are dropped.
+func (s *ingestionService) cloneMentions(ctx context.Context, sourceDocID, docID int64, chunkIDs map[string]string, nodeIDs, edgeIDs map[int64]int64) error {
+	if s.mentionRepo == nil || len(chunkIDs) == 0 {
+		return nil
+	}
+	mentions, err := s.mentionRepo.ListByDocument(ctx, sourceDocID)
+	if err != nil {
+		return err
+	}
+
+	var copies []*entity.Mention
+	for _, m := range mentions {
+		c := *m
+		c.ID, c.DocumentID = 0, docID
+		var ok bool
+		if c.ChunkID, ok = chunkIDs[m.ChunkID]; !ok {
+			continue
+		}
+		switch {
+		case m.NodeID != nil:
+			id, ok := nodeIDs[*m.NodeID]
+			if !ok {
+				continue
+			}
+			c.NodeID = &id
+		case m.EdgeID != nil:
+			id, ok := edgeIDs[*m.EdgeID]
+			if !ok {
+				continue
+			}
+			c.EdgeID = &id
+		}
+		copies = append(copies, &c)
+	}
+	return s.mentionRepo.ReplaceByDocument(ctx, docID, copies)
+}
+
+// scoreGraph computes analytics scores for a freshly saved document graph, then rescores the
+// notebook graph it joined. Failures only leave the scores stale, so they are logged and
+// ignored.
+func (s *ingestionService) scoreGraph(ctx context.Context, docID int64, nodes []*entity.Node, edges []*entity.Edge) {
+	if len(nodes) > 0 {
+		if err := s.graphRepo.UpdateNodeScores(ctx, computeNodeScores(docID, nodes, edges)); err != nil {
+			fmt.Printf("Warning: failed to compute graph analytics: %v\n", err)
+		}
+	}
+
+	doc, err := s.docRepo.GetByID(ctx, docID)
+	if err != nil || doc == nil || doc.NotebookID == nil {
+		return
+	}
+	if _, _, _, err := scoreNotebook(ctx, s.graphRepo, *doc.NotebookID); err != nil {
+		fmt.Printf("Warning: failed to compute notebook graph analytics: %v\n", err)
+	}
+}
+
+// embedNodes stores embeddings of a document's saved nodes for semantic entity search,
+// replacing those of a previous processing. Entity search is auxiliary, so failures are
+// logged and ignored.
+func (s *ingestionService) embedNodes(ctx context.Context, docID int64) {
+	if s.embeddingClient == nil || s.vectorRepo == nil {
+		return
+	}
+	nodes, err := s.graphRepo.GetNodesByDocumentID(ctx, docID)
+	if err != nil {
+		fmt.Printf("Warning: failed to embed nodes: %v\n", err)
+		return
+	}
+	if err := s.vectorRepo.DeleteByDocumentID(ctx, nodeCollection, docID); err != nil {
+		fmt.Printf("Warning: failed to delete node vectors: %v\n", err)
+		return
+	}
+	if len(nodes) == 0 {
+		return
+	}
+
+	if err := upsertNodeVectors(ctx, s.vectorRepo, s.embeddingClient, nodes); err != nil {
+		fmt.Printf("Warning: failed to embed nodes: %v\n", err)
+	}
+}
+
+// upsertNodeVectors embeds nodes and stores their vectors, replacing earlier ones.
+func upsertNodeVectors(ctx context.Context, vectorRepo repository.VectorRepository, embeddingClient embedding.Client, nodes []*entity.Node) error {
+	texts := make([]string, len(nodes))
+	for i, n := range nodes {
+		texts[i] = nodeText(n)
+	}
+	embeddings, err := embeddingClient.EmbedBatch(ctx, texts)
+	if err != nil {
+		return err
+	}
+	points := make([]*entity.VectorPoint, len(nodes))
+	for i, n := range nodes {
+		points[i] = nodePoint(n, embeddings[i])
+	}
+	_ = vectorRepo.CreateCollection(ctx, nodeCollection, embeddingDim) // Ignore error if exists
+	if err := vectorRepo.Upsert(ctx, nodeCollection, points); err != nil {
+		return fmt.Errorf("failed to store node vectors: %w", err)
+	}
+	return nil
+}
+
+// nodeText is the text embedded for a node: its name and label, and its description if any.
+func nodeText(n *entity.Node) string {
+	text := fmt.Sprintf("%s (%s)", n.Name, n.Label)
+	if description := nodeDescription(n); description != "" {
+		text += ": " + description
+	}
+	return text
+}
+
+// nodePoint builds the Qdrant point for a node.
+func nodePoint(n *entity.Node, vector []float32) *entity.VectorPoint {
+	return &entity.VectorPoint{
+		ID:     nodePointID(n.ID),
+		Vector: vector,
+		Payload: map[string]interface{}{
+			"document_id": n.DocumentID,
+			"node_id":     n.ID,
+			"name":        n.Name,
+			"label":       n.Label,
+		},
+	}
+}
+
+// nodePointID is the ID of a node's point. Qdrant only accepts UUID or unsigned point IDs, so
+// it is derived from the node ID.
+func nodePointID(id int64) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("graphweaver/node/%d", id))).String()
+}
+
+// linkEvidence records the chunks supporting a document's nodes and edges. Evidence is
+// auxiliary, so failures are logged and ignored.
+func (s *ingestionService) linkEvidence(ctx context.Context, docID int64, chunks []*entity.Chunk, quotes extractionQuotes) {
+	if s.mentionRepo == nil || len(chunks) == 0 {
+		return
+	}
+	// Load the saved graph: it includes curated nodes and edges that extraction resolved to
+	nodes, err := s.graphRepo.GetNodesByDocumentID(ctx, docID)
+	if err != nil {
+		fmt.Printf("Warning: failed to link evidence: %v\n", err)
+		return
+	}
+	edges, err := s.graphRepo.GetEdgesByDocumentID(ctx, docID)
+	if err != nil {
+		fmt.Printf("Warning: failed to link evidence: %v\n", err)
+		return
+	}
+	if err := s.mentionRepo.ReplaceByDocument(ctx, docID, findMentions(chunks, nodes, edges, quotes)); err != nil {
+		fmt.Printf("Warning: failed to link evidence: %v\n", err)
+	}
+}
+
+// placeholderFor turns the ID of a copied node into its placeholder and leaves
+// references to nodes outside the copied set untouched.
+func placeholderFor(copied map[int64]bool, id int64) int64 {
+	if copied[id] {
+		return -id
+	}
+	return id
+}
+
+// cloneChunks copies chunk rows and their vectors under new IDs, and returns the new ID of
+// each source chunk. Vectors missing from Qdrant are re-embedded when an embedding client is
+// available.
+func (s *ingestionService) cloneChunks(ctx context.Context, sourceDocID, docID int64) (map[string]string, error) {
+	chunks, err := s.chunkRepo.GetChunksByDocumentID(ctx, sourceDocID)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	newChunks := make([]*entity.Chunk, len(chunks))
+	sourceIDs := make([]string, len(chunks))
+	ids := make(map[string]string, len(chunks))
+	for i, c := range chunks {
+		sourceIDs[i] = c.ID
+		newChunks[i] = &entity.Chunk{
+			ID:         uuid.New().String(),
+			DocumentID: docID,
+			Index:      c.Index,
+			Content:    c.Content,
+			TokenCount: c.TokenCount,
+		}
+		ids[c.ID] = newChunks[i].ID
+	}
+
+	if err := s.chunkRepo.CreateChunks(ctx, newChunks); err != nil {
+		return nil, fmt.Errorf("chunk storage failed: %w", err)
+	}
+
+	if s.vectorRepo == nil {
+		return ids, nil
+	}
+
+	existing, err := s.vectorRepo.Get(ctx, chunkCollection, sourceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source vectors: %w", err)
+	}
+	vectors := make(map[string][]float32, len(existing))
+	for _, p := range existing {
+		vectors[p.ID] = p.Vector
+	}
+
+	var missing []int
+	points := make([]*entity.VectorPoint, 0, len(chunks))
+	for i, c := range newChunks {
+		vec, ok := vectors[sourceIDs[i]]
+		if !ok || len(vec) == 0 {
+			missing = append(missing, i)
+			continue
+		}
+		points = append(points, chunkPoint(c, vec))
+	}
+
+	if len(missing) > 0 && s.embeddingClient != nil {
+		texts := make([]string, len(missing))
+		for i, idx := range missing {
+			texts[i] = newChunks[idx].Content
+		}
+		embeddings, err := s.embeddingClient.EmbedBatch(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("embedding failed: %w", err)
+		}
+		for i, idx := range missing {
+			points = append(points, chunkPoint(newChunks[idx], embeddings[i]))
+		}
+	}
+
+	if len(points) == 0 {
+		return ids, nil
+	}
+	if err := s.vectorRepo.Upsert(ctx, chunkCollection, points); err != nil {
+		return nil, fmt.Errorf("vector upsert failed: %w", err)
+	}
+	return ids, nil
+}
+
+// chunkPoint builds the Qdrant point for a chunk.
+func chunkPoint(c *entity.Chunk, vector []float32) *entity.VectorPoint {
+	return &entity.VectorPoint{
+		ID:     c.ID,
+		Vector: vector,
+		Payload: map[string]interface{}{
+			"document_id": c.DocumentID,
+			"chunk_index": c.Index,
+			"content":     c.Content,
+		},
+	}
+}
+
+func (s *ingestionService) processDocumentAsync(docID int64, fileKey string) {
 	// Create a background context for the async job
 	ctx := context.Background()
 
@@ -115,7 +590,7 @@ func (s *ingestionService) processDocumentAsync(docID int64, filePath string) {
 	_ = s.docRepo.UpdateStatus(ctx, docID, "processing", nil)
 
 	// 1. Parse File
-	text, err := parser.ParseFile(filePath)
+	text, err := s.parseFile(ctx, fileKey)
 	if err != nil {
 		errMsg := fmt.Sprintf("parsing failed: %v", err)
 		_ = s.docRepo.UpdateStatus(ctx, docID, "failed", &errMsg)
@@ -123,11 +598,11 @@ func (s *ingestionService) processDocumentAsync(docID int64, filePath string) {
 	}
 
 	// 1.5. Chunking and Embedding
+	var chunkEntities []*entity.Chunk
 	if s.embeddingClient != nil && s.vectorRepo != nil && s.chunkRepo != nil {
 		_ = s.docRepo.UpdateStatus(ctx, docID, "embedding", nil)
 
 		chunks := splitText(text, 512)
-		var chunkEntities []*entity.Chunk
 		var points []*entity.VectorPoint
 
 		// Process in batches if needed, but for now linear
@@ -149,24 +624,28 @@ func (s *ingestionService) processDocumentAsync(docID int64, filePath string) {
 		for i, chunkText := range chunks {
 			chunkID := uuid.New().String()
 
-			chunkEntities = append(chunkEntities, &entity.Chunk{
+			chunk := &entity.Chunk{
 				ID:         chunkID,
 				DocumentID: docID,
 				Index:      i,
 				Content:    chunkText,
 				TokenCount: len(strings.Fields(chunkText)), // Approximately
 				Embedding:  embeddings[i],
-			})
-
-			points = append(points, &entity.VectorPoint{
-				ID:     chunkID,
-				Vector: embeddings[i],
-				Payload: map[string]interface{}{
-					"document_id": docID,
-					"chunk_index": i,
-					"content":     chunkText,
-				},
-			})
+			}
+			chunkEntities = append(chunkEntities, chunk)
+			points = append(points, chunkPoint(chunk, embeddings[i]))
+		}
+
+		// Replace the chunks of a previous processing; their evidence goes with them
+		if err := s.vectorRepo.DeleteByDocumentID(ctx, chunkCollection, docID); err != nil {
+			errMsg := fmt.Sprintf("failed to delete previous vectors: %v", err)
+			_ = s.docRepo.UpdateStatus(ctx, docID, "failed", &errMsg)
+			return
+		}
+		if err := s.chunkRepo.DeleteByDocumentID(ctx, docID); err != nil {
+			errMsg := fmt.Sprintf("failed to delete previous chunks: %v", err)
+			_ = s.docRepo.UpdateStatus(ctx, docID, "failed", &errMsg)
+			return
 		}
 
 		// Save Chunks to Postgres
@@ -183,10 +662,9 @@ func (s *ingestionService) processDocumentAsync(docID int64, filePath string) {
 		// Assuming single collection "documents" or per notebook?
 		// Proposal says: "Scenario: Vector collection initialization ... WHEN a new notebook is created"
 		// If using single collection for now:
-		collectionName := "documents"
-		_ = s.vectorRepo.CreateCollection(ctx, collectionName, 768) // Ignore error if exists
+		_ = s.vectorRepo.CreateCollection(ctx, chunkCollection, embeddingDim) // Ignore error if exists
 
-		if err := s.vectorRepo.Upsert(ctx, collectionName, points); err != nil {
+		if err := s.vectorRepo.Upsert(ctx, chunkCollection, points); err != nil {
 			fmt.Printf("Error upserting vectors: %v\n", err)
 			errMsg := fmt.Sprintf("vector upsert failed: %v", err)
 			_ = s.docRepo.UpdateStatus(ctx, docID, "failed", &errMsg)
@@ -201,12 +679,15 @@ Return ONLY a valid JSON object with the following structure:
 {
   "summary": "A brief summary of the text (max 50 words)",
   "entities": [
-    {"name": "Entity Name", "label": "Person/Location/Organization/Concept", "description": "Brief description"}
+    {"name": "Entity Name", "label": "Person/Location/Organization/Concept", "description": "Brief description", "attributes": {"role": "CEO", "founded": "1998"}, "quote": "Sentence from the text mentioning the entity"}
   ],
   "relations": [
-    {"source": "Entity Name", "target": "Entity Name", "type": "RELATION_TYPE", "description": "Context of relation"}
+    {"source": "Entity Name", "target": "Entity Name", "type": "RELATION_TYPE", "description": "Context of relation", "attributes": {"amount": 250000}, "valid_from": "2019-03", "valid_to": null, "quote": "Sentence from the text stating the relation"}
   ]
 }
+"attributes" is optional: include facts stated in the text such as dates, roles or quantities, using short snake_case keys. Use numbers for quantities.
+"valid_from" and "valid_to" are optional: give them only when the text states when a relation began or ended, as YYYY, YYYY-MM or YYYY-MM-DD.
+"quote" must be copied verbatim from the text.
 
 Text to analyze:
 %s
@@ -219,12 +700,15 @@ Return ONLY a valid JSON object with the following structure:
 {
   "summary": "A brief summary of the text (max 50 words)",
   "entities": [
-    {"name": "Entity Name", "label": "Person/Location/Organization/Concept", "description": "Brief description"}
+    {"name": "Entity Name", "label": "Person/Location/Organization/Concept", "description": "Brief description", "attributes": {"role": "CEO", "founded": "1998"}, "quote": "Sentence from the text mentioning the entity"}
   ],
   "relations": [
-    {"source": "Entity Name", "target": "Entity Name", "type": "RELATION_TYPE", "description": "Context of relation"}
+    {"source": "Entity Name", "target": "Entity Name", "type": "RELATION_TYPE", "description": "Context of relation", "attributes": {"amount": 250000}, "valid_from": "2019-03", "valid_to": null, "quote": "Sentence from the text stating the relation"}
   ]
 }
+"attributes" is optional: include facts stated in the text such as dates, roles or quantities, using short snake_case keys. Use numbers for quantities.
+"valid_from" and "valid_to" are optional: give them only when the text states when a relation began or ended, as YYYY, YYYY-MM or YYYY-MM-DD.
+"quote" must be copied verbatim from the text.
 
 Text to analyze:
 %s... (truncated)
@@ -245,30 +729,27 @@ Text to analyze:
 		return
 	}
 
-	// Clean up response (remove markdown code blocks if present)
-	jsonStr := strings.TrimSpace(response)
-	if strings.HasPrefix(jsonStr, "```json") {
-		jsonStr = strings.TrimPrefix(jsonStr, "```json")
-		jsonStr = strings.TrimSuffix(jsonStr, "```")
-	} else if strings.HasPrefix(jsonStr, "```") {
-		jsonStr = strings.TrimPrefix(jsonStr, "```")
-		jsonStr = strings.TrimSuffix(jsonStr, "```")
-	}
-	jsonStr = strings.TrimSpace(jsonStr)
+	jsonStr := cleanJSONResponse(response)
 
 	// Parse JSON
 	var result struct {
 		Summary  string `json:"summary"`
 		Entities []struct {
-			Name  string `json:"name"`
-			Label string `json:"label"`
-			Desc  string `json:"description"`
+			Name       string         `json:"name"`
+			Label      string         `json:"label"`
+			Desc       string         `json:"description"`
+			Attributes map[string]any `json:"attributes"`
+			Quote      string         `json:"quote"`
 		} `json:"entities"`
 		Relations []struct {
-			Source string `json:"source"`
-			Target string `json:"target"`
-			Type   string `json:"type"`
-			Desc   string `json:"description"`
+			Source     string          `json:"source"`
+			Target     string          `json:"target"`
+			Type       string          `json:"type"`
+			Desc       string          `json:"description"`
+			Attributes map[string]any  `json:"attributes"`
+			ValidFrom  json.RawMessage `json:"valid_from"`
+			ValidTo    json.RawMessage `json:"valid_to"`
+			Quote      string          `json:"quote"`
 		} `json:"relations"`
 	}
 
@@ -287,32 +768,35 @@ Text to analyze:
 		return
 	}
 
-	// 4. Save Graph Data
-	// Save Entities (Nodes)
-	nodeMap := make(map[string]int64) // Name -> ID
+	// 4. Save Graph Data in a single transaction
+	// Nodes get negative placeholder IDs that SaveGraph replaces with real ones
+	var nodes []*entity.Node
+	nodeMap := make(map[string]int64) // Name -> placeholder ID
+	seen := make(map[[2]string]int64) // (Name, Label) -> placeholder ID
+	quotes := extractionQuotes{nodes: make(map[[2]string]string), edges: make(map[[3]string]string)}
 	for _, e := range result.Entities {
-		// Check if node already exists for this document (or globally? For now, per document scope or simple dedupe)
-		// We'll use FindNode to check within this document context
-		existingNode, _ := s.graphRepo.FindNode(ctx, docID, e.Name, e.Label)
-		if existingNode != nil {
-			nodeMap[e.Name] = existingNode.ID
+		if e.Quote != "" && quotes.nodes[[2]string{e.Name, e.Label}] == "" {
+			quotes.nodes[[2]string{e.Name, e.Label}] = e.Quote
+		}
+		// Deduplicate entities the LLM returned more than once
+		if id, ok := seen[[2]string{e.Name, e.Label}]; ok {
+			nodeMap[e.Name] = id
 			continue
 		}
 
 		node := &entity.Node{
+			ID:         -int64(len(nodes) + 1),
 			DocumentID: docID,
 			Label:      e.Label,
 			Name:       e.Name,
-			Properties: fmt.Sprintf(`{"description": "%s"}`, strings.ReplaceAll(e.Desc, "\"", "\\\"")),
-		}
-		if err := s.graphRepo.CreateNode(ctx, node); err != nil {
-			fmt.Printf("Error creating node %s: %v\n", e.Name, err)
-			continue
+			Properties: extractedProperties(e.Desc, e.Attributes),
 		}
+		nodes = append(nodes, node)
+		seen[[2]string{e.Name, e.Label}] = node.ID
 		nodeMap[e.Name] = node.ID
 	}
 
-	// Save Relations (Edges)
+	var edges []*entity.Edge
 	for _, r := range result.Relations {
 		sourceID, ok1 := nodeMap[r.Source]
 		targetID, ok2 := nodeMap[r.Target]
@@ -321,23 +805,141 @@ Text to analyze:
 			// Skip if nodes not found (maybe LLM hallucinated a relation with a non-extracted entity)
 			continue
 		}
+		if r.Quote != "" {
+			quotes.edges[[3]string{r.Source, r.Type, r.Target}] = r.Quote
+		}
 
-		edge := &entity.Edge{
+		validFrom, validTo := extractedValidity(r.ValidFrom, r.ValidTo)
+		edges = append(edges, &entity.Edge{
 			DocumentID:   docID,
 			SourceNodeID: sourceID,
 			TargetNodeID: targetID,
 			RelationType: r.Type,
-			Properties:   fmt.Sprintf(`{"description": "%s"}`, strings.ReplaceAll(r.Desc, "\"", "\\\"")),
-		}
-		if err := s.graphRepo.CreateEdge(ctx, edge); err != nil {
-			fmt.Printf("Error creating edge %s->%s: %v\n", r.Source, r.Target, err)
-		}
+			Properties:   extractedProperties(r.Desc, r.Attributes),
+			ValidFrom:    validFrom,
+			ValidTo:      validTo,
+		})
+	}
+
+	nodes, edges, err = s.applyCorrections(ctx, docID, nodes, edges)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to apply graph corrections: %v", err)
+		_ = s.docRepo.UpdateStatus(ctx, docID, "failed", &errMsg)
+		return
+	}
+	if err := s.graphRepo.SaveGraph(ctx, docID, nodes, edges); err != nil {
+		errMsg := fmt.Sprintf("failed to save graph: %v", err)
+		_ = s.docRepo.UpdateStatus(ctx, docID, "failed", &errMsg)
+		return
 	}
+	s.scoreGraph(ctx, docID, nodes, edges)
+	s.embedNodes(ctx, docID)
+	s.linkEvidence(ctx, docID, chunkEntities, quotes)
 
 	// 5. Mark as Completed
 	_ = s.docRepo.UpdateStatus(ctx, docID, "completed", nil)
 }
 
+// applyCorrections keeps the manual edits of a document that is processed again from being
+// overwritten by the new extraction.
+func (s *ingestionService) applyCorrections(ctx context.Context, docID int64, nodes []*entity.Node, edges []*entity.Edge) ([]*entity.Node, []*entity.Edge, error) {
+	edits, err := s.editRepo.ListByDocument(ctx, docID)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := s.graphRepo.GetNodesByDocumentID(ctx, docID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(edits) == 0 && len(current) == 0 {
+		return nodes, edges, nil
+	}
+
+	known := make(map[int64]bool, len(current))
+	for _, n := range current {
+		known[n.ID] = true
+	}
+	exists := func(id int64) bool {
+		if ok, seen := known[id]; seen {
+			return ok
+		}
+		// Merges may point at nodes of other documents
+		n, err := s.graphRepo.GetNode(ctx, id)
+		known[id] = err == nil && n != nil
+		return known[id]
+	}
+	nodes, edges = correctExtraction(edits, current, nodes, edges, exists)
+	return nodes, edges, nil
+}
+
+// extractedProperties combines the description and attributes the LLM returned for an entity
+// or relation. Attributes cannot override the description.
+func extractedProperties(desc string, attrs map[string]any) entity.Properties {
+	props := make(entity.Properties, len(attrs)+1)
+	for k, v := range attrs {
+		if k = strings.TrimSpace(k); k != "" && v != nil {
+			props[k] = v
+		}
+	}
+	props["description"] = desc
+	return props
+}
+
+// extractedValidity converts the validity interval the LLM returned for a relation. A partial
+// date covers its whole period, so valid_from takes its first day and valid_to its last.
+// Unparsable or inverted dates are dropped, keeping the relation.
+func extractedValidity(from, to json.RawMessage) (*entity.Date, *entity.Date) {
+	var validFrom, validTo *entity.Date
+	if start, _, err := entity.ParseDatePeriod(extractedDate(from)); err == nil {
+		validFrom = &start
+	}
+	if _, end, err := entity.ParseDatePeriod(extractedDate(to)); err == nil {
+		validTo = &end
+	}
+	if validFrom != nil && validTo != nil && validTo.Before(validFrom.Time) {
+		return nil, nil
+	}
+	return validFrom, validTo
+}
+
+// extractedDate reads a date the LLM returned as a string or as a bare year such as 1998.
+// Anything else reads as no date.
+func extractedDate(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var year float64
+	if err := json.Unmarshal(raw, &year); err == nil && year == math.Trunc(year) {
+		return strconv.Itoa(int(year))
+	}
+	return ""
+}
+
+// cleanJSONResponse removes the markdown code fences an LLM may wrap JSON output in.
+func cleanJSONResponse(response string) string {
+	jsonStr := strings.TrimSpace(response)
+	if strings.HasPrefix(jsonStr, "```json") {
+		jsonStr = strings.TrimPrefix(jsonStr, "```json")
+		jsonStr = strings.TrimSuffix(jsonStr, "```")
+	} else if strings.HasPrefix(jsonStr, "```") {
+		jsonStr = strings.TrimPrefix(jsonStr, "```")
+		jsonStr = strings.TrimSuffix(jsonStr, "```")
+	}
+	return strings.TrimSpace(jsonStr)
+}
+
+// parseFile reads a stored file and extracts its text.
+func (s *ingestionService) parseFile(ctx context.Context, fileKey string) (string, error) {
+	r, err := s.blobStore.Get(ctx, fileKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer r.Close()
+
+	return parser.Parse(r, fileKey)
+}
+
 func splitText(text string, maxTokens int) []string {
 	words := strings.Fields(text)
 	var chunks []string
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/pkg/storage"
+)
+
+func TestExtractedValidity(t *testing.T) {
+	tests := []struct {
+		from, to string
+		wantFrom string
+		wantTo   string
+	}{
+		{`"2019-03"`, `null`, "2019-03-01", ""},
+		{`1998`, `"2001"`, "1998-01-01", "2001-12-31"},
+		{`1998.5`, `{"year": 2001}`, "", ""},
+		{`"sometime"`, `"2001-02-03"`, "", "2001-02-03"},
+		{`"2005"`, `"2001"`, "", ""}, // inverted
+		{``, ``, "", ""},
+	}
+	for _, tt := range tests {
+		from, to := extractedValidity(json.RawMessage(tt.from), json.RawMessage(tt.to))
+		gotFrom, gotTo := "", ""
+		if from != nil {
+			gotFrom = from.String()
+		}
+		if to != nil {
+			gotTo = to.String()
+		}
+		if gotFrom != tt.wantFrom || gotTo != tt.wantTo {
+			t.Errorf("extractedValidity(%s, %s) = %q, %q; want %q, %q", tt.from, tt.to, gotFrom, gotTo, tt.wantFrom, tt.wantTo)
+		}
+	}
+}
+
+type reprocessDocs struct {
+	repository.DocumentRepository
+	doc  *entity.Document
+	done chan string
+}
+
+func (r *reprocessDocs) GetByID(ctx context.Context, id int64) (*entity.Document, error) {
+	return r.doc, nil
+}
+
+func (r *reprocessDocs) UpdateStatus(ctx context.Context, id int64, status string, errorMessage *string) error {
+	if status == "completed" || status == "failed" {
+		if errorMessage != nil {
+			status += ": " + *errorMessage
+		}
+		r.done <- status
+	}
+	return nil
+}
+
+func (r *reprocessDocs) UpdateSummary(ctx context.Context, id int64, summary string) error {
+	return nil
+}
+
+type curatedGraph struct {
+	repository.GraphRepository
+	curated    *entity.Node
+	savedNodes []*entity.Node
+	savedEdges []*entity.Edge
+}
+
+func (g *curatedGraph) GetNodesByDocumentID(ctx context.Context, docID int64) ([]*entity.Node, error) {
+	return []*entity.Node{g.curated}, nil
+}
+
+func (g *curatedGraph) GetNode(ctx context.Context, id int64) (*entity.Node, error) {
+	if id == g.curated.ID {
+		return g.curated, nil
+	}
+	return nil, nil
+}
+
+func (g *curatedGraph) SaveGraph(ctx context.Context, docID int64, nodes []*entity.Node, edges []*entity.Edge) error {
+	g.savedNodes, g.savedEdges = nodes, edges
+	return nil
+}
+
+func (g *curatedGraph) UpdateNodeScores(ctx context.Context, scores []*entity.NodeScore) error {
+	return nil
+}
+
+type documentEdits struct {
+	repository.GraphEditRepository
+	edits []*entity.GraphEdit
+}
+
+func (r *documentEdits) ListByDocument(ctx context.Context, docID int64) ([]*entity.GraphEdit, error) {
+	return r.edits, nil
+}
+
+func TestReprocessKeepsCorrections(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "Alice works at Acme."
+	if err := store.Put(ctx, "doc.txt", strings.NewReader(text), int64(len(text)), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A curator renamed the extracted "Alice" to "Alice Smith"
+	curated := &entity.Node{ID: 7, DocumentID: 1, Label: "Person", Name: "Alice Smith", Source: entity.SourceHuman}
+	docs := &reprocessDocs{
+		doc:  &entity.Document{ID: 1, FilePath: "doc.txt", MimeType: "text/plain", Status: "completed"},
+		done: make(chan string, 1),
+	}
+	graph := &curatedGraph{curated: curated}
+	edits := &documentEdits{edits: []*entity.GraphEdit{{
+		DocumentID: 1,
+		EntityType: EditEntityNode,
+		EntityID:   7,
+		Action:     EditUpdate,
+		Before:     snapshot(&entity.Node{ID: 7, Label: "Person", Name: "Alice"}),
+		After:      snapshot(curated),
+	}}}
+	client := &queuedLLM{responses: []string{`{
+		"summary": "Alice works at Acme.",
+		"entities": [{"name": "Alice", "label": "Person"}, {"name": "Acme", "label": "Organization"}],
+		"relations": [{"source": "Alice", "target": "Acme", "type": "WORKS_AT", "valid_from": 2019}]
+	}`}}
+	svc := NewIngestionService(docs, nil, graph, edits, nil, nil, nil, client, nil, store)
+
+	if _, err := svc.Reprocess(ctx, 1); err != nil {
+		t.Fatalf("Reprocess: %v", err)
+	}
+	if status := <-docs.done; status != "completed" {
+		t.Fatalf("reprocessing ended with %q", status)
+	}
+
+	if len(graph.savedNodes) != 1 || graph.savedNodes[0].Name != "Acme" {
+		t.Errorf("expected only Acme to be saved as a new node, got %v", graph.savedNodes)
+	}
+	if len(graph.savedEdges) != 1 || graph.savedEdges[0].SourceNodeID != curated.ID {
+		t.Fatalf("expected the relation to start at the curated node, got %v", graph.savedEdges)
+	}
+	if from := graph.savedEdges[0].ValidFrom; from == nil || from.String() != "2019-01-01" {
+		t.Errorf("expected the relation valid from 2019-01-01, got %v", from)
+	}
+
+	docs.doc.Status = "processing"
+	if _, err := svc.Reprocess(ctx, 1); !errors.Is(err, ErrNotReprocessable) {
+		t.Errorf("expected a document being processed to be rejected, got %v", err)
+	}
+}
+
+type uploadNotebooks struct {
+	repository.NotebookRepository
+}
+
+func (uploadNotebooks) GetByID(ctx context.Context, id int64) (*entity.Notebook, error) {
+	if id != 1 {
+		return nil, sql.ErrNoRows
+	}
+	return &entity.Notebook{ID: 1}, nil
+}
+
+// fullNotebookDocs holds one document in notebook 1 and references no stored file.
+type fullNotebookDocs struct {
+	repository.DocumentRepository
+}
+
+func (fullNotebookDocs) FindByContentHash(ctx context.Context, hash string, notebookID *int64) (*entity.Document, error) {
+	return nil, nil
+}
+
+func (fullNotebookDocs) ListAllByNotebook(ctx context.Context, notebookID int64) ([]*entity.Document, error) {
+	return []*entity.Document{{ID: 1, MimeType: "text/plain"}}, nil
+}
+
+func (fullNotebookDocs) CountByFilePath(ctx context.Context, filePath string) (int, error) {
+	return 0, nil
+}
+
+type uploadFile struct{ *strings.Reader }
+
+func (uploadFile) Close() error { return nil }
+
+func TestRejectedUploadDiscardsFile(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewIngestionService(fullNotebookDocs{}, uploadNotebooks{}, nil, nil, nil, nil, nil, nil, nil, store)
+	upload := func(notebookID int64) error {
+		text := "Alice works at Acme."
+		_, _, err := svc.ProcessUpload(ctx, uploadFile{strings.NewReader(text)}, &multipart.FileHeader{Filename: "b.txt", Size: int64(len(text))}, &notebookID)
+		return err
+	}
+
+	if err := upload(2); !errors.Is(err, ErrNotebookNotFound) {
+		t.Errorf("upload to a missing notebook: got %v, want ErrNotebookNotFound", err)
+	}
+	if err := upload(1); err == nil {
+		t.Error("upload to a notebook with a document was accepted")
+	}
+	files, err := store.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("rejected uploads left files behind: %v", files[0].Key)
+	}
+}
+
+// notebookDocuments holds documents of notebook 1.
+type notebookDocuments struct {
+	repository.DocumentRepository
+	docs map[int64]*entity.Document
+}
+
+func (r *notebookDocuments) GetByID(ctx context.Context, id int64) (*entity.Document, error) {
+	return r.docs[id], nil
+}
+
+func (r *notebookDocuments) UpdateStatus(ctx context.Context, id int64, status string, errorMessage *string) error {
+	r.docs[id].Status = status
+	return nil
+}
+
+func (r *notebookDocuments) UpdateSummary(ctx context.Context, id int64, summary string) error {
+	return nil
+}
+
+// memoryGraph keeps the graphs of notebook 1 in memory.
+type memoryGraph struct {
+	repository.GraphRepository
+	nodes          []*entity.Node
+	edges          []*entity.Edge
+	notebookScores []*entity.NodeScore
+}
+
+func (g *memoryGraph) GetNodesByDocumentID(ctx context.Context, docID int64) ([]*entity.Node, error) {
+	var nodes []*entity.Node
+	for _, n := range g.nodes {
+		if n.DocumentID == docID {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes, nil
+}
+
+func (g *memoryGraph) SaveGraph(ctx context.Context, docID int64, nodes []*entity.Node, edges []*entity.Edge) error {
+	ids := make(map[int64]int64, len(nodes))
+	for _, n := range nodes {
+		ids[n.ID] = int64(len(g.nodes) + 1)
+		n.ID = ids[n.ID]
+		g.nodes = append(g.nodes, n)
+	}
+	for _, e := range edges {
+		e.SourceNodeID, e.TargetNodeID = ids[e.SourceNodeID], ids[e.TargetNodeID]
+		g.edges = append(g.edges, e)
+	}
+	return nil
+}
+
+func (g *memoryGraph) UpdateNodeScores(ctx context.Context, scores []*entity.NodeScore) error {
+	applyNodeScores(g.nodes, scores)
+	return nil
+}
+
+func (g *memoryGraph) GetNodesByNotebookID(ctx context.Context, notebookID int64) ([]*entity.Node, error) {
+	nodes := make([]*entity.Node, len(g.nodes))
+	for i, n := range g.nodes {
+		c := *n
+		nodes[i] = &c
+	}
+	return nodes, nil
+}
+
+func (g *memoryGraph) GetEdgesByNotebookID(ctx context.Context, notebookID int64) ([]*entity.Edge, error) {
+	return g.edges, nil
+}
+
+func (g *memoryGraph) ReplaceNotebookNodeScores(ctx context.Context, notebookID int64, scores []*entity.NodeScore) error {
+	g.notebookScores = scores
+	return nil
+}
+
+func (g *memoryGraph) GetNotebookNodeScores(ctx context.Context, notebookID int64) ([]*entity.NodeScore, error) {
+	return g.notebookScores, nil
+}
+
+func TestIngestScoresNotebookGraph(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	notebookID := int64(1)
+	docs := &notebookDocuments{docs: map[int64]*entity.Document{}}
+	for id, text := range map[int64]string{1: "Bob cites Carol.", 2: "Eight leaves cite the hub."} {
+		key := fmt.Sprintf("doc%d.txt", id)
+		if err := store.Put(ctx, key, strings.NewReader(text), int64(len(text)), "text/plain"); err != nil {
+			t.Fatal(err)
+		}
+		docs.docs[id] = &entity.Document{ID: id, FilePath: key, MimeType: "text/plain", NotebookID: &notebookID}
+	}
+
+	// Document 1 is a pair, document 2 a star whose hub is the notebook's key entity.
+	// Within their documents, the cited one of the pair scores a higher PageRank than the hub.
+	star := `{"summary": "A star.", "entities": [{"name": "Hub", "label": "Concept"}`
+	relations := ""
+	for i := 1; i <= 8; i++ {
+		star += fmt.Sprintf(`, {"name": "Leaf %d", "label": "Concept"}`, i)
+		if i > 1 {
+			relations += ", "
+		}
+		relations += fmt.Sprintf(`{"source": "Leaf %d", "target": "Hub", "type": "CITES"}`, i)
+	}
+	star += `], "relations": [` + relations + `]}`
+	client := &queuedLLM{responses: []string{
+		`{"summary": "A pair.", "entities": [{"name": "Bob", "label": "Person"}, {"name": "Carol", "label": "Person"}],
+		  "relations": [{"source": "Bob", "target": "Carol", "type": "CITES"}]}`,
+		star,
+	}}
+	graph := &memoryGraph{}
+	svc := &ingestionService{docRepo: docs, graphRepo: graph, editRepo: &documentEdits{}, llmClient: client, blobStore: store}
+
+	svc.processDocumentAsync(1, "doc1.txt")
+	svc.processDocumentAsync(2, "doc2.txt")
+	for id, d := range docs.docs {
+		if d.Status != "completed" {
+			t.Fatalf("document %d ended %q", id, d.Status)
+		}
+	}
+
+	ranked, err := NewGraphService(graph, docs, uploadNotebooks{}).GetNotebookKeyEntities(ctx, notebookID, 3)
+	if err != nil {
+		t.Fatalf("GetNotebookKeyEntities: %v", err)
+	}
+	if len(ranked) == 0 || ranked[0].Name != "Hub" {
+		t.Fatalf("expected Hub to rank first in the notebook, got %v (%v)", ranked[0].Name, ranked[0].PageRank)
+	}
+
+	// Per-document scores stay on the nodes, with community IDs numbered per document
+	var carol, hub *entity.Node
+	for _, n := range graph.nodes {
+		switch n.Name {
+		case "Carol":
+			carol = n
+		case "Hub":
+			hub = n
+		}
+	}
+	if carol.PageRank <= hub.PageRank {
+		t.Errorf("document PageRank of Carol %v, of Hub %v: expected Carol higher within the pair", carol.PageRank, hub.PageRank)
+	}
+	if carol.CommunityID == nil || hub.CommunityID == nil || *carol.CommunityID == *hub.CommunityID {
+		t.Errorf("expected distinct community IDs across documents, got %v and %v", carol.CommunityID, hub.CommunityID)
+	}
+}
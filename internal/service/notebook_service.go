@@ -9,11 +9,13 @@ import (
 )
 
 type NotebookService struct {
-	repo repository.NotebookRepository
+	repo    repository.NotebookRepository
+	docRepo repository.DocumentRepository
+	cleanup CleanupService
 }
 
-func NewNotebookService(repo repository.NotebookRepository) *NotebookService {
-	return &NotebookService{repo: repo}
+func NewNotebookService(repo repository.NotebookRepository, docRepo repository.DocumentRepository, cleanup CleanupService) *NotebookService {
+	return &NotebookService{repo: repo, docRepo: docRepo, cleanup: cleanup}
 }
 
 func (s *NotebookService) CreateNotebook(ctx context.Context, title, description string) (*entity.Notebook, error) {
@@ -36,6 +38,15 @@ func (s *NotebookService) GetNotebook(ctx context.Context, id int64) (*entity.No
 }
 
 func (s *NotebookService) DeleteNotebook(ctx context.Context, id int64) error {
-	// In the future, this will also trigger deletions in Vector DB and Neo4j
+	// Purge documents first so their vectors and files go too; Postgres rows alone would cascade
+	docs, err := s.docRepo.ListAllByNotebook(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to list notebook documents: %w", err)
+	}
+	for _, doc := range docs {
+		if err := s.cleanup.PurgeDocument(ctx, doc); err != nil {
+			return fmt.Errorf("failed to delete document %d: %w", doc.ID, err)
+		}
+	}
 	return s.repo.Delete(ctx, id)
 }
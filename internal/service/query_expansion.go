@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Query expansion modes of local chat. HyDE embeds a hypothetical answer to the question,
+// which lies closer to the passages answering it than a short question does. Multi-query
+// retrieves for paraphrases of the question too and fuses the results.
+const (
+	ExpansionNone       = "none"
+	ExpansionHyDE       = "hyde"
+	ExpansionMultiQuery = "multi_query"
+)
+
+const (
+	defaultSubQueries = 3
+	// MaxSubQueries bounds the paraphrases generated in multi-query expansion.
+	MaxSubQueries = 5
+)
+
+// expandedRetrieve retrieves chunks for a question with the request's query expansion and
+// returns the generated queries alongside. When expansion fails, it retrieves for the
+// question alone.
+func (s *chatService) expandedRetrieve(ctx context.Context, notebookID int64, req ChatRequest, q RetrievalQuery) ([]*RetrievedChunk, []string, error) {
+	switch req.Expansion {
+	case ExpansionHyDE:
+		passage, err := s.hypotheticalAnswer(ctx, q.Query)
+		if err != nil {
+			fmt.Printf("Warning: HyDE expansion failed, retrieving for the question: %v\n", err)
+			break
+		}
+		q.VectorQuery = passage
+		chunks, err := s.retrieval.Retrieve(ctx, notebookID, q)
+		return chunks, []string{passage}, err
+
+	case ExpansionMultiQuery:
+		n := req.SubQueries
+		if n <= 0 {
+			n = defaultSubQueries
+		}
+		queries, err := s.subQueries(ctx, q.Query, min(n, MaxSubQueries))
+		if err != nil {
+			fmt.Printf("Warning: multi-query expansion failed, retrieving for the question: %v\n", err)
+			break
+		}
+		chunks, err := s.multiQueryRetrieve(ctx, notebookID, q, queries)
+		return chunks, queries, err
+	}
+
+	chunks, err := s.retrieval.Retrieve(ctx, notebookID, q)
+	return chunks, nil, err
+}
+
+// multiQueryRetrieve retrieves for the question and each sub-query and fuses the result
+// lists by reciprocal rank, so chunks found for several queries rank first. Queries whose
+// retrieval fails are skipped.
+func (s *chatService) multiQueryRetrieve(ctx context.Context, notebookID int64, q RetrievalQuery, queries []string) ([]*RetrievedChunk, error) {
+	chunks := make(map[string]*RetrievedChunk)
+	var rankings []ranking
+	var firstErr error
+	for _, query := range append([]string{q.Query}, queries...) {
+		sub := q
+		sub.Query = query
+		results, err := s.retrieval.Retrieve(ctx, notebookID, sub)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		r := ranking{weight: 1}
+		for _, c := range results {
+			if _, ok := chunks[c.ID]; !ok {
+				chunks[c.ID] = c
+			}
+			r.ids = append(r.ids, c.ID)
+		}
+		rankings = append(rankings, r)
+	}
+	if len(rankings) == 0 {
+		return nil, firstErr
+	}
+
+	topK := q.TopK
+	if topK <= 0 {
+		topK = defaultRetrievalTopK
+	}
+	fused := reciprocalRankFusion(rankings)
+	results := make([]*RetrievedChunk, 0, min(len(fused), topK))
+	for _, f := range fused[:min(len(fused), topK)] {
+		c := chunks[f.id]
+		c.Score = f.score
+		results = append(results, c)
+	}
+	return results, nil
+}
+
+// hypotheticalAnswer asks the LLM for a passage answering the question, to be embedded in
+// its place (HyDE). The passage may be wrong; it only has to read like the right passages.
+func (s *chatService) hypotheticalAnswer(ctx context.Context, question string) (string, error) {
+	prompt := fmt.Sprintf(`Write a short passage of 3 to 5 sentences that answers the following question, written as it might appear in a document.
+Include the specific names, terms and facts such a passage would contain. If you are unsure of the facts, write a plausible passage anyway.
+Return only the passage.
+
+Question: %s`, question)
+
+	passage, err := s.llmClient.GenerateContent(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	passage = strings.TrimSpace(passage)
+	if passage == "" {
+		return "", fmt.Errorf("empty hypothetical answer")
+	}
+	return passage, nil
+}
+
+// subQueries asks the LLM for n search queries rephrasing the question or covering its parts.
+func (s *chatService) subQueries(ctx context.Context, question string, n int) ([]string, error) {
+	prompt := fmt.Sprintf(`Write %d different search queries for finding the passages that answer the following question.
+Rephrase the question with other words and synonyms, and give each part of a question with several parts its own query.
+Return ONLY a valid JSON object with the following structure:
+{"queries": ["search query"]}
+
+Question: %s`, n, question)
+
+	response, err := s.llmClient.GenerateContent(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Queries []string `json:"queries"`
+	}
+	if err := json.Unmarshal([]byte(cleanJSONResponse(response)), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse sub-queries: %w", err)
+	}
+
+	var queries []string
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(question)): true}
+	for _, q := range result.Queries {
+		q = strings.TrimSpace(q)
+		if q == "" || seen[strings.ToLower(q)] {
+			continue
+		}
+		seen[strings.ToLower(q)] = true
+		queries = append(queries, q)
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no sub-queries generated")
+	}
+	return queries[:min(len(queries), n)], nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+)
+
+// scriptedRetrieval returns the chunks listed for each query and records the queries.
+type scriptedRetrieval struct {
+	RetrievalService
+	results map[string][]string // query -> chunk IDs; a missing query fails
+	queries []RetrievalQuery
+}
+
+func (r *scriptedRetrieval) Retrieve(ctx context.Context, notebookID int64, q RetrievalQuery) ([]*RetrievedChunk, error) {
+	r.queries = append(r.queries, q)
+	ids, ok := r.results[q.Query]
+	if !ok {
+		return nil, errors.New("retrieval failed")
+	}
+	chunks := make([]*RetrievedChunk, len(ids))
+	for i, id := range ids {
+		chunks[i] = &RetrievedChunk{Chunk: &entity.Chunk{ID: id}}
+	}
+	return chunks, nil
+}
+
+func chunkIDs(chunks []*RetrievedChunk) []string {
+	ids := []string{}
+	for _, c := range chunks {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func TestSubQueries(t *testing.T) {
+	client := &queuedLLM{responses: []string{
+		"```json\n" + `{"queries": ["Who is Ada?", " Ada Lovelace biography ", "", "ada lovelace BIOGRAPHY", "Ada's work", "Ada and Babbage"]}` + "\n```",
+		"not json",
+		`{"queries": ["who is ada?"]}`,
+	}}
+	svc := &chatService{llmClient: client}
+
+	queries, err := svc.subQueries(context.Background(), "Who is Ada?", 2)
+	if err != nil {
+		t.Fatalf("subQueries: %v", err)
+	}
+	// The question itself, blanks and repeats are skipped
+	if want := []string{"Ada Lovelace biography", "Ada's work"}; !slices.Equal(queries, want) {
+		t.Errorf("sub-queries = %q, want %q", queries, want)
+	}
+	if !strings.Contains(client.prompts[0], "Write 2 different search queries") {
+		t.Errorf("prompt does not ask for 2 queries: %s", client.prompts[0])
+	}
+
+	if _, err := svc.subQueries(context.Background(), "Who is Ada?", 2); err == nil {
+		t.Error("expected an error for a response that is not JSON")
+	}
+	if _, err := svc.subQueries(context.Background(), "Who is Ada?", 2); err == nil {
+		t.Error("expected an error when only the question comes back")
+	}
+}
+
+func TestExpandedRetrieveClampsSubQueries(t *testing.T) {
+	for _, tc := range []struct {
+		requested int
+		want      string
+	}{
+		{0, "Write 3 different"},
+		{2, "Write 2 different"},
+		{50, "Write 5 different"},
+	} {
+		client := &queuedLLM{responses: []string{`{"queries": ["a"]}`}}
+		retrieval := &scriptedRetrieval{results: map[string][]string{"q": {"c1"}, "a": {"c2"}}}
+		svc := &chatService{llmClient: client, retrieval: retrieval}
+
+		req := ChatRequest{Query: "q", Expansion: ExpansionMultiQuery, SubQueries: tc.requested}
+		if _, _, err := svc.expandedRetrieve(context.Background(), 1, req, RetrievalQuery{Query: "q"}); err != nil {
+			t.Fatalf("expandedRetrieve: %v", err)
+		}
+		if !strings.Contains(client.prompts[0], tc.want) {
+			t.Errorf("sub_queries %d: prompt does not contain %q", tc.requested, tc.want)
+		}
+	}
+}
+
+func TestMultiQueryRetrieveFusesResults(t *testing.T) {
+	retrieval := &scriptedRetrieval{results: map[string][]string{
+		"q": {"c1", "c2"},
+		"a": {"c3", "c2"},
+		"b": {"c2", "c4", "c1"},
+	}}
+	svc := &chatService{retrieval: retrieval}
+
+	chunks, err := svc.multiQueryRetrieve(context.Background(), 1, RetrievalQuery{Query: "q", TopK: 3}, []string{"a", "failing", "b"})
+	if err != nil {
+		t.Fatalf("multiQueryRetrieve: %v", err)
+	}
+	// c2 is found for every query, c1 for two; the failing query is skipped
+	ids := chunkIDs(chunks)
+	if len(ids) != 3 || ids[0] != "c2" || ids[1] != "c1" {
+		t.Errorf("fused chunks = %v, want c2, c1 and one more", ids)
+	}
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			t.Errorf("chunk %s returned twice in %v", id, ids)
+		}
+		seen[id] = true
+	}
+	if len(retrieval.queries) != 4 {
+		t.Errorf("expected the question and 3 sub-queries to be retrieved, got %d", len(retrieval.queries))
+	}
+
+	if _, err := svc.multiQueryRetrieve(context.Background(), 1, RetrievalQuery{Query: "failing"}, []string{"failing too"}); err == nil {
+		t.Error("expected an error when every retrieval fails")
+	}
+}
+
+func TestExpandedRetrieveFallsBack(t *testing.T) {
+	for _, expansion := range []string{ExpansionHyDE, ExpansionMultiQuery} {
+		// The LLM has no response to give
+		retrieval := &scriptedRetrieval{results: map[string][]string{"q": {"c1"}}}
+		svc := &chatService{llmClient: &queuedLLM{}, retrieval: retrieval}
+
+		req := ChatRequest{Query: "q", Expansion: expansion}
+		chunks, expanded, err := svc.expandedRetrieve(context.Background(), 1, req, RetrievalQuery{Query: "q"})
+		if err != nil {
+			t.Fatalf("%s: expandedRetrieve: %v", expansion, err)
+		}
+		if expanded != nil || !slices.Equal(chunkIDs(chunks), []string{"c1"}) {
+			t.Errorf("%s: got chunks %v and expansions %v, want the question's chunks alone", expansion, chunkIDs(chunks), expanded)
+		}
+		if len(retrieval.queries) != 1 || retrieval.queries[0].VectorQuery != "" {
+			t.Errorf("%s: expected one retrieval for the question, got %+v", expansion, retrieval.queries)
+		}
+	}
+
+	// HyDE embeds the passage but searches full text for the question
+	retrieval := &scriptedRetrieval{results: map[string][]string{"q": {"c1"}}}
+	svc := &chatService{llmClient: &queuedLLM{responses: []string{" Ada wrote the first program. "}}, retrieval: retrieval}
+	_, expanded, err := svc.expandedRetrieve(context.Background(), 1, ChatRequest{Query: "q", Expansion: ExpansionHyDE}, RetrievalQuery{Query: "q"})
+	if err != nil {
+		t.Fatalf("expandedRetrieve: %v", err)
+	}
+	if !slices.Equal(expanded, []string{"Ada wrote the first program."}) || retrieval.queries[0].VectorQuery != expanded[0] {
+		t.Errorf("HyDE expansions = %q, vector query %q", expanded, retrieval.queries[0].VectorQuery)
+	}
+}
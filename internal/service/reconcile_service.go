@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/pkg/embedding"
+)
+
+const (
+	// reconcileGracePeriod skips chunks whose vectors may still be in flight from ingestion.
+	reconcileGracePeriod = 10 * time.Minute
+	// reconcileBatchSize bounds embedding, upsert and delete requests during repair.
+	reconcileBatchSize = 100
+)
+
+// CollectionReport describes the drift found in one vector collection.
+type CollectionReport struct {
+	Collection string   `json:"collection"`
+	Chunks     int      `json:"chunks"`
+	Points     int      `json:"points"`
+	Missing    []string `json:"missing"`  // chunk IDs without a point
+	Orphaned   []string `json:"orphaned"` // point IDs without a chunk
+	Reembedded int      `json:"reembedded"`
+	Deleted    int      `json:"deleted"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// ReconcileReport is the result of one reconciliation run.
+type ReconcileReport struct {
+	DryRun      bool                `json:"dry_run"`
+	StartedAt   time.Time           `json:"started_at"`
+	FinishedAt  time.Time           `json:"finished_at"`
+	Collections []*CollectionReport `json:"collections"`
+}
+
+// ReconcileService detects and repairs drift between Postgres chunks and Qdrant points.
+type ReconcileService interface {
+	// Reconcile compares chunk IDs with point IDs. Unless dryRun is set, missing points are
+	// re-embedded and orphaned points are deleted.
+	Reconcile(ctx context.Context, dryRun bool) (*ReconcileReport, error)
+	// Run reconciles every interval until ctx is cancelled.
+	Run(ctx context.Context, interval time.Duration, dryRun bool)
+}
+
+type reconcileService struct {
+	chunkRepo       repository.ChunkRepository
+	vectorRepo      repository.VectorRepository
+	embeddingClient embedding.Client
+}
+
+// NewReconcileService creates a new ReconcileService.
+func NewReconcileService(chunkRepo repository.ChunkRepository, vectorRepo repository.VectorRepository, embeddingClient embedding.Client) ReconcileService {
+	return &reconcileService{
+		chunkRepo:       chunkRepo,
+		vectorRepo:      vectorRepo,
+		embeddingClient: embeddingClient,
+	}
+}
+
+func (s *reconcileService) Reconcile(ctx context.Context, dryRun bool) (*ReconcileReport, error) {
+	report := &ReconcileReport{DryRun: dryRun, StartedAt: time.Now()}
+
+	chunkIDs, err := s.chunkRepo.ListChunkIDs(ctx, report.StartedAt.Add(-reconcileGracePeriod))
+	if err != nil {
+		return nil, err
+	}
+
+	col, err := s.reconcileChunkCollection(ctx, chunkIDs, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	report.Collections = append(report.Collections, col)
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+func (s *reconcileService) reconcileChunkCollection(ctx context.Context, chunkIDs []string, dryRun bool) (*CollectionReport, error) {
+	col := &CollectionReport{
+		Collection: chunkCollection,
+		Chunks:     len(chunkIDs),
+		Missing:    []string{},
+		Orphaned:   []string{},
+	}
+
+	pointIDs, err := s.pointIDs(ctx, chunkCollection)
+	if err != nil {
+		return nil, err
+	}
+	col.Points = len(pointIDs)
+
+	expected := make(map[string]bool, len(chunkIDs))
+	for _, id := range chunkIDs {
+		expected[id] = true
+		if !pointIDs[id] {
+			col.Missing = append(col.Missing, id)
+		}
+	}
+	for id := range pointIDs {
+		if !expected[id] {
+			col.Orphaned = append(col.Orphaned, id)
+		}
+	}
+
+	// Recent chunks were excluded from expected, so their points are not orphans yet
+	if len(col.Orphaned) > 0 {
+		recent, err := s.chunkRepo.GetChunksByIDs(ctx, col.Orphaned)
+		if err != nil {
+			return nil, err
+		}
+		stillExists := make(map[string]bool, len(recent))
+		for _, c := range recent {
+			stillExists[c.ID] = true
+		}
+		orphaned := col.Orphaned[:0]
+		for _, id := range col.Orphaned {
+			if !stillExists[id] {
+				orphaned = append(orphaned, id)
+			}
+		}
+		col.Orphaned = orphaned
+	}
+
+	if dryRun {
+		return col, nil
+	}
+
+	if len(col.Missing) > 0 {
+		if err := s.vectorRepo.CreateCollection(ctx, chunkCollection, embeddingDim); err != nil {
+			return nil, err
+		}
+	}
+	for start := 0; start < len(col.Missing); start += reconcileBatchSize {
+		batch := col.Missing[start:min(start+reconcileBatchSize, len(col.Missing))]
+		n, err := s.reembed(ctx, batch)
+		col.Reembedded += n
+		if err != nil {
+			col.Errors = append(col.Errors, err.Error())
+		}
+	}
+
+	for start := 0; start < len(col.Orphaned); start += reconcileBatchSize {
+		batch := col.Orphaned[start:min(start+reconcileBatchSize, len(col.Orphaned))]
+		if err := s.vectorRepo.Delete(ctx, chunkCollection, batch); err != nil {
+			col.Errors = append(col.Errors, err.Error())
+			continue
+		}
+		col.Deleted += len(batch)
+	}
+
+	return col, nil
+}
+
+// pointIDs collects all point IDs of a collection. A missing collection has no points.
+func (s *reconcileService) pointIDs(ctx context.Context, collection string) (map[string]bool, error) {
+	ids := make(map[string]bool)
+
+	exists, err := s.vectorRepo.CollectionExists(ctx, collection)
+	if err != nil || !exists {
+		return ids, err
+	}
+
+	offset := ""
+	for {
+		points, next, err := s.vectorRepo.Scroll(ctx, collection, offset, 256)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range points {
+			ids[p.ID] = true
+		}
+		if next == "" {
+			return ids, nil
+		}
+		offset = next
+	}
+}
+
+// reembed regenerates and upserts the vectors of the given chunks.
+func (s *reconcileService) reembed(ctx context.Context, ids []string) (int, error) {
+	if s.embeddingClient == nil {
+		return 0, fmt.Errorf("cannot re-embed %d chunks: embedding client not initialized", len(ids))
+	}
+
+	chunks, err := s.chunkRepo.GetChunksByIDs(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	if len(chunks) == 0 {
+		return 0, nil
+	}
+
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.Content
+	}
+	embeddings, err := s.embeddingClient.EmbedBatch(ctx, texts)
+	if err != nil {
+		return 0, fmt.Errorf("embedding failed: %w", err)
+	}
+
+	points := make([]*entity.VectorPoint, len(chunks))
+	for i, c := range chunks {
+		points[i] = chunkPoint(c, embeddings[i])
+	}
+	if err := s.vectorRepo.Upsert(ctx, chunkCollection, points); err != nil {
+		return 0, err
+	}
+	return len(points), nil
+}
+
+func (s *reconcileService) Run(ctx context.Context, interval time.Duration, dryRun bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := s.Reconcile(ctx, dryRun)
+			if err != nil {
+				log.Printf("Reconcile: failed: %v", err)
+				continue
+			}
+			for _, col := range report.Collections {
+				if len(col.Missing) > 0 || len(col.Orphaned) > 0 {
+					log.Printf("Reconcile: %s: %d missing, %d orphaned points (re-embedded %d, deleted %d, dry run %t)",
+						col.Collection, len(col.Missing), len(col.Orphaned), col.Reembedded, col.Deleted, report.DryRun)
+				}
+				for _, e := range col.Errors {
+					log.Printf("Reconcile: %s: %s", col.Collection, e)
+				}
+			}
+		}
+	}
+}
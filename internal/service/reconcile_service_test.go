@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+)
+
+// agedChunks holds chunks of which only the old ones are listed for reconciliation.
+type agedChunks struct {
+	repository.ChunkRepository
+	chunks []*entity.Chunk
+	old    []string
+}
+
+func (r *agedChunks) ListChunkIDs(ctx context.Context, createdBefore time.Time) ([]string, error) {
+	return r.old, nil
+}
+
+func (r *agedChunks) GetChunksByIDs(ctx context.Context, ids []string) ([]*entity.Chunk, error) {
+	chunks := []*entity.Chunk{}
+	for _, c := range r.chunks {
+		if slices.Contains(ids, c.ID) {
+			chunks = append(chunks, c)
+		}
+	}
+	return chunks, nil
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	chunks := &agedChunks{
+		chunks: []*entity.Chunk{
+			{ID: "a", DocumentID: 1, Content: "alice"},
+			{ID: "b", DocumentID: 1, Content: "bob"},
+			{ID: "c", DocumentID: 2, Content: "paris"},
+			{ID: "new", DocumentID: 2, Content: "alice"}, // its vector may still be in flight
+		},
+		old: []string{"a", "b", "c"},
+	}
+	vectors := &memoryVectors{points: map[string][]*entity.VectorPoint{
+		chunkCollection: {vectorPoint("a", 1), vectorPoint("new", 2), vectorPoint("gone", 3)},
+	}}
+	svc := NewReconcileService(chunks, vectors, wordEmbedder{})
+
+	report, err := svc.Reconcile(ctx, true)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	col := report.Collections[0]
+	slices.Sort(col.Missing)
+	if !slices.Equal(col.Missing, []string{"b", "c"}) || !slices.Equal(col.Orphaned, []string{"gone"}) {
+		t.Errorf("expected b and c missing and gone orphaned, got %v and %v", col.Missing, col.Orphaned)
+	}
+	if col.Reembedded != 0 || col.Deleted != 0 {
+		t.Errorf("dry run repaired %d and deleted %d points", col.Reembedded, col.Deleted)
+	}
+	if got := vectors.ids(chunkCollection); !slices.Equal(got, []string{"a", "new", "gone"}) {
+		t.Errorf("dry run changed the points to %v", got)
+	}
+
+	report, err = svc.Reconcile(ctx, false)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	col = report.Collections[0]
+	if col.Reembedded != 2 || col.Deleted != 1 || len(col.Errors) != 0 {
+		t.Errorf("expected 2 re-embedded and 1 deleted point, got %d, %d and errors %v", col.Reembedded, col.Deleted, col.Errors)
+	}
+	got := vectors.ids(chunkCollection)
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"a", "b", "c", "new"}) {
+		t.Errorf("expected points a, b, c and new after repair, got %v", got)
+	}
+	for _, p := range vectors.points[chunkCollection] {
+		if p.ID == "c" && (len(p.Vector) != 3 || p.Vector[2] != 1 || p.Payload["document_id"] != int64(2)) {
+			t.Errorf("expected chunk c to be re-embedded with its payload, got %+v", p)
+		}
+	}
+}
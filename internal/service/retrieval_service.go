@@ -0,0 +1,546 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+	"github.com/suyw-0123/graphweaver/pkg/embedding"
+	"github.com/suyw-0123/graphweaver/pkg/rerank"
+)
+
+const (
+	// rrfK damps the advantage of the top ranks in reciprocal rank fusion. 60 is the value
+	// from the original RRF paper and works well without tuning.
+	rrfK = 60
+
+	defaultRetrievalTopK = 5
+	maxRetrievalTopK     = 50
+
+	// retrievalCandidates is how many results each retriever contributes to the fusion.
+	retrievalCandidates = 50
+	// defaultVectorScoreThreshold drops dense matches that are barely related.
+	defaultVectorScoreThreshold = 0.6
+	// rerankCandidates is how many fused results are reranked before keeping the top k.
+	rerankCandidates = 20
+
+	// graphSeedWeight is how much more a mention of a named entity counts than a mention
+	// of one of its relations in graph search.
+	graphSeedWeight = 2
+)
+
+// Retrieval modes. Hybrid fuses vector and lexical search; graph finds the chunks that
+// mention the entities the query is linked to and their relations.
+const (
+	RetrievalModeHybrid  = "hybrid"
+	RetrievalModeVector  = "vector"
+	RetrievalModeLexical = "lexical"
+	RetrievalModeGraph   = "graph"
+)
+
+// ErrInvalidRetrieval is returned for retrieval parameters out of range.
+var ErrInvalidRetrieval = errors.New("service: invalid retrieval query")
+
+// RetrievalQuery describes a chunk search over a notebook.
+type RetrievalQuery struct {
+	Query string
+	// VectorQuery is embedded for dense search instead of Query when set, such as a
+	// hypothetical answer to the query. Full-text search and reranking use Query.
+	VectorQuery string
+	TopK        int
+	// Mode selects the retrievers, hybrid by default.
+	Mode string
+	// VectorWeight and LexicalWeight scale the contribution of dense and full-text search to
+	// the fused ranking in hybrid mode. A zero weight disables that retriever; both zero
+	// weighs them equally.
+	VectorWeight  float64
+	LexicalWeight float64
+	// DocumentIDs restricts the search to these documents of the notebook when set.
+	DocumentIDs []int64
+	// ScoreThreshold is the minimum cosine similarity of vector matches, 0.6 when zero.
+	ScoreThreshold float64
+	// Seeds are graph nodes the query refers to, such as its linked entities. In any mode,
+	// the chunks mentioning them are fused as a further ranking. Graph mode links the query
+	// itself when there are none.
+	Seeds []*entity.Node
+}
+
+// RetrievedChunk is a chunk ranked by retrieval.
+type RetrievedChunk struct {
+	*entity.Chunk
+	// Score is the weighted reciprocal rank fusion score.
+	Score float64 `json:"score"`
+	// VectorRank, LexicalRank and GraphRank are the 1-based ranks from each retriever, 0
+	// when the retriever did not return the chunk.
+	VectorRank   int     `json:"vector_rank,omitempty"`
+	VectorScore  float64 `json:"vector_score,omitempty"`
+	LexicalRank  int     `json:"lexical_rank,omitempty"`
+	LexicalScore float64 `json:"lexical_score,omitempty"`
+	GraphRank    int     `json:"graph_rank,omitempty"`
+	GraphScore   float64 `json:"graph_score,omitempty"`
+	// RerankScore is the reranker's relevance score, when a reranker is configured.
+	RerankScore *float64 `json:"rerank_score,omitempty"`
+}
+
+// SearchResult is a retrieved chunk with its document and the entities it mentions.
+type SearchResult struct {
+	*RetrievedChunk
+	Document *entity.Document `json:"document"`
+	Entities []*MatchedEntity `json:"entities"`
+}
+
+// MatchedEntity is a graph node mentioned in a search result.
+type MatchedEntity struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Label string `json:"label"`
+}
+
+// RetrievalService finds the chunks of a notebook relevant to a query.
+type RetrievalService interface {
+	Retrieve(ctx context.Context, notebookID int64, q RetrievalQuery) ([]*RetrievedChunk, error)
+	// Search is Retrieve for API clients: it checks the notebook exists and adds the
+	// document and mentioned entities of each chunk.
+	Search(ctx context.Context, notebookID int64, q RetrievalQuery) ([]*SearchResult, error)
+}
+
+type retrievalService struct {
+	docRepo         repository.DocumentRepository
+	notebookRepo    repository.NotebookRepository
+	chunkRepo       repository.ChunkRepository
+	graphRepo       repository.GraphRepository
+	mentionRepo     repository.MentionRepository
+	vectorRepo      repository.VectorRepository
+	embeddingClient embedding.Client
+	linker          EntityLinkingService
+	reranker        rerank.Reranker
+}
+
+// NewRetrievalService creates a new RetrievalService. The reranker is optional.
+func NewRetrievalService(
+	docRepo repository.DocumentRepository,
+	notebookRepo repository.NotebookRepository,
+	chunkRepo repository.ChunkRepository,
+	graphRepo repository.GraphRepository,
+	mentionRepo repository.MentionRepository,
+	vectorRepo repository.VectorRepository,
+	embeddingClient embedding.Client,
+	linker EntityLinkingService,
+	reranker rerank.Reranker,
+) RetrievalService {
+	return &retrievalService{
+		docRepo:         docRepo,
+		notebookRepo:    notebookRepo,
+		chunkRepo:       chunkRepo,
+		graphRepo:       graphRepo,
+		mentionRepo:     mentionRepo,
+		vectorRepo:      vectorRepo,
+		embeddingClient: embeddingClient,
+		linker:          linker,
+		reranker:        reranker,
+	}
+}
+
+// Retrieve runs the retrievers of the query's mode and fuses their rankings. Dense search
+// catches paraphrases, full-text search exact names, identifiers and acronyms. When one
+// retriever fails, the other's results are returned alone. With a reranker, the top fused
+// candidates are reordered by it before keeping the top k.
+func (s *retrievalService) Retrieve(ctx context.Context, notebookID int64, q RetrievalQuery) ([]*RetrievedChunk, error) {
+	if err := normalizeRetrievalQuery(&q); err != nil {
+		return nil, err
+	}
+	var docs map[int64]bool
+	if len(q.DocumentIDs) > 0 {
+		docs = make(map[int64]bool, len(q.DocumentIDs))
+		for _, id := range q.DocumentIDs {
+			docs[id] = true
+		}
+	}
+
+	chunks := make(map[string]*RetrievedChunk)
+	var rankings []ranking
+	var errs []error
+
+	if q.VectorWeight > 0 {
+		vectorQuery := q.Query
+		if q.VectorQuery != "" {
+			vectorQuery = q.VectorQuery
+		}
+		hits, err := s.vectorSearch(ctx, notebookID, vectorQuery, docs, q.ScoreThreshold)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			r := ranking{weight: q.VectorWeight}
+			for i, h := range hits {
+				c := retrieved(chunks, h.Chunk)
+				c.VectorRank, c.VectorScore = i+1, h.Score
+				r.ids = append(r.ids, c.ID)
+			}
+			rankings = append(rankings, r)
+		}
+	}
+	if q.LexicalWeight > 0 {
+		matches, err := s.chunkRepo.SearchChunks(ctx, notebookID, q.Query, q.DocumentIDs, retrievalCandidates)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("service: lexical search failed: %w", err))
+		} else {
+			r := ranking{weight: q.LexicalWeight}
+			for i, m := range matches {
+				c := retrieved(chunks, &m.Chunk)
+				c.LexicalRank, c.LexicalScore = i+1, m.Score
+				r.ids = append(r.ids, c.ID)
+			}
+			rankings = append(rankings, r)
+		}
+	}
+	if q.Mode == RetrievalModeGraph || len(q.Seeds) > 0 {
+		hits, err := s.graphSearch(ctx, notebookID, q, docs)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			r := ranking{weight: 1}
+			for i, h := range hits {
+				c := retrieved(chunks, h.Chunk)
+				c.GraphRank, c.GraphScore = i+1, h.Score
+				r.ids = append(r.ids, c.ID)
+			}
+			rankings = append(rankings, r)
+		}
+	}
+	if len(rankings) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	fused := reciprocalRankFusion(rankings)
+	candidates := len(fused)
+	if s.reranker == nil {
+		candidates = min(candidates, q.TopK)
+	} else {
+		candidates = min(candidates, max(q.TopK, rerankCandidates))
+	}
+	results := make([]*RetrievedChunk, 0, candidates)
+	for _, f := range fused[:candidates] {
+		c := chunks[f.id]
+		c.Score = f.score
+		results = append(results, c)
+	}
+	if s.reranker != nil {
+		results = s.rerank(ctx, q.Query, results)
+	}
+	return results[:min(len(results), q.TopK)], nil
+}
+
+func (s *retrievalService) Search(ctx context.Context, notebookID int64, q RetrievalQuery) ([]*SearchResult, error) {
+	if _, err := s.notebookRepo.GetByID(ctx, notebookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotebookNotFound
+		}
+		return nil, fmt.Errorf("service: failed to get notebook: %w", err)
+	}
+	chunks, err := s.Retrieve(ctx, notebookID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := s.docRepo.ListAllByNotebook(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list documents: %w", err)
+	}
+	docsByID := make(map[int64]*entity.Document, len(docs))
+	for _, d := range docs {
+		docsByID[d.ID] = d
+	}
+
+	chunkIDs := make([]string, len(chunks))
+	for i, c := range chunks {
+		chunkIDs[i] = c.ID
+	}
+	entities, err := s.chunkEntities(ctx, notebookID, chunkIDs)
+	if err != nil {
+		// Results are still useful without their entities
+		fmt.Printf("Warning: failed to get entities of search results: %v\n", err)
+	}
+
+	results := make([]*SearchResult, len(chunks))
+	for i, c := range chunks {
+		results[i] = &SearchResult{RetrievedChunk: c, Document: docsByID[c.DocumentID], Entities: entities[c.ID]}
+		if results[i].Entities == nil {
+			results[i].Entities = []*MatchedEntity{}
+		}
+	}
+	return results, nil
+}
+
+// chunkEntities returns the nodes mentioned in each chunk.
+func (s *retrievalService) chunkEntities(ctx context.Context, notebookID int64, chunkIDs []string) (map[string][]*MatchedEntity, error) {
+	mentions, err := s.mentionRepo.ListByChunks(ctx, chunkIDs)
+	if err != nil || len(mentions) == 0 {
+		return nil, err
+	}
+	nodes, err := s.graphRepo.GetNodesByNotebookID(ctx, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	nodesByID := make(map[int64]*entity.Node, len(nodes))
+	for _, n := range nodes {
+		nodesByID[n.ID] = n
+	}
+
+	type chunkNode struct {
+		chunkID string
+		nodeID  int64
+	}
+	entities := make(map[string][]*MatchedEntity)
+	seen := make(map[chunkNode]bool)
+	for _, m := range mentions {
+		if m.NodeID == nil || nodesByID[*m.NodeID] == nil || seen[chunkNode{m.ChunkID, *m.NodeID}] {
+			continue
+		}
+		seen[chunkNode{m.ChunkID, *m.NodeID}] = true
+		n := nodesByID[*m.NodeID]
+		entities[m.ChunkID] = append(entities[m.ChunkID], &MatchedEntity{ID: n.ID, Name: n.Name, Label: n.Label})
+	}
+	return entities, nil
+}
+
+// normalizeRetrievalQuery validates a query and applies the defaults of its mode.
+func normalizeRetrievalQuery(q *RetrievalQuery) error {
+	if q.VectorWeight < 0 || q.LexicalWeight < 0 {
+		return fmt.Errorf("%w: weights cannot be negative", ErrInvalidRetrieval)
+	}
+	if q.ScoreThreshold < 0 || q.ScoreThreshold > 1 {
+		return fmt.Errorf("%w: score_threshold must be between 0 and 1", ErrInvalidRetrieval)
+	}
+	if q.ScoreThreshold == 0 {
+		q.ScoreThreshold = defaultVectorScoreThreshold
+	}
+	switch q.Mode {
+	case "", RetrievalModeHybrid:
+		q.Mode = RetrievalModeHybrid
+		if q.VectorWeight == 0 && q.LexicalWeight == 0 {
+			q.VectorWeight, q.LexicalWeight = 1, 1
+		}
+	case RetrievalModeVector:
+		q.VectorWeight, q.LexicalWeight = 1, 0
+	case RetrievalModeLexical:
+		q.VectorWeight, q.LexicalWeight = 0, 1
+	case RetrievalModeGraph:
+		q.VectorWeight, q.LexicalWeight = 0, 0
+	default:
+		return fmt.Errorf("%w: mode must be hybrid, vector, lexical or graph", ErrInvalidRetrieval)
+	}
+	if q.TopK <= 0 {
+		q.TopK = defaultRetrievalTopK
+	}
+	q.TopK = min(q.TopK, maxRetrievalTopK)
+	return nil
+}
+
+// scoredChunk is a chunk found by one retriever with that retriever's score.
+type scoredChunk struct {
+	Chunk *entity.Chunk
+	Score float64
+}
+
+// vectorSearch embeds the query and searches the chunks of the notebook's non-deleted
+// documents, and of docs when not nil.
+func (s *retrievalService) vectorSearch(ctx context.Context, notebookID int64, query string, docs map[int64]bool, threshold float64) ([]scoredChunk, error) {
+	if s.embeddingClient == nil || s.vectorRepo == nil {
+		return nil, fmt.Errorf("service: vector search is not configured")
+	}
+	vector, err := s.embeddingClient.EmbedText(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to embed query: %w", err)
+	}
+	notebookDocs, err := s.docRepo.ListAllByNotebook(ctx, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list documents: %w", err)
+	}
+	inScope := []int64{}
+	for _, d := range notebookDocs {
+		if !d.IsDeleted && (docs == nil || docs[d.ID]) {
+			inScope = append(inScope, d.ID)
+		}
+	}
+
+	results, err := s.vectorRepo.Search(ctx, chunkCollection, vector, retrievalCandidates, float32(threshold), inScope)
+	if err != nil {
+		return nil, fmt.Errorf("service: vector search failed: %w", err)
+	}
+
+	var hits []scoredChunk
+	for _, res := range results {
+		docID, ok := payloadInt64(res.Payload["document_id"])
+		if !ok {
+			continue
+		}
+		content, _ := res.Payload["content"].(string)
+		index, _ := payloadInt64(res.Payload["chunk_index"])
+		hits = append(hits, scoredChunk{
+			Chunk: &entity.Chunk{ID: res.ID, DocumentID: docID, Index: int(index), Content: content},
+			Score: float64(res.Score),
+		})
+	}
+	return hits, nil
+}
+
+// graphSearch ranks chunks by their mentions of the query's seeds, or of the entities the
+// query is linked to, and of the relations attached to them.
+func (s *retrievalService) graphSearch(ctx context.Context, notebookID int64, q RetrievalQuery, docs map[int64]bool) ([]scoredChunk, error) {
+	seeds := q.Seeds
+	if len(seeds) == 0 {
+		linked, err := s.linker.LinkEntities(ctx, notebookID, q.Query)
+		if err != nil {
+			return nil, err
+		}
+		seeds = linkedNodes(linked)
+	}
+	if len(seeds) == 0 {
+		return nil, nil
+	}
+	return s.chunksMentioning(ctx, seeds, docs)
+}
+
+// chunksMentioning ranks chunks by their mentions of the seed nodes and of the relations
+// attached to them; a node mention counts graphSeedWeight times a relation mention.
+func (s *retrievalService) chunksMentioning(ctx context.Context, seeds []*entity.Node, docs map[int64]bool) ([]scoredChunk, error) {
+	seedIDs := make([]int64, len(seeds))
+	var edgeIDs []int64
+	for i, n := range seeds {
+		seedIDs[i] = n.ID
+		_, edges, err := s.graphRepo.GetNeighbors(ctx, n.ID, repository.NeighborQuery{
+			Direction: repository.DirectionBoth, Depth: 1, Limit: defaultNodeLimit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to get neighbors: %w", err)
+		}
+		for _, e := range edges {
+			edgeIDs = append(edgeIDs, e.ID)
+		}
+	}
+	mentions, err := s.mentionRepo.ListByEntities(ctx, seedIDs, edgeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get mentions: %w", err)
+	}
+
+	scores := make(map[string]float64)
+	for _, m := range mentions {
+		if docs != nil && !docs[m.DocumentID] {
+			continue
+		}
+		if m.NodeID != nil {
+			scores[m.ChunkID] += graphSeedWeight
+		} else {
+			scores[m.ChunkID]++
+		}
+	}
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	ids = ids[:min(len(ids), retrievalCandidates)]
+
+	chunks, err := s.chunkRepo.GetChunksByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get chunks: %w", err)
+	}
+	byID := make(map[string]*entity.Chunk, len(chunks))
+	for _, c := range chunks {
+		byID[c.ID] = c
+	}
+	hits := make([]scoredChunk, 0, len(ids))
+	for _, id := range ids {
+		if c := byID[id]; c != nil {
+			hits = append(hits, scoredChunk{Chunk: c, Score: scores[id]})
+		}
+	}
+	return hits, nil
+}
+
+// retrieved returns the result entry for a chunk, adding it on first sight.
+func retrieved(chunks map[string]*RetrievedChunk, c *entity.Chunk) *RetrievedChunk {
+	if r, ok := chunks[c.ID]; ok {
+		return r
+	}
+	r := &RetrievedChunk{Chunk: c}
+	chunks[c.ID] = r
+	return r
+}
+
+// rerank reorders chunks by the reranker's scores, keeping the fused order if it fails.
+func (s *retrievalService) rerank(ctx context.Context, query string, chunks []*RetrievedChunk) []*RetrievedChunk {
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.Content
+	}
+	ranked, err := s.reranker.Rerank(ctx, query, texts)
+	if err != nil {
+		fmt.Printf("Warning: Reranking failed, keeping fused order: %v\n", err)
+		return chunks
+	}
+
+	reordered := make([]*RetrievedChunk, 0, len(chunks))
+	seen := make(map[int]bool, len(chunks))
+	for _, r := range ranked {
+		if r.Index < 0 || r.Index >= len(chunks) || seen[r.Index] {
+			continue
+		}
+		seen[r.Index] = true
+		score := r.Score
+		chunks[r.Index].RerankScore = &score
+		reordered = append(reordered, chunks[r.Index])
+	}
+	for i, c := range chunks {
+		if !seen[i] {
+			reordered = append(reordered, c)
+		}
+	}
+	return reordered
+}
+
+// ranking is one retriever's result IDs, best first, and its weight in the fusion.
+type ranking struct {
+	ids    []string
+	weight float64
+}
+
+type fusedResult struct {
+	id    string
+	score float64
+}
+
+// reciprocalRankFusion scores each ID by the sum over rankings of weight / (rrfK + rank),
+// with 1-based ranks. Fusing ranks rather than scores needs no calibration between
+// retrievers whose scores are not comparable. Ties are broken by ID.
+func reciprocalRankFusion(rankings []ranking) []fusedResult {
+	scores := make(map[string]float64)
+	for _, r := range rankings {
+		for i, id := range r.ids {
+			scores[id] += r.weight / float64(rrfK+i+1)
+		}
+	}
+	fused := make([]fusedResult, 0, len(scores))
+	for id, score := range scores {
+		fused = append(fused, fusedResult{id: id, score: score})
+	}
+	sort.Slice(fused, func(i, j int) bool {
+		if fused[i].score != fused[j].score {
+			return fused[i].score > fused[j].score
+		}
+		return fused[i].id < fused[j].id
+	})
+	return fused
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/suyw-0123/graphweaver/internal/entity"
+	"github.com/suyw-0123/graphweaver/internal/repository"
+)
+
+func TestReciprocalRankFusion(t *testing.T) {
+	vector := ranking{ids: []string{"a", "b", "c"}, weight: 1}
+	lexical := ranking{ids: []string{"c", "d", "a"}, weight: 1}
+
+	fused := reciprocalRankFusion([]ranking{vector, lexical})
+	if len(fused) != 4 {
+		t.Fatalf("expected 4 fused results, got %d", len(fused))
+	}
+	// a: 1/61 + 1/63, c: 1/63 + 1/61, so the tie is broken by ID
+	if fused[0].id != "a" || fused[1].id != "c" {
+		t.Errorf("expected chunks found by both retrievers first, got %v", fused)
+	}
+	if want := 1.0/61 + 1.0/63; math.Abs(fused[0].score-want) > 1e-12 {
+		t.Errorf("expected score %v, got %v", want, fused[0].score)
+	}
+	if fused[2].id != "b" || fused[3].id != "d" {
+		t.Errorf("expected b (vector rank 2) before d (lexical rank 2) by ID, got %v", fused)
+	}
+
+	// Weighing full-text search up lets its top result win
+	lexical.weight = 3
+	fused = reciprocalRankFusion([]ranking{vector, lexical})
+	if fused[0].id != "c" {
+		t.Errorf("expected the weighted lexical ranking to put c first, got %v", fused)
+	}
+}
+
+func TestNormalizeRetrievalQuery(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		q     RetrievalQuery
+		want  RetrievalQuery
+		valid bool
+	}{
+		{"defaults", RetrievalQuery{},
+			RetrievalQuery{Mode: RetrievalModeHybrid, VectorWeight: 1, LexicalWeight: 1, TopK: defaultRetrievalTopK, ScoreThreshold: defaultVectorScoreThreshold}, true},
+		{"hybrid keeps weights", RetrievalQuery{Mode: RetrievalModeHybrid, VectorWeight: 0.5, LexicalWeight: 2, TopK: 10, ScoreThreshold: 0.3},
+			RetrievalQuery{Mode: RetrievalModeHybrid, VectorWeight: 0.5, LexicalWeight: 2, TopK: 10, ScoreThreshold: 0.3}, true},
+		{"hybrid with one weight", RetrievalQuery{LexicalWeight: 1},
+			RetrievalQuery{Mode: RetrievalModeHybrid, LexicalWeight: 1, TopK: defaultRetrievalTopK, ScoreThreshold: defaultVectorScoreThreshold}, true},
+		{"vector", RetrievalQuery{Mode: RetrievalModeVector, LexicalWeight: 3},
+			RetrievalQuery{Mode: RetrievalModeVector, VectorWeight: 1, TopK: defaultRetrievalTopK, ScoreThreshold: defaultVectorScoreThreshold}, true},
+		{"lexical", RetrievalQuery{Mode: RetrievalModeLexical, VectorWeight: 3},
+			RetrievalQuery{Mode: RetrievalModeLexical, LexicalWeight: 1, TopK: defaultRetrievalTopK, ScoreThreshold: defaultVectorScoreThreshold}, true},
+		{"graph", RetrievalQuery{Mode: RetrievalModeGraph, VectorWeight: 1, LexicalWeight: 1},
+			RetrievalQuery{Mode: RetrievalModeGraph, TopK: defaultRetrievalTopK, ScoreThreshold: defaultVectorScoreThreshold}, true},
+		{"top_k clamped", RetrievalQuery{TopK: 80},
+			RetrievalQuery{Mode: RetrievalModeHybrid, VectorWeight: 1, LexicalWeight: 1, TopK: maxRetrievalTopK, ScoreThreshold: defaultVectorScoreThreshold}, true},
+		{"threshold of 1", RetrievalQuery{ScoreThreshold: 1},
+			RetrievalQuery{Mode: RetrievalModeHybrid, VectorWeight: 1, LexicalWeight: 1, TopK: defaultRetrievalTopK, ScoreThreshold: 1}, true},
+		{"unknown mode", RetrievalQuery{Mode: "fuzzy"}, RetrievalQuery{}, false},
+		{"negative weight", RetrievalQuery{VectorWeight: -1}, RetrievalQuery{}, false},
+		{"negative threshold", RetrievalQuery{ScoreThreshold: -0.1}, RetrievalQuery{}, false},
+		{"threshold above 1", RetrievalQuery{ScoreThreshold: 1.5}, RetrievalQuery{}, false},
+	} {
+		q := tc.q
+		err := normalizeRetrievalQuery(&q)
+		if !tc.valid {
+			if !errors.Is(err, ErrInvalidRetrieval) {
+				t.Errorf("%s: got error %v, want ErrInvalidRetrieval", tc.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if q.Mode != tc.want.Mode || q.VectorWeight != tc.want.VectorWeight || q.LexicalWeight != tc.want.LexicalWeight ||
+			q.TopK != tc.want.TopK || q.ScoreThreshold != tc.want.ScoreThreshold {
+			t.Errorf("%s: got %+v, want %+v", tc.name, q, tc.want)
+		}
+	}
+}
+
+// seedGraph relates node 1 to node 2 through edge 10.
+type seedGraph struct {
+	repository.GraphRepository
+}
+
+func (seedGraph) GetNeighbors(ctx context.Context, nodeID int64, q repository.NeighborQuery) ([]*entity.Node, []*entity.Edge, error) {
+	return []*entity.Node{{ID: 1}, {ID: 2}}, []*entity.Edge{{ID: 10, SourceNodeID: 1, TargetNodeID: 2}}, nil
+}
+
+type entityMentions struct {
+	repository.MentionRepository
+	mentions []*entity.Mention
+}
+
+func (r *entityMentions) ListByEntities(ctx context.Context, nodeIDs, edgeIDs []int64) ([]*entity.Mention, error) {
+	return r.mentions, nil
+}
+
+func TestRetrieveGraphMode(t *testing.T) {
+	node, edge := int64(1), int64(10)
+	mentions := &entityMentions{mentions: []*entity.Mention{
+		{DocumentID: 1, ChunkID: "relation", EdgeID: &edge},
+		{DocumentID: 1, ChunkID: "entity", NodeID: &node},
+		{DocumentID: 1, ChunkID: "both", NodeID: &node},
+		{DocumentID: 1, ChunkID: "both", EdgeID: &edge},
+		{DocumentID: 1, ChunkID: "twice", EdgeID: &edge},
+		{DocumentID: 1, ChunkID: "twice", EdgeID: &edge},
+		{DocumentID: 2, ChunkID: "other", NodeID: &node},
+	}}
+	chunks := &agedChunks{}
+	for _, id := range []string{"relation", "entity", "both", "twice", "other"} {
+		chunks.chunks = append(chunks.chunks, &entity.Chunk{ID: id, DocumentID: 1})
+	}
+	chunks.chunks[4].DocumentID = 2
+	svc := NewRetrievalService(nil, nil, chunks, seedGraph{}, mentions, nil, nil, nil, nil)
+
+	q := RetrievalQuery{Query: "q", Mode: RetrievalModeGraph, Seeds: []*entity.Node{{ID: 1}}, TopK: 10}
+	results, err := svc.Retrieve(context.Background(), 1, q)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	// An entity mention counts twice a relation mention; ties are broken by chunk ID
+	want := []string{"both", "entity", "other", "twice", "relation"}
+	if got := chunkIDs(results); !slices.Equal(got, want) {
+		t.Errorf("graph ranking = %v, want %v", got, want)
+	}
+	if results[0].GraphRank != 1 || results[0].GraphScore != 3 || results[0].VectorRank != 0 || results[0].LexicalRank != 0 {
+		t.Errorf("top result = %+v, want graph rank 1 with score 3 and no other retrievers", results[0])
+	}
+
+	q.DocumentIDs = []int64{2}
+	results, err = svc.Retrieve(context.Background(), 1, q)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if got := chunkIDs(results); !slices.Equal(got, []string{"other"}) {
+		t.Errorf("graph ranking restricted to document 2 = %v, want [other]", got)
+	}
+}
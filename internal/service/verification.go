@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxVerifiedClaims bounds the claims an answer is split into for verification.
+const maxVerifiedClaims = 20
+
+// ClaimCheck is one claim of an answer and whether the context supports it.
+type ClaimCheck struct {
+	Claim     string `json:"claim"`
+	Supported bool   `json:"supported"`
+	// Evidence quotes the context supporting the claim
+	Evidence string `json:"evidence,omitempty"`
+}
+
+// Verification reports how well an answer is grounded in the context it was generated from.
+type Verification struct {
+	Claims []*ClaimCheck `json:"claims"`
+	// Groundedness is the share of supported claims, 1 for an answer without claims
+	Groundedness float64 `json:"groundedness"`
+	// Regenerated is set when the answer was rewritten because claims were unsupported;
+	// the claims are those of the rewritten answer.
+	Regenerated bool `json:"regenerated,omitempty"`
+}
+
+// checkAnswer verifies resp.Answer against the context the answer was generated from and
+// records the result in resp. With regenerate, an answer with unsupported claims is rewritten
+// from the context once and verified again. Verification is optional, so failures are logged
+// and leave the answer unverified.
+func (s *chatService) checkAnswer(ctx context.Context, question, contextText string, regenerate bool, resp *ChatResponse) {
+	v, err := s.verifyAnswer(ctx, contextText, resp.Answer)
+	if err != nil {
+		fmt.Printf("Warning: answer verification failed: %v\n", err)
+		return
+	}
+	resp.Verification = v
+	if !regenerate || v.Groundedness == 1 {
+		return
+	}
+
+	answer, err := s.regenerateAnswer(ctx, question, contextText, resp.Answer, v)
+	if err != nil {
+		fmt.Printf("Warning: answer regeneration failed, keeping the original: %v\n", err)
+		return
+	}
+	rv, err := s.verifyAnswer(ctx, contextText, answer)
+	if err != nil {
+		fmt.Printf("Warning: verification of regenerated answer failed, keeping the original: %v\n", err)
+		return
+	}
+	rv.Regenerated = true
+	resp.Answer, resp.Verification = answer, rv
+}
+
+// verifyAnswer splits an answer into claims, then asks the LLM whether the context supports
+// each of them.
+func (s *chatService) verifyAnswer(ctx context.Context, contextText, answer string) (*Verification, error) {
+	claims, err := s.answerClaims(ctx, answer)
+	if err != nil {
+		return nil, err
+	}
+	if len(claims) == 0 {
+		return &Verification{Claims: []*ClaimCheck{}, Groundedness: 1}, nil
+	}
+
+	var sb strings.Builder
+	for i, c := range claims {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, c)
+	}
+	prompt := fmt.Sprintf(`You are a fact checker. Decide for each numbered Claim whether the Context supports it.
+A claim is supported only if the Context states it or it follows directly from the Context; general knowledge does not count.
+Return ONLY a valid JSON object with the following structure, with one entry per claim:
+{
+  "checks": [
+    {"claim": 1, "supported": true, "evidence": "Short quote from the Context supporting the claim"}
+  ]
+}
+Leave the evidence empty for unsupported claims.
+
+Context:
+%s
+
+Claims:
+%s`, contextText, sb.String())
+
+	response, err := s.llmClient.GenerateContent(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Checks []struct {
+			Claim     int    `json:"claim"`
+			Supported bool   `json:"supported"`
+			Evidence  string `json:"evidence"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal([]byte(cleanJSONResponse(response)), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse claim checks: %w", err)
+	}
+
+	// Claims the checker skipped count as unsupported
+	checks := make([]*ClaimCheck, len(claims))
+	for i, c := range claims {
+		checks[i] = &ClaimCheck{Claim: c}
+	}
+	for _, r := range result.Checks {
+		if r.Claim < 1 || r.Claim > len(claims) {
+			continue
+		}
+		checks[r.Claim-1].Supported = r.Supported
+		if r.Supported {
+			checks[r.Claim-1].Evidence = r.Evidence
+		}
+	}
+	return &Verification{Claims: checks, Groundedness: groundedness(checks)}, nil
+}
+
+// answerClaims asks the LLM to split an answer into self-contained factual claims.
+func (s *chatService) answerClaims(ctx context.Context, answer string) ([]string, error) {
+	prompt := fmt.Sprintf(`Split the following Answer into its factual claims.
+Each claim is one short, self-contained statement: replace pronouns with the names they refer to.
+Leave out statements that claim nothing, such as saying that something is not known.
+Return ONLY a valid JSON object with the following structure:
+{"claims": ["Claim"]}
+
+Answer:
+%s`, answer)
+
+	response, err := s.llmClient.GenerateContent(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Claims []string `json:"claims"`
+	}
+	if err := json.Unmarshal([]byte(cleanJSONResponse(response)), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse claims: %w", err)
+	}
+	var claims []string
+	for _, c := range result.Claims {
+		if c = strings.TrimSpace(c); c != "" {
+			claims = append(claims, c)
+		}
+	}
+	return claims[:min(len(claims), maxVerifiedClaims)], nil
+}
+
+// regenerateAnswer rewrites an answer from the context, leaving out its unsupported claims.
+func (s *chatService) regenerateAnswer(ctx context.Context, question, contextText, answer string, v *Verification) (string, error) {
+	var sb strings.Builder
+	for _, c := range v.Claims {
+		if !c.Supported {
+			fmt.Fprintf(&sb, "- %s\n", c.Claim)
+		}
+	}
+	prompt := fmt.Sprintf(`You are a helpful assistant for a Knowledge Graph application.
+A previous answer to the User's Question made claims that the Context does not support.
+Write the answer again using only information in the Context. Leave out the unsupported claims.
+If the Context does not answer the question, say you don't know.
+
+Context:
+%s
+
+User Question: %s
+
+Previous Answer:
+%s
+
+Unsupported Claims:
+%s
+Answer:`, contextText, question, answer, sb.String())
+
+	return s.llmClient.GenerateContent(ctx, prompt)
+}
+
+// groundedness is the share of supported claims, 1 when there are none.
+func groundedness(checks []*ClaimCheck) float64 {
+	if len(checks) == 0 {
+		return 1
+	}
+	supported := 0
+	for _, c := range checks {
+		if c.Supported {
+			supported++
+		}
+	}
+	return float64(supported) / float64(len(checks))
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/suyw-0123/graphweaver/pkg/llm"
+)
+
+// queuedLLM returns its responses in order and records the prompts.
+type queuedLLM struct {
+	responses []string
+	prompts   []string
+}
+
+func (q *queuedLLM) GenerateContent(ctx context.Context, prompt string) (string, error) {
+	q.prompts = append(q.prompts, prompt)
+	if len(q.responses) == 0 {
+		return "", errors.New("no more responses")
+	}
+	r := q.responses[0]
+	q.responses = q.responses[1:]
+	return r, nil
+}
+
+func (q *queuedLLM) GenerateWithTools(ctx context.Context, messages []llm.Message, tools []llm.Tool) (*llm.ToolResponse, error) {
+	return nil, errors.New("tools not supported")
+}
+
+func (q *queuedLLM) Close() error { return nil }
+
+func TestCheckAnswer(t *testing.T) {
+	client := &queuedLLM{responses: []string{
+		// Claims of the answer, then their checks: the third claim is skipped
+		`{"claims": ["Ada wrote the first program", "Ada was born in Paris", "Ada met Babbage"]}`,
+		"```json\n" + `{"checks": [{"claim": 1, "supported": true, "evidence": "the first program"}, {"claim": 2, "supported": false, "evidence": "x"}, {"claim": 9, "supported": true}]}` + "\n```",
+	}}
+	svc := &chatService{llmClient: client}
+
+	resp := &ChatResponse{Answer: "Ada, born in Paris, wrote the first program and met Babbage."}
+	svc.checkAnswer(context.Background(), "Who was Ada?", "Ada wrote the first program.", false, resp)
+	v := resp.Verification
+	if v == nil || len(v.Claims) != 3 || v.Regenerated {
+		t.Fatalf("verification = %+v, want 3 claims, not regenerated", v)
+	}
+	if !v.Claims[0].Supported || v.Claims[1].Supported || v.Claims[2].Supported {
+		t.Errorf("support = %v %v %v, want true false false", v.Claims[0].Supported, v.Claims[1].Supported, v.Claims[2].Supported)
+	}
+	if v.Claims[1].Evidence != "" {
+		t.Errorf("unsupported claim has evidence %q", v.Claims[1].Evidence)
+	}
+	if math.Abs(v.Groundedness-1.0/3) > 1e-9 {
+		t.Errorf("groundedness = %v, want 1/3", v.Groundedness)
+	}
+
+	// Unsupported claims, then the regenerated answer, its claims and checks
+	client.responses = []string{
+		`{"claims": ["Ada was born in Paris"]}`,
+		`{"checks": [{"claim": 1, "supported": false}]}`,
+		"Ada wrote the first program.",
+		`{"claims": ["Ada wrote the first program"]}`,
+		`{"checks": [{"claim": 1, "supported": true}]}`,
+	}
+	resp = &ChatResponse{Answer: "Ada was born in Paris."}
+	svc.checkAnswer(context.Background(), "Who was Ada?", "Ada wrote the first program.", true, resp)
+	if resp.Answer != "Ada wrote the first program." || resp.Verification == nil || !resp.Verification.Regenerated || resp.Verification.Groundedness != 1 {
+		t.Errorf("regenerated answer %q with verification %+v", resp.Answer, resp.Verification)
+	}
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_documents_content_hash;
+DROP INDEX IF EXISTS idx_documents_notebook_content_hash;
+ALTER TABLE documents DROP COLUMN IF EXISTS content_hash;
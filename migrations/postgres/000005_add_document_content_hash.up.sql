@@ -0,0 +1,4 @@
+ALTER TABLE documents ADD COLUMN content_hash VARCHAR(64);
+
+CREATE INDEX idx_documents_notebook_content_hash ON documents(notebook_id, content_hash);
+CREATE INDEX idx_documents_content_hash ON documents(content_hash);
@@ -0,0 +1,4 @@
+ALTER TABLE documents ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_documents_deleted_at ON documents(deleted_at) WHERE is_deleted = true;
+CREATE INDEX idx_documents_file_path ON documents(file_path);
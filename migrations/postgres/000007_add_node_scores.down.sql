@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_nodes_document_pagerank;
+ALTER TABLE nodes DROP COLUMN IF EXISTS community_id;
+ALTER TABLE nodes DROP COLUMN IF EXISTS betweenness;
+ALTER TABLE nodes DROP COLUMN IF EXISTS degree_centrality;
+ALTER TABLE nodes DROP COLUMN IF EXISTS pagerank;
@@ -0,0 +1,6 @@
+ALTER TABLE nodes ADD COLUMN pagerank DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE nodes ADD COLUMN degree_centrality DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE nodes ADD COLUMN betweenness DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE nodes ADD COLUMN community_id BIGINT;
+
+CREATE INDEX idx_nodes_document_pagerank ON nodes(document_id, pagerank DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS community_nodes;
+DROP TABLE IF EXISTS communities;
@@ -0,0 +1,21 @@
+CREATE TABLE communities (
+    id BIGSERIAL PRIMARY KEY,
+    notebook_id BIGINT NOT NULL REFERENCES notebooks (id) ON DELETE CASCADE,
+    parent_id BIGINT REFERENCES communities (id) ON DELETE CASCADE,
+    level INT NOT NULL, -- 0 is the coarsest level
+    title TEXT NOT NULL,
+    summary TEXT NOT NULL,
+    rank DOUBLE PRECISION NOT NULL DEFAULT 0,
+    node_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_communities_notebook_level ON communities (notebook_id, level);
+
+CREATE TABLE community_nodes (
+    community_id BIGINT NOT NULL REFERENCES communities (id) ON DELETE CASCADE,
+    node_id BIGINT NOT NULL REFERENCES nodes (id) ON DELETE CASCADE,
+    PRIMARY KEY (community_id, node_id)
+);
+
+CREATE INDEX idx_community_nodes_node_id ON community_nodes (node_id);
@@ -0,0 +1,3 @@
+DELETE FROM community_nodes cn WHERE NOT EXISTS (SELECT 1 FROM nodes n WHERE n.id = cn.node_id);
+ALTER TABLE community_nodes ADD CONSTRAINT community_nodes_node_id_fkey
+    FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+-- Graph nodes may live outside Postgres (GRAPH_BACKEND=neo4j), so community
+-- membership can no longer reference the nodes table.
+ALTER TABLE community_nodes DROP CONSTRAINT IF EXISTS community_nodes_node_id_fkey;
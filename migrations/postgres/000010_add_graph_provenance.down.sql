@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS graph_edits;
+ALTER TABLE edges DROP COLUMN IF EXISTS source;
+ALTER TABLE nodes DROP COLUMN IF EXISTS source;
@@ -0,0 +1,19 @@
+-- Where each node and edge came from: llm extraction, a human edit or an import
+ALTER TABLE nodes ADD COLUMN source VARCHAR(16) NOT NULL DEFAULT 'llm';
+ALTER TABLE edges ADD COLUMN source VARCHAR(16) NOT NULL DEFAULT 'llm';
+
+-- Audit trail of manual graph edits. Entity IDs are not foreign keys: edits outlive
+-- the entities they deleted, and nodes may live in Neo4j.
+CREATE TABLE graph_edits (
+    id BIGSERIAL PRIMARY KEY,
+    document_id BIGINT NOT NULL REFERENCES documents (id) ON DELETE CASCADE,
+    entity_type VARCHAR(16) NOT NULL, -- node, edge
+    entity_id BIGINT NOT NULL,
+    action VARCHAR(16) NOT NULL, -- create, update, delete, merge, split
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_graph_edits_document_id ON graph_edits (document_id);
+CREATE INDEX idx_graph_edits_entity ON graph_edits (entity_type, entity_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_edges_properties;
+DROP INDEX IF EXISTS idx_nodes_properties;
+ALTER TABLE edges ALTER COLUMN properties DROP NOT NULL, ALTER COLUMN properties DROP DEFAULT;
+ALTER TABLE nodes ALTER COLUMN properties DROP NOT NULL, ALTER COLUMN properties DROP DEFAULT;
@@ -0,0 +1,9 @@
+-- Properties are always a JSON object
+UPDATE nodes SET properties = '{}' WHERE properties IS NULL OR jsonb_typeof(properties) <> 'object';
+UPDATE edges SET properties = '{}' WHERE properties IS NULL OR jsonb_typeof(properties) <> 'object';
+ALTER TABLE nodes ALTER COLUMN properties SET DEFAULT '{}', ALTER COLUMN properties SET NOT NULL;
+ALTER TABLE edges ALTER COLUMN properties SET DEFAULT '{}', ALTER COLUMN properties SET NOT NULL;
+
+-- Containment (@>) filtering on property values
+CREATE INDEX idx_nodes_properties ON nodes USING GIN (properties jsonb_path_ops);
+CREATE INDEX idx_edges_properties ON edges USING GIN (properties jsonb_path_ops);
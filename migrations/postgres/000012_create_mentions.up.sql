@@ -0,0 +1,18 @@
+-- Evidence linking nodes and edges to the chunks they were extracted from. Node and edge
+-- IDs are not foreign keys since the graph may live in Neo4j.
+CREATE TABLE mentions (
+    id BIGSERIAL PRIMARY KEY,
+    document_id BIGINT NOT NULL REFERENCES documents (id) ON DELETE CASCADE,
+    node_id BIGINT,
+    edge_id BIGINT,
+    chunk_id UUID NOT NULL REFERENCES chunks (id) ON DELETE CASCADE,
+    start_offset INT NOT NULL, -- in characters, within the chunk content
+    end_offset INT NOT NULL,
+    quote TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((node_id IS NULL) <> (edge_id IS NULL))
+);
+
+CREATE INDEX idx_mentions_document_id ON mentions (document_id);
+CREATE INDEX idx_mentions_node_id ON mentions (node_id) WHERE node_id IS NOT NULL;
+CREATE INDEX idx_mentions_edge_id ON mentions (edge_id) WHERE edge_id IS NOT NULL;
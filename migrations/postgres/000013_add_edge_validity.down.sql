@@ -0,0 +1,2 @@
+ALTER TABLE edges DROP COLUMN IF EXISTS valid_to;
+ALTER TABLE edges DROP COLUMN IF EXISTS valid_from;
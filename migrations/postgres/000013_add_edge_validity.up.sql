@@ -0,0 +1,3 @@
+-- Validity interval of a relation; NULL bounds are open. Both bounds are inclusive.
+ALTER TABLE edges ADD COLUMN valid_from DATE;
+ALTER TABLE edges ADD COLUMN valid_to DATE;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_chunks_content_tsv;
+ALTER TABLE chunks DROP COLUMN IF EXISTS content_tsv;
@@ -0,0 +1,6 @@
+-- Full-text search over chunk content. The simple configuration neither stems nor drops
+-- stop words, so names, identifiers and acronyms match exactly in any language.
+ALTER TABLE chunks ADD COLUMN content_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED;
+
+CREATE INDEX idx_chunks_content_tsv ON chunks USING GIN (content_tsv);
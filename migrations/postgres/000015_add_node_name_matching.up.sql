@@ -0,0 +1,6 @@
+-- Fuzzy matching of node names against questions, for linking the entities a question
+-- refers to. word_similarity() comes from pg_trgm.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Trigram index for fuzzy lookups of node names
+CREATE INDEX IF NOT EXISTS idx_nodes_name_trgm ON nodes USING gin (name gin_trgm_ops);
@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS chat_cache;
+DROP TRIGGER IF EXISTS communities_bump_notebook_version ON communities;
+DROP TRIGGER IF EXISTS graph_edits_bump_notebook_version ON graph_edits;
+DROP TRIGGER IF EXISTS edges_delete_bump_notebook_version ON edges;
+DROP TRIGGER IF EXISTS edges_update_bump_notebook_version ON edges;
+DROP TRIGGER IF EXISTS edges_insert_bump_notebook_version ON edges;
+DROP TRIGGER IF EXISTS nodes_delete_bump_notebook_version ON nodes;
+DROP TRIGGER IF EXISTS nodes_update_bump_notebook_version ON nodes;
+DROP TRIGGER IF EXISTS nodes_insert_bump_notebook_version ON nodes;
+DROP TRIGGER IF EXISTS documents_bump_notebook_version ON documents;
+DROP FUNCTION IF EXISTS bump_community_notebook_version();
+DROP FUNCTION IF EXISTS bump_edit_notebook_version();
+DROP FUNCTION IF EXISTS bump_graph_notebook_version();
+DROP FUNCTION IF EXISTS bump_document_notebook_version();
+DROP FUNCTION IF EXISTS bump_notebook_version(BIGINT);
+ALTER TABLE notebooks DROP COLUMN IF EXISTS content_version;
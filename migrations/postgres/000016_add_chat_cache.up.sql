@@ -0,0 +1,105 @@
+-- content_version changes whenever a notebook's documents, graph, graph edits or communities do, so
+-- cached chat answers can be keyed on the notebook content they were generated from.
+ALTER TABLE notebooks ADD COLUMN content_version BIGINT NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION bump_notebook_version(nb BIGINT) RETURNS VOID AS $$
+BEGIN
+    UPDATE notebooks SET content_version = content_version + 1 WHERE id = nb;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION bump_document_notebook_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.notebook_id IS NOT NULL THEN
+        PERFORM bump_notebook_version(OLD.notebook_id);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.notebook_id IS NOT NULL
+        AND (TG_OP = 'INSERT' OR NEW.notebook_id IS DISTINCT FROM OLD.notebook_id) THEN
+        PERFORM bump_notebook_version(NEW.notebook_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER documents_bump_notebook_version
+    AFTER INSERT OR UPDATE OR DELETE ON documents
+    FOR EACH ROW EXECUTE FUNCTION bump_document_notebook_version();
+
+-- Graph changes bump the versions of the notebooks of the changed rows' documents. Triggers
+-- are per statement, as extraction writes a document's graph in bulk.
+CREATE OR REPLACE FUNCTION bump_graph_notebook_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE notebooks SET content_version = content_version + 1
+        WHERE id IN (SELECT d.notebook_id FROM old_rows r JOIN documents d ON d.id = r.document_id);
+    ELSE
+        UPDATE notebooks SET content_version = content_version + 1
+        WHERE id IN (SELECT d.notebook_id FROM new_rows r JOIN documents d ON d.id = r.document_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER nodes_insert_bump_notebook_version
+    AFTER INSERT ON nodes REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_graph_notebook_version();
+CREATE TRIGGER nodes_update_bump_notebook_version
+    AFTER UPDATE ON nodes REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_graph_notebook_version();
+CREATE TRIGGER nodes_delete_bump_notebook_version
+    AFTER DELETE ON nodes REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_graph_notebook_version();
+CREATE TRIGGER edges_insert_bump_notebook_version
+    AFTER INSERT ON edges REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_graph_notebook_version();
+CREATE TRIGGER edges_update_bump_notebook_version
+    AFTER UPDATE ON edges REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_graph_notebook_version();
+CREATE TRIGGER edges_delete_bump_notebook_version
+    AFTER DELETE ON edges REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_graph_notebook_version();
+
+-- A graph kept in Neo4j only changes in Postgres through the curation recorded in graph_edits
+CREATE OR REPLACE FUNCTION bump_edit_notebook_version() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM bump_notebook_version(d.notebook_id) FROM documents d WHERE d.id = NEW.document_id AND d.notebook_id IS NOT NULL;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER graph_edits_bump_notebook_version
+    AFTER INSERT ON graph_edits
+    FOR EACH ROW EXECUTE FUNCTION bump_edit_notebook_version();
+
+CREATE OR REPLACE FUNCTION bump_community_notebook_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM bump_notebook_version(OLD.notebook_id);
+    ELSE
+        PERFORM bump_notebook_version(NEW.notebook_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER communities_bump_notebook_version
+    AFTER INSERT OR DELETE ON communities
+    FOR EACH ROW EXECUTE FUNCTION bump_community_notebook_version();
+
+CREATE TABLE chat_cache (
+    id BIGSERIAL PRIMARY KEY,
+    notebook_id BIGINT NOT NULL REFERENCES notebooks (id) ON DELETE CASCADE,
+    content_version BIGINT NOT NULL,
+    -- request_key holds the chat options other than the question; answers are only reused
+    -- for identical options
+    request_key TEXT NOT NULL,
+    query TEXT NOT NULL,
+    -- little-endian float32 query embedding
+    embedding BYTEA NOT NULL,
+    response JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_chat_cache_lookup ON chat_cache (notebook_id, content_version, request_key);
+CREATE INDEX idx_chat_cache_expires_at ON chat_cache (expires_at);
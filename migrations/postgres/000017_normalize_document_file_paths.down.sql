@@ -0,0 +1,2 @@
+-- The upload directory the old paths pointed into is not known here, so the keys are kept.
+SELECT 1;
@@ -0,0 +1,12 @@
+-- Documents uploaded before blob storage hold paths inside the upload directory instead of
+-- store keys: "<dir>/<timestamp>_<name>" and, for content-addressed uploads,
+-- "<dir>/ab/<hash><ext>". A LocalStore rooted at the upload directory keeps those files at the
+-- part of the path below the directory.
+UPDATE documents
+SET file_path = substring(file_path FROM '([0-9a-f]{2}/[0-9a-f]{64}[^/]*)$')
+WHERE file_path ~ '/[0-9a-f]{2}/[0-9a-f]{64}[^/]*$';
+
+UPDATE documents
+SET file_path = regexp_replace(file_path, '^.*/', '')
+WHERE file_path LIKE '%/%'
+  AND file_path !~ '^[0-9a-f]{2}/[0-9a-f]{64}[^/]*$';
@@ -0,0 +1,9 @@
+-- Community building runs in the background; the latest build of each notebook is tracked here
+CREATE TABLE community_builds (
+    notebook_id BIGINT PRIMARY KEY REFERENCES notebooks (id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    error_message TEXT,
+    communities INT NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
@@ -0,0 +1,13 @@
+-- Analytics over a notebook's whole graph. The scores on nodes are computed over each
+-- node's own document graph and are not comparable across documents.
+CREATE TABLE notebook_node_scores (
+    notebook_id BIGINT NOT NULL REFERENCES notebooks (id) ON DELETE CASCADE,
+    node_id BIGINT NOT NULL REFERENCES nodes (id) ON DELETE CASCADE,
+    pagerank DOUBLE PRECISION NOT NULL DEFAULT 0,
+    degree_centrality DOUBLE PRECISION NOT NULL DEFAULT 0,
+    betweenness DOUBLE PRECISION NOT NULL DEFAULT 0,
+    community_id BIGINT NOT NULL,
+    PRIMARY KEY (notebook_id, node_id)
+);
+
+CREATE INDEX idx_notebook_node_scores_node_id ON notebook_node_scores (node_id);
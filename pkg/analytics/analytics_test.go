@@ -0,0 +1,97 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPageRank_Star(t *testing.T) {
+	// Every leaf points to the hub
+	g := NewGraph([]int64{1, 2, 3, 4, 5})
+	for _, leaf := range []int64{2, 3, 4, 5} {
+		g.AddEdge(leaf, 1)
+	}
+
+	scores := PageRank(g, 0.85, 100, 1e-9)
+
+	sum := 0.0
+	for id, s := range scores {
+		sum += s
+		if id != 1 && s >= scores[1] {
+			t.Errorf("Expected hub to outrank node %d: %f >= %f", id, s, scores[1])
+		}
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		t.Errorf("Expected scores to sum to 1, got %f", sum)
+	}
+}
+
+func TestDegreeCentrality(t *testing.T) {
+	g := NewGraph([]int64{1, 2, 3})
+	g.AddEdge(1, 2)
+	g.AddEdge(1, 3)
+
+	scores := DegreeCentrality(g)
+	if scores[1] != 1 || scores[2] != 0.5 || scores[3] != 0.5 {
+		t.Errorf("Unexpected degree centrality: %v", scores)
+	}
+}
+
+func TestBetweennessCentrality_Path(t *testing.T) {
+	// 1 - 2 - 3: every shortest path between 1 and 3 passes through 2
+	g := NewGraph([]int64{1, 2, 3})
+	g.AddEdge(1, 2)
+	g.AddEdge(2, 3)
+
+	scores := BetweennessCentrality(g)
+	if math.Abs(scores[2]-1) > 1e-9 {
+		t.Errorf("Expected betweenness 1 for middle node, got %f", scores[2])
+	}
+	if scores[1] != 0 || scores[3] != 0 {
+		t.Errorf("Expected betweenness 0 for endpoints, got %v", scores)
+	}
+}
+
+func TestLouvain_TwoCliques(t *testing.T) {
+	// Two 4-cliques joined by a single bridge 4 - 5
+	g := NewGraph([]int64{1, 2, 3, 4, 5, 6, 7, 8})
+	clique := func(ids ...int64) {
+		for i := range ids {
+			for j := i + 1; j < len(ids); j++ {
+				g.AddEdge(ids[i], ids[j])
+			}
+		}
+	}
+	clique(1, 2, 3, 4)
+	clique(5, 6, 7, 8)
+	g.AddEdge(4, 5)
+
+	levels := Louvain(g)
+	if len(levels) == 0 {
+		t.Fatal("Expected at least one level")
+	}
+	top := levels[len(levels)-1]
+
+	for _, id := range []int64{2, 3, 4} {
+		if top[id] != top[1] {
+			t.Errorf("Expected node %d in the community of node 1", id)
+		}
+	}
+	for _, id := range []int64{6, 7, 8} {
+		if top[id] != top[5] {
+			t.Errorf("Expected node %d in the community of node 5", id)
+		}
+	}
+	if top[1] == top[5] {
+		t.Error("Expected the cliques in different communities")
+	}
+}
+
+func TestLouvain_NoEdges(t *testing.T) {
+	g := NewGraph([]int64{1, 2})
+
+	levels := Louvain(g)
+	if len(levels) != 1 || levels[0][1] == levels[0][2] {
+		t.Errorf("Expected a single level of singletons, got %v", levels)
+	}
+}
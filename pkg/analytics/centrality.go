@@ -0,0 +1,131 @@
+package analytics
+
+import "math"
+
+// PageRank computes PageRank scores with the given damping factor, iterating until the
+// total change drops below tol or maxIter is reached. Scores sum to 1. Dangling nodes
+// distribute their rank uniformly.
+func PageRank(g *Graph, damping float64, maxIter int, tol float64) map[int64]float64 {
+	n := g.NodeCount()
+	if n == 0 {
+		return map[int64]float64{}
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+
+	next := make([]float64, n)
+	for iter := 0; iter < maxIter; iter++ {
+		dangling := 0.0
+		for u := range rank {
+			if len(g.out[u]) == 0 {
+				dangling += rank[u]
+			}
+		}
+
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for u, targets := range g.out {
+			if len(targets) == 0 {
+				continue
+			}
+			share := damping * rank[u] / float64(len(targets))
+			for _, v := range targets {
+				next[v] += share
+			}
+		}
+
+		diff := 0.0
+		for i := range rank {
+			diff += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if diff < tol {
+			break
+		}
+	}
+
+	return g.byID(rank)
+}
+
+// DegreeCentrality returns each node's number of incident edges, in either direction,
+// divided by n-1.
+func DegreeCentrality(g *Graph) map[int64]float64 {
+	n := g.NodeCount()
+	scores := make([]float64, n)
+	if n < 2 {
+		return g.byID(scores)
+	}
+	for u := range scores {
+		scores[u] = float64(len(g.out[u])+len(g.in[u])) / float64(n-1)
+	}
+	return g.byID(scores)
+}
+
+// BetweennessCentrality computes normalized betweenness on the undirected graph using
+// Brandes' algorithm: the share of shortest paths between other node pairs passing
+// through each node.
+func BetweennessCentrality(g *Graph) map[int64]float64 {
+	n := g.NodeCount()
+	cb := make([]float64, n)
+	adj := g.undirected()
+
+	stack := make([]int, 0, n)
+	queue := make([]int, 0, n)
+	pred := make([][]int, n)
+	sigma := make([]float64, n)
+	dist := make([]int, n)
+	delta := make([]float64, n)
+
+	for s := 0; s < n; s++ {
+		stack = stack[:0]
+		queue = append(queue[:0], s)
+		for i := 0; i < n; i++ {
+			pred[i] = pred[i][:0]
+			sigma[i] = 0
+			dist[i] = -1
+			delta[i] = 0
+		}
+		sigma[s] = 1
+		dist[s] = 0
+
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+			for _, w := range adj[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					pred[w] = append(pred[w], v)
+				}
+			}
+		}
+
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+			for _, v := range pred[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				cb[w] += delta[w]
+			}
+		}
+	}
+
+	// Each undirected pair was counted from both ends
+	if n > 2 {
+		norm := float64((n - 1) * (n - 2))
+		for i := range cb {
+			cb[i] /= norm
+		}
+	}
+	return g.byID(cb)
+}
@@ -0,0 +1,166 @@
+package analytics
+
+import "sort"
+
+// Louvain detects communities by greedy modularity optimization on the undirected graph.
+// It returns one partition per level of the hierarchy, from the finest to the coarsest;
+// each partition maps every node ID to a community number in [0, k). Parallel edges add
+// weight. Node order makes the result deterministic.
+func Louvain(g *Graph) []map[int64]int {
+	n := g.NodeCount()
+
+	// Level graph: weighted adjacency without self-loops, plus self-loop weights
+	adj := make([]map[int]float64, n)
+	loop := make([]float64, n)
+	for u := range adj {
+		adj[u] = make(map[int]float64)
+	}
+	for u, targets := range g.out {
+		for _, v := range targets {
+			if u == v {
+				loop[u]++
+				continue
+			}
+			adj[u][v]++
+			adj[v][u]++
+		}
+	}
+
+	// member[i] is the level-graph node that original node i currently belongs to
+	member := make([]int, n)
+	for i := range member {
+		member[i] = i
+	}
+
+	var levels []map[int64]int
+	for {
+		community, moved := louvainPhase(adj, loop)
+		k := renumber(community)
+		if !moved || k == len(adj) {
+			break
+		}
+
+		for i := range member {
+			member[i] = community[member[i]]
+		}
+		levels = append(levels, g.partition(member))
+
+		adj, loop = aggregate(adj, loop, community, k)
+	}
+
+	if len(levels) == 0 {
+		// No merge improved modularity: every node is its own community
+		levels = append(levels, g.partition(member))
+	}
+	return levels
+}
+
+// louvainPhase moves nodes between communities while modularity improves.
+// It returns the community of every node and whether any node moved.
+func louvainPhase(adj []map[int]float64, loop []float64) ([]int, bool) {
+	n := len(adj)
+	community := make([]int, n)
+	degree := make([]float64, n)
+	total := make([]float64, n) // sum of degrees per community
+
+	// Neighbors in index order rather than map order keep the result deterministic
+	neighbors := make([][]int, n)
+	for u := range adj {
+		for v := range adj[u] {
+			neighbors[u] = append(neighbors[u], v)
+		}
+		sort.Ints(neighbors[u])
+	}
+
+	m2 := 0.0
+	for u := range adj {
+		community[u] = u
+		for _, w := range adj[u] {
+			degree[u] += w
+		}
+		degree[u] += 2 * loop[u]
+		total[u] = degree[u]
+		m2 += degree[u]
+	}
+	if m2 == 0 {
+		return community, false
+	}
+
+	moved := false
+	for improved := true; improved; {
+		improved = false
+		for u := 0; u < n; u++ {
+			// Weight from u to each neighboring community, in first-seen order for determinism
+			links := make(map[int]float64)
+			var order []int
+			for _, v := range neighbors[u] {
+				c := community[v]
+				if _, seen := links[c]; !seen {
+					order = append(order, c)
+				}
+				links[c] += adj[u][v]
+			}
+
+			old := community[u]
+			total[old] -= degree[u]
+
+			best, bestGain := old, links[old]-total[old]*degree[u]/m2
+			for _, c := range order {
+				if gain := links[c] - total[c]*degree[u]/m2; gain > bestGain+1e-12 {
+					best, bestGain = c, gain
+				}
+			}
+
+			community[u] = best
+			total[best] += degree[u]
+			if best != old {
+				moved, improved = true, true
+			}
+		}
+	}
+	return community, moved
+}
+
+// aggregate builds the next level graph with one node per community.
+func aggregate(adj []map[int]float64, loop []float64, community []int, k int) ([]map[int]float64, []float64) {
+	nextAdj := make([]map[int]float64, k)
+	nextLoop := make([]float64, k)
+	for c := range nextAdj {
+		nextAdj[c] = make(map[int]float64)
+	}
+	for u := range adj {
+		cu := community[u]
+		nextLoop[cu] += loop[u]
+		for v, w := range adj[u] {
+			cv := community[v]
+			if cu == cv {
+				nextLoop[cu] += w / 2 // every internal edge is visited from both ends
+			} else {
+				nextAdj[cu][cv] += w
+			}
+		}
+	}
+	return nextAdj, nextLoop
+}
+
+// renumber maps community labels to 0..k-1 in order of first appearance and returns k.
+func renumber(community []int) int {
+	ids := make(map[int]int)
+	for i, c := range community {
+		id, ok := ids[c]
+		if !ok {
+			id = len(ids)
+			ids[c] = id
+		}
+		community[i] = id
+	}
+	return len(ids)
+}
+
+func (g *Graph) partition(member []int) map[int64]int {
+	p := make(map[int64]int, len(member))
+	for i, c := range member {
+		p[g.ids[i]] = c
+	}
+	return p
+}
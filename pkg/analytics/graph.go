@@ -0,0 +1,70 @@
+// Package analytics implements graph algorithms for ranking entities and detecting communities.
+package analytics
+
+// Graph is a directed multigraph over int64 node IDs.
+// Undirected algorithms treat every edge as connecting its endpoints in both directions.
+type Graph struct {
+	ids   []int64
+	index map[int64]int
+	out   [][]int
+	in    [][]int
+}
+
+// NewGraph creates a graph containing the given nodes and no edges.
+func NewGraph(nodeIDs []int64) *Graph {
+	g := &Graph{
+		ids:   make([]int64, 0, len(nodeIDs)),
+		index: make(map[int64]int, len(nodeIDs)),
+	}
+	for _, id := range nodeIDs {
+		g.addNode(id)
+	}
+	return g
+}
+
+func (g *Graph) addNode(id int64) int {
+	if i, ok := g.index[id]; ok {
+		return i
+	}
+	i := len(g.ids)
+	g.ids = append(g.ids, id)
+	g.index[id] = i
+	g.out = append(g.out, nil)
+	g.in = append(g.in, nil)
+	return i
+}
+
+// AddEdge adds a directed edge, adding unknown endpoints as nodes.
+func (g *Graph) AddEdge(from, to int64) {
+	f, t := g.addNode(from), g.addNode(to)
+	g.out[f] = append(g.out[f], t)
+	g.in[t] = append(g.in[t], f)
+}
+
+// NodeCount returns the number of nodes.
+func (g *Graph) NodeCount() int {
+	return len(g.ids)
+}
+
+// undirected returns the adjacency lists ignoring direction. Parallel edges are kept.
+func (g *Graph) undirected() [][]int {
+	adj := make([][]int, len(g.ids))
+	for u, targets := range g.out {
+		for _, v := range targets {
+			adj[u] = append(adj[u], v)
+			if u != v {
+				adj[v] = append(adj[v], u)
+			}
+		}
+	}
+	return adj
+}
+
+// byID converts a per-index slice into a map keyed by node ID.
+func (g *Graph) byID(values []float64) map[int64]float64 {
+	m := make(map[int64]float64, len(values))
+	for i, v := range values {
+		m[g.ids[i]] = v
+	}
+	return m
+}
@@ -2,6 +2,7 @@ package embedding
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/google/generative-ai-go/genai"
 	"google.golang.org/api/option"
@@ -14,6 +15,9 @@ type Client interface {
 	Close() error
 }
 
+// maxBatchSize is the most texts Gemini embeds in one batchEmbedContents request.
+const maxBatchSize = 100
+
 // GeminiClient implements Client using Google Gemini API
 type GeminiClient struct {
 	client *genai.Client
@@ -43,8 +47,13 @@ func (c *GeminiClient) EmbedText(ctx context.Context, text string) ([]float32, e
 	return res.Embedding.Values, nil
 }
 
-// EmbedBatch generates embeddings for multiple text strings
+// EmbedBatch generates embeddings for multiple text strings, in requests of at most
+// maxBatchSize texts
 func (c *GeminiClient) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	return embedInBatches(ctx, texts, maxBatchSize, c.embedBatch)
+}
+
+func (c *GeminiClient) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
 	batch := c.model.NewBatch()
 	for _, text := range texts {
 		batch.AddContent(genai.Text(text))
@@ -62,6 +71,23 @@ func (c *GeminiClient) EmbedBatch(ctx context.Context, texts []string) ([][]floa
 	return embeddings, nil
 }
 
+// embedInBatches embeds texts with embed, at most size texts per call.
+func embedInBatches(ctx context.Context, texts []string, size int, embed func(context.Context, []string) ([][]float32, error)) ([][]float32, error) {
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += size {
+		batch := texts[start:min(start+size, len(texts))]
+		res, err := embed(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed texts %d to %d: %w", start, start+len(batch)-1, err)
+		}
+		if len(res) != len(batch) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(batch), len(res))
+		}
+		embeddings = append(embeddings, res...)
+	}
+	return embeddings, nil
+}
+
 // Close closes the underlying client
 func (c *GeminiClient) Close() error {
 	return c.client.Close()
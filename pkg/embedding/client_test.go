@@ -2,6 +2,7 @@ package embedding
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"testing"
 )
@@ -51,3 +52,33 @@ func TestEmbedText_Integration(t *testing.T) {
 		t.Logf("Warning: Expected 768 dimensions, got %d", len(embedding))
 	}
 }
+
+func TestEmbedInBatches(t *testing.T) {
+	texts := make([]string, 250)
+	for i := range texts {
+		texts[i] = fmt.Sprint(i)
+	}
+
+	var sizes []int
+	embed := func(ctx context.Context, batch []string) ([][]float32, error) {
+		sizes = append(sizes, len(batch))
+		res := make([][]float32, len(batch))
+		for i, text := range batch {
+			var n float32
+			fmt.Sscan(text, &n)
+			res[i] = []float32{n}
+		}
+		return res, nil
+	}
+
+	embeddings, err := embedInBatches(context.Background(), texts, maxBatchSize, embed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 3 || sizes[0] != 100 || sizes[1] != 100 || sizes[2] != 50 {
+		t.Errorf("expected batches of 100, 100 and 50, got %v", sizes)
+	}
+	if len(embeddings) != len(texts) || embeddings[249][0] != 249 {
+		t.Errorf("expected embeddings in input order, got %d ending with %v", len(embeddings), embeddings[len(embeddings)-1])
+	}
+}
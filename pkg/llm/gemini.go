@@ -11,13 +11,17 @@ import (
 // Client defines the interface for LLM interactions.
 type Client interface {
 	GenerateContent(ctx context.Context, prompt string) (string, error)
+	// GenerateWithTools continues a conversation in which the model may call the given tools.
+	// The last message must be from the user or return tool results.
+	GenerateWithTools(ctx context.Context, messages []Message, tools []Tool) (*ToolResponse, error)
 	Close() error
 }
 
 // GeminiClient implements Client using Google's Gemini API.
 type GeminiClient struct {
-	client *genai.Client
-	model  *genai.GenerativeModel
+	client    *genai.Client
+	model     *genai.GenerativeModel
+	modelName string
 }
 
 // NewGeminiClient creates a new GeminiClient.
@@ -37,8 +41,9 @@ func NewGeminiClient(ctx context.Context, apiKey string, modelName string) (*Gem
 	model := client.GenerativeModel(modelName)
 
 	return &GeminiClient{
-		client: client,
-		model:  model,
+		client:    client,
+		model:     model,
+		modelName: modelName,
 	}, nil
 }
 
@@ -64,6 +69,96 @@ func (c *GeminiClient) GenerateContent(ctx context.Context, prompt string) (stri
 	return result, nil
 }
 
+// GenerateWithTools sends the conversation to a model configured with the tools. Tool
+// results are sent as function responses in a user turn, as the Gemini API expects.
+func (c *GeminiClient) GenerateWithTools(ctx context.Context, messages []Message, tools []Tool) (*ToolResponse, error) {
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages to send")
+	}
+
+	// A model per call: the tools are part of the model configuration
+	model := c.client.GenerativeModel(c.modelName)
+	if len(tools) > 0 {
+		declarations := make([]*genai.FunctionDeclaration, len(tools))
+		for i, t := range tools {
+			declarations[i] = functionDeclaration(t)
+		}
+		model.Tools = []*genai.Tool{{FunctionDeclarations: declarations}}
+	}
+
+	session := model.StartChat()
+	for _, m := range messages[:len(messages)-1] {
+		session.History = append(session.History, geminiContent(m))
+	}
+	resp, err := session.SendMessage(ctx, geminiContent(messages[len(messages)-1]).Parts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return nil, fmt.Errorf("no content generated")
+	}
+
+	result := &ToolResponse{}
+	for _, part := range resp.Candidates[0].Content.Parts {
+		switch p := part.(type) {
+		case genai.Text:
+			result.Text += string(p)
+		case genai.FunctionCall:
+			result.ToolCalls = append(result.ToolCalls, ToolCall{Name: p.Name, Args: p.Args})
+		}
+	}
+	if result.Text == "" && len(result.ToolCalls) == 0 {
+		return nil, fmt.Errorf("no content generated")
+	}
+	return result, nil
+}
+
+// functionDeclaration converts a tool to a Gemini function declaration.
+func functionDeclaration(t Tool) *genai.FunctionDeclaration {
+	properties := make(map[string]*genai.Schema, len(t.Parameters))
+	for name, p := range t.Parameters {
+		schema := &genai.Schema{Description: p.Description}
+		switch p.Type {
+		case TypeInteger:
+			schema.Type = genai.TypeInteger
+		case TypeNumber:
+			schema.Type = genai.TypeNumber
+		case TypeBoolean:
+			schema.Type = genai.TypeBoolean
+		default:
+			schema.Type = genai.TypeString
+		}
+		properties[name] = schema
+	}
+	return &genai.FunctionDeclaration{
+		Name:        t.Name,
+		Description: t.Description,
+		Parameters: &genai.Schema{
+			Type:       genai.TypeObject,
+			Properties: properties,
+			Required:   t.Required,
+		},
+	}
+}
+
+// geminiContent converts a message to Gemini content.
+func geminiContent(m Message) *genai.Content {
+	content := &genai.Content{Role: RoleUser}
+	if m.Role == RoleModel {
+		content.Role = RoleModel
+	}
+	if m.Text != "" {
+		content.Parts = append(content.Parts, genai.Text(m.Text))
+	}
+	for _, call := range m.ToolCalls {
+		content.Parts = append(content.Parts, genai.FunctionCall{Name: call.Name, Args: call.Args})
+	}
+	for _, r := range m.ToolResults {
+		content.Parts = append(content.Parts, genai.FunctionResponse{Name: r.Name, Response: r.Result})
+	}
+	return content
+}
+
 // Close closes the underlying client.
 func (c *GeminiClient) Close() error {
 	return c.client.Close()
@@ -0,0 +1,60 @@
+package llm
+
+// Roles of the messages in a tool-calling conversation.
+const (
+	RoleUser  = "user"
+	RoleModel = "model"
+	// RoleTool messages return the results of the model's tool calls.
+	RoleTool = "tool"
+)
+
+// Parameter types of a tool, as in JSON Schema.
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
+// Tool is a function the model may ask to call.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]Parameter
+	Required    []string
+}
+
+// Parameter describes one argument of a tool.
+type Parameter struct {
+	Type        string
+	Description string
+}
+
+// ToolCall is the model's request to call a tool. Numbers in Args are float64, as decoded
+// from JSON.
+type ToolCall struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+}
+
+// ToolResult is the outcome of a tool call, returned to the model.
+type ToolResult struct {
+	Name   string         `json:"name"`
+	Result map[string]any `json:"result"`
+}
+
+// Message is one turn of a tool-calling conversation: user text, model text and tool calls,
+// or the results of those calls.
+type Message struct {
+	Role        string
+	Text        string
+	ToolCalls   []ToolCall
+	ToolResults []ToolResult
+}
+
+// ToolResponse is the model's next turn: tool calls to run, or the final text when there
+// are none.
+type ToolResponse struct {
+	Text      string
+	ToolCalls []ToolCall
+}
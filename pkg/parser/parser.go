@@ -3,6 +3,7 @@ package parser
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,34 +14,50 @@ import (
 // ParseFile extracts text content from a file based on its extension.
 // Supports .pdf and .md (and .txt).
 func ParseFile(filePath string) (string, error) {
-	ext := strings.ToLower(filepath.Ext(filePath))
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	return Parse(f, filePath)
+}
+
+// Parse extracts text content from r, using the extension of name to pick the format.
+func Parse(r io.Reader, name string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(name))
 
 	switch ext {
 	case ".pdf":
-		return parsePDF(filePath)
+		return parsePDF(r)
 	case ".md", ".txt":
-		return parseText(filePath)
+		return parseText(r)
 	default:
 		return "", fmt.Errorf("unsupported file extension: %s", ext)
 	}
 }
 
-func parseText(filePath string) (string, error) {
-	content, err := os.ReadFile(filePath)
+func parseText(r io.Reader) (string, error) {
+	content, err := io.ReadAll(r)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file: %w", err)
 	}
 	return string(content), nil
 }
 
-func parsePDF(filePath string) (string, error) {
-	f, r, err := pdf.Open(filePath)
+func parsePDF(r io.Reader) (string, error) {
+	// The pdf reader needs random access, so buffer the whole file
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pdf: %w", err)
+	}
+
+	pr, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
 	if err != nil {
 		return "", fmt.Errorf("failed to open pdf: %w", err)
 	}
-	defer f.Close()
 
-	b, err := r.GetPlainText()
+	b, err := pr.GetPlainText()
 	if err != nil {
 		return "", fmt.Errorf("failed to get plain text from pdf: %w", err)
 	}
@@ -0,0 +1,72 @@
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HTTPReranker calls a rerank server speaking the Text Embeddings Inference API, such as TEI
+// serving a cross-encoder model.
+type HTTPReranker struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPReranker creates a reranker for the server at baseURL, e.g. http://localhost:8080.
+func NewHTTPReranker(baseURL string) *HTTPReranker {
+	return &HTTPReranker{
+		url:    strings.TrimSuffix(baseURL, "/") + "/rerank",
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type httpRerankRequest struct {
+	Query    string   `json:"query"`
+	Texts    []string `json:"texts"`
+	Truncate bool     `json:"truncate"`
+}
+
+// Rerank posts the documents to the server's /rerank endpoint.
+func (r *HTTPReranker) Rerank(ctx context.Context, query string, documents []string) ([]Result, error) {
+	if len(documents) == 0 {
+		return []Result{}, nil
+	}
+	body, err := json.Marshal(httpRerankRequest{Query: query, Texts: documents, Truncate: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rerank request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rerank request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call rerank server: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("rerank server returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var results []Result
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode rerank response: %w", err)
+	}
+	for _, res := range results {
+		if res.Index < 0 || res.Index >= len(documents) {
+			return nil, fmt.Errorf("rerank server returned invalid index %d", res.Index)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results, nil
+}
@@ -0,0 +1,60 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Ensure the rerankers implement Reranker
+var (
+	_ Reranker = (*HTTPReranker)(nil)
+	_ Reranker = (*LLMReranker)(nil)
+)
+
+func TestHTTPReranker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rerank" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req httpRerankRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Query != "who founded acme" || len(req.Texts) != 3 {
+			t.Errorf("unexpected request body %+v", req)
+		}
+		// TEI returns results sorted, but the order is not relied on
+		_, _ = w.Write([]byte(`[{"index":0,"score":0.1},{"index":2,"score":0.9},{"index":1,"score":0.5}]`))
+	}))
+	defer server.Close()
+
+	results, err := NewHTTPReranker(server.URL+"/").Rerank(context.Background(), "who founded acme", []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+	if len(results) != 3 || results[0].Index != 2 || results[1].Index != 1 || results[2].Index != 0 {
+		t.Errorf("expected results ordered by score, got %+v", results)
+	}
+}
+
+func TestHTTPReranker_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("bad") != "" {
+			_, _ = w.Write([]byte(`[{"index":5,"score":1}]`))
+			return
+		}
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if _, err := NewHTTPReranker(server.URL).Rerank(context.Background(), "q", []string{"a"}); err == nil {
+		t.Error("expected an error for a failed request")
+	}
+	r := &HTTPReranker{url: server.URL + "/rerank?bad=1", client: http.DefaultClient}
+	if _, err := r.Rerank(context.Background(), "q", []string{"a"}); err == nil {
+		t.Error("expected an error for an out-of-range index")
+	}
+}
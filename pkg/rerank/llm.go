@@ -0,0 +1,97 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/suyw-0123/graphweaver/pkg/llm"
+)
+
+// maxPassageLength bounds each passage in the prompt, in bytes.
+const maxPassageLength = 1500
+
+// LLMReranker ranks documents listwise: the LLM sees all candidates at once and returns
+// their order, which needs no dedicated model but costs one generation per query.
+type LLMReranker struct {
+	client llm.Client
+}
+
+// NewLLMReranker creates a reranker backed by an LLM.
+func NewLLMReranker(client llm.Client) *LLMReranker {
+	return &LLMReranker{client: client}
+}
+
+// Rerank scores documents by their position in the LLM's ranking, from 1 for the first down
+// towards 0. Documents the LLM leaves out follow in their input order.
+func (r *LLMReranker) Rerank(ctx context.Context, query string, documents []string) ([]Result, error) {
+	if len(documents) == 0 {
+		return []Result{}, nil
+	}
+
+	var sb strings.Builder
+	for i, doc := range documents {
+		fmt.Fprintf(&sb, "[%d] %s\n\n", i, truncate(strings.Join(strings.Fields(doc), " "), maxPassageLength))
+	}
+	prompt := fmt.Sprintf(`You are ranking search results.
+Order the passages below by how well they help answer the Question, most relevant first.
+Return ONLY a valid JSON object with the following structure:
+{"ranking": [3, 0, 1]}
+where the numbers are passage numbers. Leave out passages that are irrelevant.
+
+Question: %s
+
+Passages:
+%s`, query, sb.String())
+
+	response, err := r.client.GenerateContent(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rerank: %w", err)
+	}
+	var parsed struct {
+		Ranking []int `json:"ranking"`
+	}
+	if err := json.Unmarshal([]byte(trimCodeFence(response)), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse ranking: %w", err)
+	}
+
+	results := make([]Result, 0, len(documents))
+	ranked := make(map[int]bool, len(documents))
+	for _, i := range parsed.Ranking {
+		if i >= 0 && i < len(documents) && !ranked[i] {
+			ranked[i] = true
+			results = append(results, Result{Index: i})
+		}
+	}
+	for i := range documents {
+		if !ranked[i] {
+			results = append(results, Result{Index: i})
+		}
+	}
+	for pos := range results {
+		results[pos].Score = 1 - float64(pos)/float64(len(results))
+	}
+	return results, nil
+}
+
+// truncate shortens s to at most n bytes without splitting a character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
+
+// trimCodeFence removes the markdown code fences an LLM may wrap JSON output in.
+func trimCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "```json")
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimSuffix(s, "```")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,43 @@
+package rerank
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/suyw-0123/graphweaver/pkg/llm"
+)
+
+type stubLLM struct{ response string }
+
+func (s stubLLM) GenerateContent(ctx context.Context, prompt string) (string, error) {
+	return s.response, nil
+}
+
+func (s stubLLM) GenerateWithTools(ctx context.Context, messages []llm.Message, tools []llm.Tool) (*llm.ToolResponse, error) {
+	return nil, errors.New("tools not supported")
+}
+
+func (s stubLLM) Close() error { return nil }
+
+func TestLLMReranker(t *testing.T) {
+	r := NewLLMReranker(stubLLM{response: "```json\n{\"ranking\": [2, 7, 0, 2]}\n```"})
+	results, err := r.Rerank(context.Background(), "q", []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+
+	// Out-of-range and repeated numbers are ignored; unranked passages come last
+	want := []int{2, 0, 1}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), results)
+	}
+	for i, idx := range want {
+		if results[i].Index != idx {
+			t.Fatalf("expected order %v, got %+v", want, results)
+		}
+	}
+	if results[0].Score <= results[1].Score || results[1].Score <= results[2].Score {
+		t.Errorf("expected decreasing scores, got %+v", results)
+	}
+}
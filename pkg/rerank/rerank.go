@@ -0,0 +1,15 @@
+package rerank
+
+import "context"
+
+// Result is the relevance of one document to a query.
+type Result struct {
+	Index int     `json:"index"` // position of the document in the input
+	Score float64 `json:"score"` // higher is more relevant; scales differ between rerankers
+}
+
+// Reranker orders candidate documents by their relevance to a query.
+type Reranker interface {
+	// Rerank returns a result for every document, most relevant first.
+	Rerank(ctx context.Context, query string, documents []string) ([]Result, error)
+}
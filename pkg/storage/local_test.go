@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Ensure implementations satisfy BlobStore
+var (
+	_ BlobStore = (*LocalStore)(nil)
+	_ BlobStore = (*S3Store)(nil)
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+
+	key := "ab/abcdef.txt"
+	content := "hello world"
+
+	if err := store.Put(ctx, key, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	info, err := store.Stat(ctx, key)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Size != int64(len(content)) {
+		t.Errorf("Expected size %d, got %d", len(content), info.Size)
+	}
+
+	r, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	got, _ := io.ReadAll(r)
+	r.Close()
+	if string(got) != content {
+		t.Errorf("Expected %q, got %q", content, got)
+	}
+
+	objects, err := store.List(ctx, "ab/")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(objects) != 1 || objects[0].Key != key {
+		t.Errorf("Expected [%s], got %v", key, objects)
+	}
+
+	if _, err := store.PresignedURL(ctx, key, "a.txt", 0); !errors.Is(err, ErrPresignNotSupported) {
+		t.Errorf("Expected ErrPresignNotSupported, got %v", err)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Stat(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Deleting a missing object should succeed, got %v", err)
+	}
+}
+
+func TestLocalStore_RejectsEscapingKeys(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+
+	for _, key := range []string{"", "/", `..\secret`} {
+		if _, err := store.Stat(context.Background(), key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected invalid key error for %q, got %v", key, err)
+		}
+	}
+
+	// "../" segments are cleaned and stay inside the root
+	p, err := store.path("../../etc/passwd")
+	if err != nil {
+		t.Fatalf("path failed: %v", err)
+	}
+	if !strings.HasPrefix(p, store.root) {
+		t.Errorf("Expected path inside root, got %s", p)
+	}
+}
@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config holds the connection settings for an S3-compatible service.
+type S3Config struct {
+	Endpoint        string // host[:port], e.g. "localhost:9000" or "s3.amazonaws.com"
+	AccessKeyID     string
+	SecretAccessKey string
+	Bucket          string
+	Region          string
+	UseSSL          bool
+	// PublicEndpoint is the host[:port] browsers reach the service at, when it differs from
+	// Endpoint, e.g. "localhost:9000" for the "minio:9000" of docker-compose. Presigned URLs
+	// are signed for it.
+	PublicEndpoint string
+	// PublicUseSSL selects https for PublicEndpoint
+	PublicUseSSL bool
+}
+
+// S3Store implements BlobStore on S3-compatible object storage (AWS S3, MinIO, ...).
+type S3Store struct {
+	client *minio.Client
+	// presignClient signs download URLs; it is client unless a public endpoint is configured
+	presignClient *minio.Client
+	bucket        string
+}
+
+// NewS3Store creates an S3Store and ensures the bucket exists.
+func NewS3Store(ctx context.Context, cfg S3Config) (*S3Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket: %w", err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("failed to create bucket: %w", err)
+		}
+	}
+
+	presignClient := client
+	if cfg.PublicEndpoint != "" && cfg.PublicEndpoint != cfg.Endpoint {
+		// Signing is offline, but a client without a region looks the bucket location up
+		// through the endpoint, which the server may not be able to reach
+		region := cfg.Region
+		if region == "" {
+			region, err = client.GetBucketLocation(ctx, cfg.Bucket)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get bucket location: %w", err)
+			}
+		}
+		presignClient, err = minio.New(cfg.PublicEndpoint, &minio.Options{
+			Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+			Secure: cfg.PublicUseSSL,
+			Region: region,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create s3 presign client: %w", err)
+		}
+	}
+
+	return &S3Store{client: client, presignClient: presignClient, bucket: cfg.Bucket}, nil
+}
+
+// Put uploads the object. A negative size streams the upload in parts.
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to put object: %w", err)
+	}
+	return nil
+}
+
+// Get opens the object for reading.
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+	// GetObject is lazy; Stat surfaces a missing key before the caller starts reading.
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, s.wrapErr("failed to get object", err)
+	}
+	return obj, nil
+}
+
+// Delete removes the object.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// Stat returns object metadata.
+func (s *S3Store) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, s.wrapErr("failed to stat object", err)
+	}
+	return &ObjectInfo{
+		Key:          key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+	}, nil
+}
+
+// PresignedURL returns a presigned GET URL for the object that downloads it as filename.
+func (s *S3Store) PresignedURL(ctx context.Context, key, filename string, expiry time.Duration) (string, error) {
+	params := url.Values{}
+	if filename != "" {
+		params.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	u, err := s.presignClient.PresignedGetObject(ctx, s.bucket, key, expiry, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign url: %w", err)
+	}
+	return u.String(), nil
+}
+
+// List returns all objects under prefix.
+func (s *S3Store) List(ctx context.Context, prefix string) ([]*ObjectInfo, error) {
+	objects := []*ObjectInfo{}
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", obj.Err)
+		}
+		objects = append(objects, &ObjectInfo{
+			Key:          obj.Key,
+			Size:         obj.Size,
+			ContentType:  obj.ContentType,
+			LastModified: obj.LastModified,
+		})
+	}
+	return objects, nil
+}
+
+func (s *S3Store) wrapErr(msg string, err error) error {
+	resp := minio.ToErrorResponse(err)
+	if resp.Code == "NoSuchKey" || resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
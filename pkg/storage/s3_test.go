@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestS3Store_Integration(t *testing.T) {
+	// Runs against a local MinIO (docker-compose up minio) unless S3_ENDPOINT points elsewhere.
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "localhost:9000"
+	}
+	accessKey := os.Getenv("S3_ACCESS_KEY_ID")
+	if accessKey == "" {
+		accessKey = "graphweaver"
+	}
+	secretKey := os.Getenv("S3_SECRET_ACCESS_KEY")
+	if secretKey == "" {
+		secretKey = "graphweaver123"
+	}
+
+	ctx := context.Background()
+	store, err := NewS3Store(ctx, S3Config{
+		Endpoint:        endpoint,
+		AccessKeyID:     accessKey,
+		SecretAccessKey: secretKey,
+		Bucket:          "graphweaver-test",
+	})
+	if err != nil {
+		t.Skipf("Skipping integration test: failed to connect to s3 (is minio running?): %v", err)
+	}
+
+	key := "test/" + uuid.New().String() + ".txt"
+	content := "hello minio"
+
+	if err := store.Put(ctx, key, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	defer store.Delete(ctx, key)
+
+	info, err := store.Stat(ctx, key)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Size != int64(len(content)) {
+		t.Errorf("Expected size %d, got %d", len(content), info.Size)
+	}
+
+	r, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	got, _ := io.ReadAll(r)
+	r.Close()
+	if string(got) != content {
+		t.Errorf("Expected %q, got %q", content, got)
+	}
+
+	url, err := store.PresignedURL(ctx, key, "report.txt", time.Minute)
+	if err != nil || url == "" {
+		t.Errorf("PresignedURL failed: %v", err)
+	} else if !strings.Contains(url, "response-content-disposition=") {
+		t.Errorf("Expected a content disposition in %s", url)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when an object does not exist.
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrPresignNotSupported is returned by backends that cannot issue presigned URLs.
+	ErrPresignNotSupported = errors.New("storage: presigned URLs not supported")
+)
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// BlobStore defines the interface for storing uploaded files.
+// Keys are slash-separated relative paths, e.g. "ab/abcdef.pdf".
+type BlobStore interface {
+	// Put stores the content of r under key, replacing any existing object.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Get opens the object for reading. The caller must close the returned reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// Stat returns metadata about the object, or ErrNotFound.
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+
+	// PresignedURL returns a time-limited download URL, or ErrPresignNotSupported. The
+	// download is saved under filename.
+	PresignedURL(ctx context.Context, key, filename string, expiry time.Duration) (string, error)
+
+	// List returns all objects whose key starts with prefix.
+	List(ctx context.Context, prefix string) ([]*ObjectInfo, error)
+}
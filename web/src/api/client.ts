@@ -79,6 +79,11 @@ export const api = {
         return response.json();
     },
 
+    async deleteDocument(id: number): Promise<void> {
+        const response = await fetch(`${API_BASE_URL}/documents/${id}`, { method: 'DELETE' });
+        if (!response.ok) throw new Error('Failed to delete document');
+    },
+
     async getDocumentGraph(documentId: number): Promise<any> {
         const response = await fetch(`${API_BASE_URL}/documents/${documentId}/graph`);
         if (!response.ok) {
@@ -87,11 +92,11 @@ export const api = {
         return response.json();
     },
 
-    async chat(notebookId: number, query: string): Promise<string> {
+    async chat(notebookId: number, query: string, mode?: 'local' | 'global'): Promise<string> {
         const response = await fetch(`${API_BASE_URL}/notebooks/${notebookId}/chat`, {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify({ query }),
+            body: JSON.stringify({ query, mode }),
         });
         if (!response.ok) {
             const errorData = await response.json().catch(() => ({}));
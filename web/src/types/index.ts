@@ -4,12 +4,14 @@ export interface Document {
     file_path: string;
     mime_type: string;
     file_size: number;
+    content_hash?: string;
     status: 'pending' | 'processing' | 'completed' | 'failed';
     error_message?: string;
     summary?: string;
     created_at: string;
     updated_at: string;
     is_deleted: boolean;
+    deleted_at?: string;
     notebook_id?: number;
 }
 